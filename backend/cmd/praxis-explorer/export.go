@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/export"
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// runExport implements `praxis-explorer export`: the same export as GET
+// /export/agents, read straight from DATABASE_URL into a file or stdout.
+func runExport(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", export.NDJSON, "output format: "+strings.Join(export.Formats, ", "))
+	out := fs.String("o", "-", "output file, - for stdout")
+	q := fs.String("q", "", "match domain, card name or skill name")
+	semantic := fs.String("semantic", "", "keep agents whose card is similar to this text")
+	expr := fs.String("filter", "", "filter expression, e.g. 'tag:finance -network:sepolia'")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: praxis-explorer export [flags]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	params := store.SearchParams{Q: *q, Semantic: *semantic}
+	x, err := filter.Parse(*expr)
+	if err != nil {
+		return err
+	}
+	if x != nil {
+		params.Filters = []filter.Expr{x}
+	}
+
+	var dst io.Writer = os.Stdout
+	var file *os.File
+	if *out != "-" {
+		if file, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer file.Close()
+		dst = file
+	}
+	buf := bufio.NewWriter(dst)
+	w, err := export.NewWriter(*format, buf)
+	if err != nil {
+		return err
+	}
+
+	st, err := store.NewPostgres(os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	n := 0
+	err = st.ExportAgents(ctx, params, func(e store.ExportRow) error {
+		n++
+		return w.Write(e)
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(os.Stderr, "exported %d agents\n", n)
+	return nil
+}
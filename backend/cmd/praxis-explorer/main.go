@@ -1,23 +1,42 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"time"
 
 	explorer "github.com/praxis/praxis-explorer/internal/explorer"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		if err := runExport(os.Args[2:]); err != nil {
+			log.Fatalf("export: %v", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "snapshot" {
+		if err := runSnapshot(os.Args[2:]); err != nil {
+			log.Fatalf("snapshot: %v", err)
+		}
+		return
+	}
 	srv, err := explorer.NewServerFromEnv()
 	if err != nil {
 		log.Fatalf("explorer init error: %v", err)
 	}
 	go srv.RunIndexer()
+	srv.RunWebhooks()
+	srv.RunEmbeddings()
 	port := os.Getenv("EXPLORER_PORT")
 	if port == "" {
 		port = "8080"
 	}
 	if err := srv.RunHTTP(":" + port); err != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Close(ctx)
 		log.Fatalf("explorer http error: %v", err)
 	}
 }
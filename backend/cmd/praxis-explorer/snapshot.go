@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/snapshot"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+const snapshotUsage = `usage: praxis-explorer snapshot <command> [flags]
+
+commands:
+  keygen    print a new signing key pair
+  create    write a signed snapshot of DATABASE_URL
+  restore   load a snapshot into DATABASE_URL`
+
+// runSnapshot implements `praxis-explorer snapshot`.
+func runSnapshot(args []string) error {
+	if len(args) == 0 {
+		return errors.New(snapshotUsage)
+	}
+	switch args[0] {
+	case "keygen":
+		pub, priv, err := snapshot.GenerateKey()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("EXPLORER_SNAPSHOT_KEY=%s\nEXPLORER_SNAPSHOT_PUBLIC_KEY=%s\n", priv, pub)
+		return nil
+	case "create":
+		return snapshotCreate(args[1:])
+	case "restore":
+		return snapshotRestore(args[1:])
+	}
+	return fmt.Errorf("unknown snapshot command %q\n%s", args[0], snapshotUsage)
+}
+
+func snapshotCreate(args []string) error {
+	fs := flag.NewFlagSet("snapshot create", flag.ContinueOnError)
+	out := fs.String("o", "-", "output file, - for stdout")
+	keyHex := fs.String("key", os.Getenv("EXPLORER_SNAPSHOT_KEY"), "hex signing key (default $EXPLORER_SNAPSHOT_KEY)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *keyHex == "" {
+		return errors.New("a signing key is required; create one with `praxis-explorer snapshot keygen`")
+	}
+	key, err := snapshot.ParsePrivateKey(*keyHex)
+	if err != nil {
+		return err
+	}
+
+	var dst io.Writer = os.Stdout
+	var file *os.File
+	if *out != "-" {
+		if file, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer file.Close()
+		dst = file
+	}
+	buf := bufio.NewWriter(dst)
+
+	st, err := store.NewPostgres(os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	m, err := snapshot.Create(ctx, st, buf, key, time.Now())
+	if err != nil {
+		return err
+	}
+	if err := buf.Flush(); err != nil {
+		return err
+	}
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+	for _, t := range m.Tables {
+		fmt.Fprintf(os.Stderr, "%-24s %d rows\n", t.Name, t.Rows)
+	}
+	fmt.Fprintf(os.Stderr, "snapshot signed by %s\n", m.Signer)
+	return nil
+}
+
+func snapshotRestore(args []string) error {
+	fs := flag.NewFlagSet("snapshot restore", flag.ContinueOnError)
+	in := fs.String("i", "-", "snapshot file, - for stdin")
+	pubHex := fs.String("pubkey", os.Getenv("EXPLORER_SNAPSHOT_PUBLIC_KEY"), "hex public key the snapshot must be signed with (default $EXPLORER_SNAPSHOT_PUBLIC_KEY)")
+	insecure := fs.Bool("insecure", false, "restore without checking the signature")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var trusted ed25519.PublicKey
+	switch {
+	case *pubHex != "":
+		var err error
+		if trusted, err = snapshot.ParsePublicKey(*pubHex); err != nil {
+			return err
+		}
+	case !*insecure:
+		return errors.New("a trusted public key is required (-pubkey); pass -insecure to skip the signature check")
+	}
+
+	var src io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		src = f
+	}
+
+	st, err := store.NewPostgres(os.Getenv("DATABASE_URL"))
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	m, err := snapshot.Restore(ctx, st, bufio.NewReader(src), trusted)
+	if err != nil {
+		return err
+	}
+	for _, t := range m.Tables {
+		fmt.Fprintf(os.Stderr, "%-24s %d rows\n", t.Name, t.Rows)
+	}
+	fmt.Fprintf(os.Stderr, "restored snapshot of %s signed by %s\n", m.CreatedAt.Format(time.RFC3339), m.Signer)
+	return nil
+}
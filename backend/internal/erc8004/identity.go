@@ -12,8 +12,14 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+var tracer = otel.Tracer("github.com/praxis/praxis-explorer/internal/erc8004")
+
 type AgentInfo struct {
 	AgentId      *big.Int
 	AgentDomain  string
@@ -202,10 +208,19 @@ func normalizeAgentTuple(v interface{}) (agentInfoTuple, error) {
 	return res, fmt.Errorf("unsupported tuple type: %T", v)
 }
 
-func (i *Identity) callAgentTuple(ctx context.Context, method string, args ...interface{}) (agentInfoTuple, error) {
+func (i *Identity) callAgentTuple(ctx context.Context, method string, args ...interface{}) (_ agentInfoTuple, err error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	ctx, span := tracer.Start(ctx, "erc8004.callAgentTuple", trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("method", method), attribute.String("contract", i.addr.Hex())))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
 
 	// 1) Pack call data
 	data, err := i.abi.Pack(method, args...)
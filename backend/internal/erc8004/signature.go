@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/crypto"
 )
@@ -28,3 +29,48 @@ func SignRegistrationEIP191(privKey *ecdsa.PrivateKey, chainID uint64, agentID u
 	// Adjust V to 27/28 form if needed; most consumers accept 0/1 in last byte already
 	return hexutil.Encode(sig), nil
 }
+
+// BuildRefreshMessage constructs the EIP-191 message an agent owner signs to ask an
+// explorer to re-index their agent. The nonce must be fresh and the timestamp (unix
+// seconds) recent, so a captured signature can't be replayed.
+func BuildRefreshMessage(chain string, agentID uint64, nonce string, timestamp int64) string {
+	return fmt.Sprintf("Praxis Agent Refresh\nchain=%s\nagentId=%d\nnonce=%s\ntimestamp=%d", chain, agentID, nonce, timestamp)
+}
+
+// SignRefreshEIP191 signs the refresh message with personal_sign semantics and
+// returns a 0x-prefixed signature.
+func SignRefreshEIP191(privKey *ecdsa.PrivateKey, chain string, agentID uint64, nonce string, timestamp int64) (string, error) {
+	msg := BuildRefreshMessage(chain, agentID, nonce, timestamp)
+	sig, err := crypto.Sign(accounts.TextHash([]byte(msg)), privKey)
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(sig), nil
+}
+
+// RecoverEIP191 returns the address that produced sig (65 bytes, V as 0/1 or 27/28)
+// over msg with personal_sign semantics.
+func RecoverEIP191(msg []byte, sig string) (common.Address, error) {
+	raw, err := hexutil.Decode(strings.TrimSpace(sig))
+	if err != nil {
+		return common.Address{}, fmt.Errorf("signature: %w", err)
+	}
+	if len(raw) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("signature: want %d bytes, got %d", crypto.SignatureLength, len(raw))
+	}
+	raw = append([]byte(nil), raw...)
+	if raw[64] >= 27 {
+		raw[64] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(msg), raw)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("signature: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// VerifyEIP191 reports whether sig over msg was produced by addr.
+func VerifyEIP191(msg []byte, sig string, addr common.Address) bool {
+	got, err := RecoverEIP191(msg, sig)
+	return err == nil && got == addr
+}
@@ -0,0 +1,40 @@
+package erc8004
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestRefreshSignatureRoundTrip(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	sig, err := SignRefreshEIP191(key, "sepolia", 7, "n-1", 1700000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := []byte(BuildRefreshMessage("sepolia", 7, "n-1", 1700000000))
+	if !VerifyEIP191(msg, sig, addr) {
+		t.Fatal("signature should verify for the signer")
+	}
+
+	// wallets return V as 27/28; both forms must recover the same address
+	raw := hexutil.MustDecode(sig)
+	raw[64] += 27
+	if !VerifyEIP191(msg, hexutil.Encode(raw), addr) {
+		t.Fatal("signature with V=27/28 should verify")
+	}
+
+	other := []byte(BuildRefreshMessage("sepolia", 8, "n-1", 1700000000))
+	if VerifyEIP191(other, sig, addr) {
+		t.Fatal("signature must not verify for a different agent")
+	}
+	if _, err := RecoverEIP191(msg, "0x1234"); err == nil {
+		t.Fatal("expected error for short signature")
+	}
+}
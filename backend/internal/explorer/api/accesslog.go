@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+// accessLogMiddleware logs each request once it is served, through logging.From so
+// the entry carries the request id and follows the configured format, levels and
+// redaction. Server errors are logged as warnings.
+func accessLogMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+		status := c.Writer.Status()
+		entry := logging.From(c.Request.Context()).WithFields(log.Fields{
+			"method":     c.Request.Method,
+			"path":       c.Request.URL.Path,
+			"route":      c.FullPath(),
+			"status":     status,
+			"latency_ms": time.Since(start).Milliseconds(),
+			"bytes":      c.Writer.Size(),
+			"client_ip":  c.ClientIP(),
+		})
+		if status >= http.StatusInternalServerError {
+			entry.Warn("request served")
+			return
+		}
+		entry.Info("request served")
+	}
+}
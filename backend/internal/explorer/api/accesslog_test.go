@@ -0,0 +1,49 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+func TestAccessLogThroughLogging(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		_ = logging.Setup(logging.DefaultConfig())
+		log.SetReportCaller(false)
+	}()
+	if err := logging.Setup(logging.Config{Format: logging.FormatJSON, Level: "info"}); err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(requestIDMiddleware(), accessLogMiddleware())
+	r.GET("/agents/:chainId", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	req := httptest.NewRequest(http.MethodGet, "/agents/sepolia?q=x", nil)
+	req.Header.Set(requestIDHeader, "req-1")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("not one JSON entry: %q", buf.String())
+	}
+	for k, want := range map[string]any{
+		"request_id": "req-1", "method": "GET", "path": "/agents/sepolia", "route": "/agents/:chainId",
+		"status": float64(http.StatusNoContent), "pkg": "api", "level": "info",
+	} {
+		if entry[k] != want {
+			t.Errorf("%s = %v, want %v", k, entry[k], want)
+		}
+	}
+}
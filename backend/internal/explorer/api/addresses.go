@@ -0,0 +1,64 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	"github.com/praxis/praxis-explorer/internal/explorer/logging"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// registerAddressRoutes mounts the reverse lookup from an account to its agents.
+func registerAddressRoutes(r *gin.Engine, st *store.Postgres, ix Indexer) {
+	// agents whose address is the CAIP-10 account; when none are indexed, the
+	// chain's identity registry is asked and the agent it knows is indexed
+	r.GET("/addresses/:caip10/agents", func(c *gin.Context) {
+		account, ok := caip.NormalizeAccount(c.Param("caip10"), "")
+		if !ok {
+			problem.Write(c, http.StatusBadRequest, "invalid CAIP-10 account id")
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		items, err := st.ListAgentsByAddress(c, account, limit)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		source := "index"
+		if chain, addr, _ := caip.ParseAccount(account); len(items) == 0 && configuredChain(ix, chain) {
+			// the index had nothing and the registry could not answer: unknown, not
+			// "no agent"
+			row, found, err := ix.ResolveAddress(c, chain, common.HexToAddress(addr))
+			if err != nil {
+				logging.From(c).WithError(err).WithField("account", account).Warn("on-chain address lookup failed")
+				problem.Write(c, http.StatusBadGateway, "on-chain address lookup failed: "+err.Error())
+				return
+			}
+			if found {
+				items, source = append(items, row), "chain"
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items, "source": source})
+	})
+}
+
+// configuredChain reports whether the indexer reads chain, an EVM chain it can
+// resolve addresses on.
+func configuredChain(ix Indexer, chain string) bool {
+	if ix == nil {
+		return false
+	}
+	if ns, _, _ := caip.ParseChain(chain); ns != caip.EVM {
+		return false
+	}
+	for _, n := range ix.Networks() {
+		if n.Name == chain {
+			return true
+		}
+	}
+	return false
+}
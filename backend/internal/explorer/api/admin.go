@@ -0,0 +1,138 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/auth"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	"github.com/praxis/praxis-explorer/internal/explorer/indexer"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// registerAdminRoutes mounts /admin behind authentication. Operator-only routes manage
+// the explorer as a whole; /admin/agents/:chainId/:agentId/* is also open to owners of
+// that agent. Every mutation is audited.
+func registerAdminRoutes(r *gin.Engine, st *store.Postgres, authn *auth.Authenticator, ix Refresher) {
+	admin := r.Group("/admin", authn.Authenticate())
+	operator := admin.Group("", auth.RequireRole(auth.RoleOperator))
+
+	// Operator: refresh any agent by fetching card and upserting with provided agentId
+	operator.POST("/refresh", authn.Audit("agent.refresh"), func(c *gin.Context) {
+		var req struct {
+			ChainID      string `json:"chainId"`
+			Domain       string `json:"domain"`
+			AgentID      int64  `json:"agentId"`
+			RegistryAddr string `json:"registryAddr"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.ChainID == "" || req.Domain == "" || req.AgentID <= 0 {
+			problem.Write(c, http.StatusBadRequest, "chainId, domain, agentId required")
+			return
+		}
+		if err := ix.RefreshFromDomain(c.Request.Context(), caip.Normalize(req.ChainID), req.RegistryAddr, req.AgentID, req.Domain); err != nil {
+			problem.Write(c, http.StatusBadGateway, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	// Owner or operator: re-index an agent through the indexer
+	agent := admin.Group("/agents/:chainId/:agentId", authn.RequireAgentAccess())
+	agent.POST("/refresh", authn.Audit("agent.refresh"), func(c *gin.Context) {
+		agentID, err := strconv.ParseInt(c.Param("agentId"), 10, 64)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, "invalid agentId")
+			return
+		}
+		if err := ix.RefreshAgent(c.Request.Context(), c.Param("chainId"), agentID); err != nil {
+			if errors.Is(err, indexer.ErrUnknownAgent) {
+				problem.Write(c, http.StatusNotFound, "not found")
+				return
+			}
+			problem.Write(c, http.StatusBadGateway, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	operator.GET("/keys", func(c *gin.Context) {
+		keys, err := st.ListAPIKeys(c)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"items": keys})
+	})
+
+	// The plaintext key is returned once and never stored.
+	operator.POST("/keys", authn.Audit("apikey.create"), func(c *gin.Context) {
+		var req struct {
+			Name         string `json:"name"`
+			Role         string `json:"role"`
+			OwnerAddress string `json:"ownerAddress"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || strings.TrimSpace(req.Name) == "" {
+			problem.Write(c, http.StatusBadRequest, "name required")
+			return
+		}
+		switch req.Role {
+		case auth.RoleOperator:
+			req.OwnerAddress = ""
+		case auth.RoleOwner:
+			if !common.IsHexAddress(req.OwnerAddress) {
+				problem.Write(c, http.StatusBadRequest, "owner keys require ownerAddress")
+				return
+			}
+		default:
+			problem.Write(c, http.StatusBadRequest, fmt.Sprintf("role must be %q or %q", auth.RoleOperator, auth.RoleOwner))
+			return
+		}
+		key, hash, prefix, err := auth.GenerateAPIKey()
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		id, err := st.CreateAPIKey(c, strings.TrimSpace(req.Name), hash, prefix, req.Role, req.OwnerAddress)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"id": id, "key": key, "prefix": prefix, "role": req.Role})
+	})
+
+	operator.DELETE("/keys/:id", authn.Audit("apikey.revoke"), func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, "invalid id")
+			return
+		}
+		ok, err := st.RevokeAPIKey(c, id)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !ok {
+			problem.Write(c, http.StatusNotFound, "not found")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	operator.GET("/audit", func(c *gin.Context) {
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		items, err := st.ListAudit(c, limit)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	})
+
+	registerWebhookRoutes(operator, st, authn)
+}
@@ -0,0 +1,24 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+)
+
+// requestedChainKey holds the :chainId path segment as the client sent it.
+const requestedChainKey = "requestedChainId"
+
+// chainParamMiddleware lets every route take either a network name (sepolia) or
+// a CAIP-2 chain id (eip155:11155111) for :chainId, by rewriting the parameter to
+// the CAIP-2 id agents are stored under before handlers and auth read it.
+func chainParamMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for i, p := range c.Params {
+			if p.Key == "chainId" {
+				c.Set(requestedChainKey, p.Value)
+				c.Params[i].Value = caip.Normalize(p.Value)
+			}
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/conformance"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// agentGetter loads one agent; *store.Postgres implements it.
+type agentGetter interface {
+	GetAgent(ctx context.Context, chainID, agentID string) (store.AgentRow, error)
+}
+
+// conformanceRunner checks an agent's A2A endpoint; *conformance.Checker implements it.
+type conformanceRunner interface {
+	Run(ctx context.Context, agent store.AgentRow) conformance.Report
+}
+
+// registerConformanceRoute mounts the A2A conformance check of an agent's card url.
+// Each run calls out to the agent, so an agent is checked at most once per refresh
+// interval, however its chain and id are spelled in the URL.
+func registerConformanceRoute(r *gin.Engine, st agentGetter, checker conformanceRunner) {
+	limiter := newAgentLimiter(refreshInterval())
+
+	r.POST("/agents/:chainId/:agentId/conformance", func(c *gin.Context) {
+		ai, err := st.GetAgent(c, c.Param("chainId"), c.Param("agentId"))
+		if err != nil {
+			problem.Write(c, http.StatusNotFound, "not found")
+			return
+		}
+		if allowed, wait := limiter.allow(agentKey(ai.ChainID, ai.AgentID), time.Now()); !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			problem.Write(c, http.StatusTooManyRequests, "agent was checked recently")
+			return
+		}
+		c.JSON(http.StatusOK, checker.Run(c.Request.Context(), ai))
+	})
+}
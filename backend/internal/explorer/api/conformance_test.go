@@ -0,0 +1,60 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/conformance"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// fakeAgents resolves ids the way Postgres casts them, so "01" and "+1" are agent 1.
+type fakeAgents struct{}
+
+func (fakeAgents) GetAgent(_ context.Context, chainID, agentID string) (store.AgentRow, error) {
+	id, err := strconv.ParseInt(agentID, 10, 64)
+	if err != nil {
+		return store.AgentRow{}, err
+	}
+	return store.AgentRow{ChainID: chainID, AgentID: id}, nil
+}
+
+type countingChecker struct{ runs int }
+
+func (c *countingChecker) Run(_ context.Context, agent store.AgentRow) conformance.Report {
+	c.runs++
+	return conformance.Report{ChainID: agent.ChainID, AgentID: agent.AgentID}
+}
+
+func TestConformanceLimitsEverySpellingOfAnAgent(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(chainParamMiddleware())
+	checker := &countingChecker{}
+	registerConformanceRoute(r, fakeAgents{}, checker)
+
+	cases := []struct {
+		path string
+		want int
+	}{
+		{"/agents/eip155:11155111/1/conformance", http.StatusOK},
+		{"/agents/sepolia/01/conformance", http.StatusTooManyRequests},
+		{"/agents/SEPOLIA/+1/conformance", http.StatusTooManyRequests},
+		{"/agents/sepolia/2/conformance", http.StatusOK},
+		{"/agents/eip155:1/1/conformance", http.StatusOK},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tc.path, nil))
+		if w.Code != tc.want {
+			t.Errorf("%s: status %d, want %d", tc.path, w.Code, tc.want)
+		}
+	}
+	if checker.runs != 3 {
+		t.Errorf("checker ran %d times, want 3", checker.runs)
+	}
+}
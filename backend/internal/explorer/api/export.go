@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/export"
+	"github.com/praxis/praxis-explorer/internal/explorer/logging"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+func exportConcurrency() int {
+	if v := os.Getenv("EXPLORER_EXPORT_CONCURRENCY"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return n
+		}
+		log.WithField("value", v).Warn("invalid EXPLORER_EXPORT_CONCURRENCY; using default")
+	}
+	return 2
+}
+
+// registerExport mounts the bulk export. Each export holds a database connection
+// for as long as the client reads, so only a few may run at once.
+func registerExport(r *gin.Engine, st *store.Postgres) {
+	slots := make(chan struct{}, exportConcurrency())
+	r.GET("/export/agents", func(c *gin.Context) {
+		params, err := searchParams(c)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		// format has already been validated against the spec
+		format := c.DefaultQuery("format", export.NDJSON)
+		select {
+		case slots <- struct{}{}:
+			defer func() { <-slots }()
+		default:
+			c.Header("Retry-After", "30")
+			problem.Write(c, http.StatusTooManyRequests, "too many exports running; retry later")
+			return
+		}
+
+		w, err := export.NewWriter(format, c.Writer)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		c.Header("Content-Type", export.ContentType(format))
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="agents.%s"`, format))
+		err = st.ExportAgents(c.Request.Context(), params, w.Write)
+		if err == nil {
+			err = w.Close()
+		}
+		if err == nil {
+			return
+		}
+		if !c.Writer.Written() {
+			c.Writer.Header().Del("Content-Disposition")
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		// the status is already sent; drop the connection so the client sees a
+		// truncated download rather than a complete-looking file
+		logging.From(c).WithError(err).Warn("[export] export failed mid-stream")
+		if conn, _, err := c.Writer.Hijack(); err == nil {
+			conn.Close()
+		}
+	})
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/gql"
+	"github.com/praxis/praxis-explorer/internal/explorer/indexer"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// Indexer is what the API needs from *indexer.Indexer.
+type Indexer interface {
+	Refresher
+	Networks() []indexer.Chain
+	Status(ctx context.Context) (indexer.Status, error)
+	ResolveAddress(ctx context.Context, chain string, addr common.Address) (store.AgentRow, bool, error)
+	ResolveDomain(ctx context.Context, domain string) ([]store.AgentRow, error)
+}
+
+// registerGraphQL mounts GET and POST /graphql.
+func registerGraphQL(r *gin.Engine, st *store.Postgres, ix Indexer) {
+	networks := func() []gql.Network {
+		if ix == nil {
+			return nil
+		}
+		var out []gql.Network
+		for _, n := range ix.Networks() {
+			out = append(out, gql.Network{ID: n.Name, Alias: n.Alias, IdentityRegistry: n.Identity, ReputationRegistry: n.Reputation, ValidationRegistry: n.Validation})
+		}
+		return out
+	}
+	srv, err := gql.New(st, networks, gql.ConfigFromEnv())
+	if err != nil {
+		// the schema is static, so this only fails on a programming error
+		log.WithError(err).Fatal("failed to build GraphQL schema")
+	}
+	r.GET("/graphql", srv.Handler())
+	r.POST("/graphql", srv.Handler())
+}
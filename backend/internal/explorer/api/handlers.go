@@ -1,82 +1,180 @@
 package api
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/auth"
+	"github.com/praxis/praxis-explorer/internal/explorer/conformance"
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
+	"github.com/praxis/praxis-explorer/internal/explorer/metrics"
+	"github.com/praxis/praxis-explorer/internal/explorer/openapi"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
 	"github.com/praxis/praxis-explorer/internal/explorer/store"
 )
 
-func RegisterRoutes(r *gin.Engine, st *store.Postgres) {
+// searchParams reads the agent filters shared by /agents and /export/agents: q,
+// semantic, the repeatable field parameters combined per match, and filter.
+func searchParams(c *gin.Context) (store.SearchParams, error) {
+	params := store.SearchParams{
+		Q:        c.Query("q"),
+		Semantic: c.Query("semantic"),
+	}
+	// each filter parameter may repeat; its values become one expression so a
+	// facet can drop its own selection
+	for _, f := range filter.Fields {
+		if x := filter.Values(f, c.QueryArray(f), c.Query("match") == "any"); x != nil {
+			params.Filters = append(params.Filters, x)
+		}
+	}
+	x, err := filter.Parse(c.Query("filter"))
+	if err != nil {
+		return store.SearchParams{}, err
+	}
+	if x != nil {
+		params.Filters = append(params.Filters, x)
+	}
+	if n := filter.Terms(params.Filters...); n > filter.MaxTerms {
+		return store.SearchParams{}, fmt.Errorf("filter: %d terms across parameters, at most %d allowed", n, filter.MaxTerms)
+	}
+	return params, nil
+}
+
+// RegisterRoutes mounts the REST API. Every route must be described in
+// openapi/openapi.yaml; requests are validated against it before handlers run and,
+// under gin.TestMode, responses are checked too.
+func RegisterRoutes(r *gin.Engine, st *store.Postgres, authn *auth.Authenticator, ix Indexer) {
+	spec := openapi.MustLoad()
+	// let handlers passing the gin context reach the request's trace span
+	r.ContextWithFallback = true
+	r.Use(requestIDMiddleware(), accessLogMiddleware(), metricsMiddleware(), tracingMiddleware(), chainParamMiddleware())
+	r.Use(spec.Middleware(openapi.Options{ValidateResponses: gin.Mode() == gin.TestMode}))
+	r.NoRoute(func(c *gin.Context) { problem.Write(c, http.StatusNotFound, "no such route") })
+	r.HandleMethodNotAllowed = true
+	r.NoMethod(func(c *gin.Context) { problem.Write(c, http.StatusMethodNotAllowed, "") })
+	r.GET("/openapi.json", spec.Handler())
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
+	registerHealth(r, st, ix)
+	authn.RegisterRoutes(r)
+	registerAdminRoutes(r, st, authn, ix)
+	registerRefreshRoute(r, st, ix)
+	registerGraphQL(r, st, ix)
+	registerStream(r, st)
+	registerExport(r, st)
+	registerAddressRoutes(r, st, ix)
+	registerResolveRoute(r, st, ix)
+
 	r.GET("/agents", func(c *gin.Context) {
-		params := store.SearchParams{
-			Q:          c.Query("q"),
-			Network:    c.Query("network"),
-			Capability: c.Query("capability"),
-			Skill:      c.Query("skill"),
-			Tag:        c.Query("tag"),
-			TrustModel: c.Query("trustModel"),
-			Cursor:     c.Query("cursor"),
+		params, err := searchParams(c)
+		if err != nil {
+			problem.Write(c, http.StatusBadRequest, err.Error())
+			return
 		}
-		limitStr := c.Query("limit")
-		if limitStr != "" {
-			if v, err := strconv.Atoi(limitStr); err == nil {
-				params.Limit = v
-			}
+		params.Cursor = c.Query("cursor")
+		// limit, sort and order have already been validated against the spec
+		params.Limit, _ = strconv.Atoi(c.Query("limit"))
+		if params.Sort, err = store.ParseSort(c.Query("sort"), c.Query("order")); err != nil {
+			problem.Write(c, http.StatusBadRequest, err.Error())
+			return
 		}
 		items, next, err := st.SearchAgents(c, params)
+		if errors.Is(err, store.ErrInvalidCursor) {
+			problem.Write(c, http.StatusBadRequest, err.Error())
+			return
+		}
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			problem.Write(c, http.StatusInternalServerError, err.Error())
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"items": items, "nextCursor": next})
+		res := gin.H{"items": items, "nextCursor": next}
+		if v := c.Query("facets"); v != "" {
+			var names []string
+			seen := map[string]bool{}
+			for _, n := range strings.Split(v, ",") {
+				if n = strings.TrimSpace(n); n != "" && !seen[n] {
+					seen[n] = true
+					names = append(names, n)
+				}
+			}
+			facetLimit, _ := strconv.Atoi(c.Query("facetLimit"))
+			facets, err := st.SearchFacets(c, params, names, facetLimit)
+			if err != nil {
+				problem.Write(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+			res["facets"] = facets
+		}
+		c.JSON(http.StatusOK, res)
 	})
 
 	r.GET("/agents/:chainId/:agentId", func(c *gin.Context) {
 		ai, err := st.GetAgent(c, c.Param("chainId"), c.Param("agentId"))
 		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			problem.Write(c, http.StatusNotFound, "not found")
 			return
 		}
 		c.JSON(http.StatusOK, ai)
 	})
 
-	// Admin: refresh an agent by fetching card and upserting with provided agentId
-	r.POST("/admin/refresh", func(c *gin.Context) {
-		var req struct {
-			ChainID      string `json:"chainId"`
-			Domain       string `json:"domain"`
-			AgentID      int64  `json:"agentId"`
-			RegistryAddr string `json:"registryAddr"`
+	r.GET("/agents/:chainId/:agentId/registration", func(c *gin.Context) {
+		reg, err := st.GetRegistration(c, c.Param("chainId"), c.Param("agentId"))
+		if err != nil {
+			problem.Write(c, http.StatusNotFound, "not found")
+			return
 		}
-		if err := c.ShouldBindJSON(&req); err != nil || req.ChainID == "" || req.Domain == "" || req.AgentID <= 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "chainId, domain, agentId required"})
+		c.JSON(http.StatusOK, reg)
+	})
+
+	// agents whose cards read most like this one's, by local n-gram embeddings
+	r.GET("/agents/:chainId/:agentId/similar", func(c *gin.Context) {
+		ai, err := st.GetAgent(c, c.Param("chainId"), c.Param("agentId"))
+		if err != nil {
+			problem.Write(c, http.StatusNotFound, "not found")
 			return
 		}
-		url := req.Domain
-		if !(len(url) > 7 && (url[:7] == "http://" || (len(url) > 8 && url[:8] == "https://"))) {
-			url = fmt.Sprintf("http://%s/.well-known/agent-card.json", req.Domain)
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		items, err := st.SimilarAgents(c, ai, limit)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
 		}
-		resp, err := http.Get(url)
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	})
+
+	r.GET("/agents/:chainId/:agentId/probes", func(c *gin.Context) {
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		probes, err := st.ListProbes(c, c.Param("chainId"), c.Param("agentId"), limit)
 		if err != nil {
-			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			problem.Write(c, http.StatusInternalServerError, err.Error())
 			return
 		}
-		defer resp.Body.Close()
-		var card map[string]any
-		if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
-			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		c.JSON(http.StatusOK, gin.H{"items": probes})
+	})
+
+	// on-chain timeline: registration and update logs with block, tx and sender
+	r.GET("/agents/:chainId/:agentId/events", func(c *gin.Context) {
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		events, err := st.ListAgentEvents(c, c.Param("chainId"), c.Param("agentId"), limit)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
 			return
 		}
-		if err := st.UpsertAgentFromCard(c, req.ChainID, req.RegistryAddr, req.AgentID, req.Domain, card); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusOK, gin.H{"items": events})
+	})
+
+	// MCP catalog: tools, resources and prompts advertised by the agent's MCP server
+	r.GET("/agents/:chainId/:agentId/mcp", func(c *gin.Context) {
+		cat, err := st.GetMCPCatalog(c, c.Param("chainId"), c.Param("agentId"))
+		if err != nil {
+			problem.Write(c, http.StatusNotFound, "not found")
 			return
 		}
-		// best-effort: remove placeholder row with agent_id=0
-		_ = st.DeleteAgent(c, req.ChainID, 0)
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+		c.JSON(http.StatusOK, cat)
 	})
+
+	registerConformanceRoute(r, st, conformance.New(nil))
 }
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/indexer"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// healthTimeout bounds the database checks behind /readyz and /status.
+const healthTimeout = 3 * time.Second
+
+// dbHealth is the database part of /status.
+type dbHealth struct {
+	OK                    bool   `json:"ok"`
+	SchemaVersion         int    `json:"schemaVersion"`
+	RequiredSchemaVersion int    `json:"requiredSchemaVersion"`
+	Error                 string `json:"error,omitempty"`
+}
+
+func checkDB(ctx context.Context, st *store.Postgres) dbHealth {
+	h := dbHealth{RequiredSchemaVersion: store.SchemaVersion}
+	if err := st.Ping(ctx); err != nil {
+		h.Error = "database unreachable: " + err.Error()
+		return h
+	}
+	v, err := st.AppliedSchemaVersion(ctx)
+	if err != nil {
+		h.Error = err.Error()
+		return h
+	}
+	h.SchemaVersion = v
+	if v < store.SchemaVersion {
+		h.Error = fmt.Sprintf("database schema is at migration %d; this build needs %d", v, store.SchemaVersion)
+		return h
+	}
+	h.OK = true
+	return h
+}
+
+// registerHealth mounts the probes: /healthz answers while the process serves
+// HTTP, /readyz only once the database is reachable and migrated, and /status
+// details the database and every chain watcher.
+func registerHealth(r *gin.Engine, st *store.Postgres, ix Indexer) {
+	r.GET("/healthz", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+
+	r.GET("/readyz", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c, healthTimeout)
+		defer cancel()
+		if h := checkDB(ctx, st); !h.OK {
+			problem.Write(c, http.StatusServiceUnavailable, h.Error)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ready"})
+	})
+
+	r.GET("/status", func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c, healthTimeout)
+		defer cancel()
+		db := checkDB(ctx, st)
+		var ixs indexer.Status
+		if ix != nil {
+			// a failed zero-id count is already visible as the database error
+			ixs, _ = ix.Status(ctx)
+		}
+		if ixs.Chains == nil {
+			ixs.Chains = []indexer.ChainStatus{}
+		}
+		c.JSON(http.StatusOK, gin.H{"database": db, "indexer": ixs})
+	})
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	erc "github.com/praxis/praxis-explorer/internal/erc8004"
+	"github.com/praxis/praxis-explorer/internal/explorer/indexer"
+	"github.com/praxis/praxis-explorer/internal/explorer/logging"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// Refresher re-indexes agents through the indexer; *indexer.Indexer implements it.
+type Refresher interface {
+	RefreshAgent(ctx context.Context, chain string, agentID int64) error
+	RefreshFromDomain(ctx context.Context, chain, registryAddr string, agentID int64, domain string) error
+	IsAgentSigner(ctx context.Context, chain string, agentID int64, addr common.Address) (bool, error)
+}
+
+// maxRefreshSkew bounds how far a signed refresh timestamp may be from our clock.
+const maxRefreshSkew = 5 * time.Minute
+
+// agentLimiter allows one request per agent every interval.
+type agentLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     map[string]time.Time
+}
+
+func newAgentLimiter(interval time.Duration) *agentLimiter {
+	return &agentLimiter{interval: interval, last: map[string]time.Time{}}
+}
+
+// allow reports whether key may proceed now, or how long until it may.
+func (l *agentLimiter) allow(key string, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if prev, ok := l.last[key]; ok && now.Sub(prev) < l.interval {
+		return false, l.interval - now.Sub(prev)
+	}
+	l.last[key] = now
+	// forget agents whose window has passed so the map doesn't grow unbounded
+	if len(l.last) > 10000 {
+		for k, t := range l.last {
+			if now.Sub(t) >= l.interval {
+				delete(l.last, k)
+			}
+		}
+	}
+	return true, 0
+}
+
+// agentKey is the limiter key of an agent, from its normalized chain and parsed id
+// so every spelling of them in a URL shares one window.
+func agentKey(chain string, agentID int64) string {
+	return chain + "/" + strconv.FormatInt(agentID, 10)
+}
+
+func refreshInterval() time.Duration {
+	if v := os.Getenv("EXPLORER_REFRESH_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d >= 0 {
+			return d
+		}
+		log.WithField("value", v).Warn("invalid EXPLORER_REFRESH_INTERVAL; using default")
+	}
+	return time.Minute
+}
+
+// registerRefreshRoute mounts the owner self-service refresh: the agent's on-chain
+// AgentAddress (or v1 owner) signs erc8004.BuildRefreshMessage over a fresh nonce
+// and the current time, and the agent is re-indexed through the indexer.
+func registerRefreshRoute(r *gin.Engine, st *store.Postgres, ix Refresher) {
+	limiter := newAgentLimiter(refreshInterval())
+
+	r.POST("/agents/:chainId/:agentId/refresh", func(c *gin.Context) {
+		chain := c.Param("chainId")
+		agentID, err := strconv.ParseUint(c.Param("agentId"), 10, 63)
+		if err != nil || agentID == 0 {
+			problem.Write(c, http.StatusBadRequest, "invalid agentId")
+			return
+		}
+		var req struct {
+			Nonce     string `json:"nonce"`
+			Timestamp int64  `json:"timestamp"`
+			Signature string `json:"signature"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || len(req.Nonce) < 8 || len(req.Nonce) > 128 || req.Timestamp == 0 || req.Signature == "" {
+			problem.Write(c, http.StatusBadRequest, "nonce (8-128 chars), timestamp and signature required")
+			return
+		}
+		now := time.Now()
+		if d := now.Sub(time.Unix(req.Timestamp, 0)); d > maxRefreshSkew || d < -maxRefreshSkew {
+			problem.Write(c, http.StatusBadRequest, fmt.Sprintf("timestamp must be within %s of server time", maxRefreshSkew))
+			return
+		}
+
+		// the owner signed the chain as written in the URL, name or CAIP-2 id
+		msg := erc.BuildRefreshMessage(c.GetString(requestedChainKey), agentID, req.Nonce, req.Timestamp)
+		signer, err := erc.RecoverEIP191([]byte(msg), req.Signature)
+		if err != nil {
+			problem.Write(c, http.StatusUnauthorized, err.Error())
+			return
+		}
+		ok, err := ix.IsAgentSigner(c, chain, int64(agentID), signer)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !ok {
+			problem.Write(c, http.StatusForbidden, "signer "+signer.Hex()+" is not the agent address or owner")
+			return
+		}
+
+		// the nonce is spent before the limiter is charged, so replaying a signed
+		// request cannot use up the owner's refresh window
+		fresh, err := st.UseRefreshNonce(c, chain, int64(agentID), req.Nonce, 2*maxRefreshSkew)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !fresh {
+			problem.Write(c, http.StatusConflict, "nonce already used")
+			return
+		}
+		if allowed, wait := limiter.allow(agentKey(chain, int64(agentID)), now); !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(wait.Seconds())+1))
+			problem.Write(c, http.StatusTooManyRequests, "agent was refreshed recently")
+			return
+		}
+
+		if err := ix.RefreshAgent(c.Request.Context(), chain, int64(agentID)); err != nil {
+			if errors.Is(err, indexer.ErrUnknownAgent) {
+				problem.Write(c, http.StatusNotFound, "not found")
+				return
+			}
+			problem.Write(c, http.StatusBadGateway, err.Error())
+			return
+		}
+		logging.From(c).WithFields(log.Fields{"chain": chain, "agent_id": agentID, "signer": signer.Hex()}).Info("owner refresh")
+
+		ai, err := st.GetAgent(c, chain, c.Param("agentId"))
+		if err != nil {
+			c.JSON(http.StatusOK, gin.H{"status": "ok"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "agent": ai})
+	})
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/indexer"
+	"github.com/praxis/praxis-explorer/internal/explorer/logging"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// resolvedAgent is an agent registered under the resolved domain.
+type resolvedAgent struct {
+	store.AgentRow
+	// whether the card served at the domain declares this registration; unset
+	// when the card could not be fetched
+	CardMatches *bool `json:"cardMatches,omitempty"`
+}
+
+// registerResolveRoute mounts GET /resolve, from a domain to the agents
+// registered under it on any configured chain.
+func registerResolveRoute(r *gin.Engine, st *store.Postgres, ix Indexer) {
+	r.GET("/resolve", func(c *gin.Context) {
+		domain := normalizeDomain(c.Query("domain"))
+		if domain == "" {
+			problem.Write(c, http.StatusBadRequest, "domain is required")
+			return
+		}
+		items, err := st.ListAgentsByDomain(c, domain)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		source := "index"
+		if len(items) == 0 && ix != nil {
+			// the index had nothing and the registries could not answer: unknown, not
+			// "no agent"
+			found, err := ix.ResolveDomain(c, domain)
+			if err != nil {
+				logging.From(c).WithError(err).WithField("domain", domain).Warn("on-chain domain lookup failed")
+				problem.Write(c, http.StatusBadGateway, "on-chain domain lookup failed: "+err.Error())
+				return
+			}
+			if len(found) > 0 {
+				items, source = found, "chain"
+			}
+		}
+
+		var card map[string]any
+		if len(items) > 0 {
+			if card, err = indexer.FetchCard(c, domain); err != nil {
+				logging.From(c).WithError(err).WithField("domain", domain).Info("card at domain unavailable")
+			}
+		}
+		out := make([]resolvedAgent, len(items))
+		for i, a := range items {
+			out[i].AgentRow = a
+			if card != nil {
+				ok := indexer.CardRegisters(card, a)
+				out[i].CardMatches = &ok
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"domain": domain, "items": out, "source": source})
+	})
+}
+
+// normalizeDomain reduces a domain query to the host registries store: no
+// scheme, no trailing slash, lowercase.
+func normalizeDomain(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "https://"), "http://")
+	return strings.TrimRight(s, "/")
+}
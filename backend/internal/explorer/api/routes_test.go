@@ -0,0 +1,45 @@
+package api
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/auth"
+	"github.com/praxis/praxis-explorer/internal/explorer/openapi"
+)
+
+// TestRoutesMatchSpec fails when a route is added without documenting it in
+// openapi.yaml, or the spec describes a route that no longer exists.
+func TestRoutesMatchSpec(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterRoutes(r, nil, auth.New(nil, auth.Config{}), nil)
+
+	registered := map[string]bool{}
+	for _, rt := range r.Routes() {
+		registered[rt.Method+" "+rt.Path] = true
+	}
+	documented := map[string]bool{}
+	for _, k := range openapi.MustLoad().Routes() {
+		documented[k] = true
+	}
+
+	var missing, stale []string
+	for k := range registered {
+		if !documented[k] {
+			missing = append(missing, k)
+		}
+	}
+	for k := range documented {
+		if !registered[k] {
+			stale = append(stale, k)
+		}
+	}
+	if len(missing) > 0 {
+		t.Errorf("routes missing from openapi.yaml:\n  %s", strings.Join(missing, "\n  "))
+	}
+	if len(stale) > 0 {
+		t.Errorf("openapi.yaml documents routes that are not registered:\n  %s", strings.Join(stale, "\n  "))
+	}
+}
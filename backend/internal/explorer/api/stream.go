@@ -0,0 +1,29 @@
+package api
+
+import (
+	"os"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	"github.com/praxis/praxis-explorer/internal/explorer/stream"
+	log "github.com/sirupsen/logrus"
+)
+
+func streamPollInterval() time.Duration {
+	if v := os.Getenv("EXPLORER_STREAM_POLL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			return d
+		}
+		log.WithField("value", v).Warn("invalid EXPLORER_STREAM_POLL; using default")
+	}
+	return time.Second
+}
+
+// registerStream mounts the SSE and WebSocket event streams. The broker only starts
+// polling the event log once the first client subscribes.
+func registerStream(r *gin.Engine, st *store.Postgres) {
+	h := stream.NewHandler(stream.NewBroker(st, streamPollInterval()), 0)
+	r.GET("/stream/agents", h.SSE)
+	r.GET("/stream/agents/ws", h.WebSocket)
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/praxis/praxis-explorer/internal/explorer/auth"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	"github.com/praxis/praxis-explorer/internal/explorer/webhook"
+)
+
+// validWebhookURL accepts absolute http(s) URLs.
+func validWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func pathID(c *gin.Context, name string) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 64)
+	if err != nil || id <= 0 {
+		problem.Write(c, http.StatusBadRequest, "invalid "+name)
+		return 0, false
+	}
+	return id, true
+}
+
+// registerWebhookRoutes mounts operator management of webhook subscriptions under
+// /admin/webhooks. The signing secret is returned once, at creation.
+func registerWebhookRoutes(operator *gin.RouterGroup, st *store.Postgres, authn *auth.Authenticator) {
+	operator.GET("/webhooks", func(c *gin.Context) {
+		items, err := st.ListWebhooks(c)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	})
+
+	operator.POST("/webhooks", authn.Audit("webhook.create"), func(c *gin.Context) {
+		var req struct {
+			URL         string              `json:"url"`
+			Description string              `json:"description"`
+			Filter      store.WebhookFilter `json:"filter"`
+			Types       []string            `json:"types"`
+			Active      *bool               `json:"active"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || !validWebhookURL(req.URL) {
+			problem.Write(c, http.StatusBadRequest, "url must be an absolute http(s) URL")
+			return
+		}
+		secret, err := webhook.GenerateSecret()
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		p, _ := auth.PrincipalFrom(c)
+		w, err := st.CreateWebhook(c, store.WebhookSubscription{
+			URL:         req.URL,
+			Secret:      secret,
+			Description: strings.TrimSpace(req.Description),
+			Filter:      req.Filter,
+			Types:       req.Types,
+			Active:      req.Active == nil || *req.Active,
+			CreatedBy:   p.Kind + ":" + p.ID,
+		})
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusCreated, struct {
+			store.WebhookSubscription
+			Secret string `json:"secret"`
+		}{w, secret})
+	})
+
+	operator.GET("/webhooks/:id", func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+		w, err := st.GetWebhook(c, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			problem.Write(c, http.StatusNotFound, "not found")
+			return
+		} else if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, w)
+	})
+
+	// Absent fields are left unchanged; filter and types are replaced as a whole.
+	operator.PATCH("/webhooks/:id", authn.Audit("webhook.update"), func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+		var req struct {
+			URL         *string              `json:"url"`
+			Description *string              `json:"description"`
+			Filter      *store.WebhookFilter `json:"filter"`
+			Types       *[]string            `json:"types"`
+			Active      *bool                `json:"active"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			problem.Write(c, http.StatusBadRequest, "invalid body")
+			return
+		}
+		if req.URL != nil && !validWebhookURL(*req.URL) {
+			problem.Write(c, http.StatusBadRequest, "url must be an absolute http(s) URL")
+			return
+		}
+		w, err := st.GetWebhook(c, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			problem.Write(c, http.StatusNotFound, "not found")
+			return
+		} else if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if req.URL != nil {
+			w.URL = *req.URL
+		}
+		if req.Description != nil {
+			w.Description = strings.TrimSpace(*req.Description)
+		}
+		if req.Filter != nil {
+			w.Filter = *req.Filter
+		}
+		if req.Types != nil {
+			w.Types = *req.Types
+		}
+		if req.Active != nil {
+			w.Active = *req.Active
+		}
+		w, err = st.UpdateWebhook(c, w)
+		if errors.Is(err, pgx.ErrNoRows) {
+			problem.Write(c, http.StatusNotFound, "not found")
+			return
+		} else if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, w)
+	})
+
+	operator.DELETE("/webhooks/:id", authn.Audit("webhook.delete"), func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+		deleted, err := st.DeleteWebhook(c, id)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !deleted {
+			problem.Write(c, http.StatusNotFound, "not found")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	operator.GET("/webhooks/:id/deliveries", func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+		limit, _ := strconv.Atoi(c.Query("limit"))
+		items, err := st.ListWebhookDeliveries(c, id, c.Query("status"), limit)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"items": items})
+	})
+
+	// Re-queue a delivery, typically a dead-lettered one, with a fresh attempt budget.
+	operator.POST("/webhooks/:id/deliveries/:deliveryId/retry", authn.Audit("webhook.retry"), func(c *gin.Context) {
+		id, ok := pathID(c, "id")
+		if !ok {
+			return
+		}
+		deliveryID, ok := pathID(c, "deliveryId")
+		if !ok {
+			return
+		}
+		found, err := st.RetryWebhookDelivery(c, id, deliveryID)
+		if err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !found {
+			problem.Write(c, http.StatusNotFound, "not found")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+}
@@ -0,0 +1,298 @@
+// Package auth guards the /admin endpoints. Callers authenticate with a static API
+// key (stored hashed) or a SIWE session token; roles separate operator actions from
+// actions an agent owner may take on their own agents, and every admin mutation is
+// written to the audit log.
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// Roles
+const (
+	RoleOperator = "operator" // manages the explorer: any agent, keys, audit log
+	RoleOwner    = "owner"    // may act only on agents it owns
+)
+
+// Principal kinds
+const (
+	KindAPIKey  = "api_key"
+	KindSession = "session"
+)
+
+const (
+	apiKeyPrefix  = "pxk_"
+	sessionPrefix = "pxs_"
+	principalKey  = "auth.principal"
+	maxAuditBody  = 64 * 1024
+)
+
+// Principal is the authenticated caller of an admin request.
+type Principal struct {
+	Kind    string `json:"kind"`
+	ID      string `json:"id"`
+	Role    string `json:"role"`
+	Address string `json:"address,omitempty"` // lowercased 0x address for owners
+}
+
+// Store is the persistence auth needs; *store.Postgres implements it.
+type Store interface {
+	LookupAPIKey(ctx context.Context, keyHash string) (store.APIKey, error)
+	CreateSIWENonce(ctx context.Context, nonce string, expiresAt time.Time) error
+	ConsumeSIWENonce(ctx context.Context, nonce string) (bool, error)
+	CreateSession(ctx context.Context, tokenHash string, sess store.AdminSession) error
+	LookupSession(ctx context.Context, tokenHash string) (store.AdminSession, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+	IsAgentOwner(ctx context.Context, chainID, agentID, address string) (bool, error)
+	RecordAudit(ctx context.Context, e store.AuditEntry) error
+}
+
+// Config controls SIWE sign-in.
+type Config struct {
+	SIWEDomain string        // expected domain in SIWE messages; empty disables SIWE sign-in
+	SessionTTL time.Duration // lifetime of SIWE sessions
+	NonceTTL   time.Duration
+	Operators  []string // addresses whose SIWE sessions get the operator role
+}
+
+// ConfigFromEnv reads EXPLORER_SIWE_DOMAIN, EXPLORER_SESSION_TTL and
+// EXPLORER_OPERATOR_ADDRESSES (comma-separated).
+func ConfigFromEnv() Config {
+	cfg := Config{
+		SIWEDomain: strings.TrimSpace(os.Getenv("EXPLORER_SIWE_DOMAIN")),
+		SessionTTL: 12 * time.Hour,
+		NonceTTL:   10 * time.Minute,
+	}
+	if v := os.Getenv("EXPLORER_SESSION_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.SessionTTL = d
+		} else {
+			log.WithField("value", v).Warn("invalid EXPLORER_SESSION_TTL; using default")
+		}
+	}
+	for _, a := range strings.Split(os.Getenv("EXPLORER_OPERATOR_ADDRESSES"), ",") {
+		if a = strings.ToLower(strings.TrimSpace(a)); a != "" {
+			cfg.Operators = append(cfg.Operators, a)
+		}
+	}
+	return cfg
+}
+
+// Authenticator resolves and checks admin principals.
+type Authenticator struct {
+	store Store
+	cfg   Config
+	now   func() time.Time
+}
+
+// New returns an Authenticator backed by st.
+func New(st Store, cfg Config) *Authenticator {
+	if cfg.SessionTTL <= 0 {
+		cfg.SessionTTL = 12 * time.Hour
+	}
+	if cfg.NonceTTL <= 0 {
+		cfg.NonceTTL = 10 * time.Minute
+	}
+	return &Authenticator{store: st, cfg: cfg, now: time.Now}
+}
+
+// HashToken is how API keys and session tokens are stored: sha256, hex encoded.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey returns a new plaintext key, its hash and a short display prefix.
+func GenerateAPIKey() (key, hash, prefix string, err error) {
+	key, err = randomToken(apiKeyPrefix)
+	if err != nil {
+		return "", "", "", err
+	}
+	return key, HashToken(key), KeyPrefix(key), nil
+}
+
+// KeyPrefix is the display prefix stored for a key.
+func KeyPrefix(key string) string {
+	if len(key) > len(apiKeyPrefix)+6 {
+		return key[:len(apiKeyPrefix)+6]
+	}
+	return key
+}
+
+func randomToken(prefix string) (string, error) {
+	var b [32]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b[:]), nil
+}
+
+// bearer extracts the credential from "Authorization: Bearer ..." or X-API-Key.
+func bearer(r *http.Request) string {
+	if h := strings.TrimSpace(r.Header.Get("Authorization")); len(h) > 7 && strings.EqualFold(h[:7], "bearer ") {
+		return strings.TrimSpace(h[7:])
+	}
+	return strings.TrimSpace(r.Header.Get("X-API-Key"))
+}
+
+func (a *Authenticator) resolve(ctx context.Context, token string) (Principal, error) {
+	switch {
+	case strings.HasPrefix(token, sessionPrefix):
+		sess, err := a.store.LookupSession(ctx, HashToken(token))
+		if err != nil {
+			return Principal{}, err
+		}
+		return Principal{Kind: KindSession, ID: sess.Address, Role: sess.Role, Address: sess.Address}, nil
+	default:
+		k, err := a.store.LookupAPIKey(ctx, HashToken(token))
+		if err != nil {
+			return Principal{}, err
+		}
+		return Principal{Kind: KindAPIKey, ID: k.Prefix, Role: k.Role, Address: k.OwnerAddress}, nil
+	}
+}
+
+// Authenticate rejects requests without a valid API key or session token.
+func (a *Authenticator) Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := bearer(c.Request)
+		if token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="praxis-explorer-admin"`)
+			problem.Abort(c, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		p, err := a.resolve(c.Request.Context(), token)
+		if err != nil {
+			if !errors.Is(err, pgx.ErrNoRows) {
+				log.WithError(err).Error("[auth] credential lookup failed")
+				problem.Abort(c, http.StatusInternalServerError, "authentication unavailable")
+				return
+			}
+			problem.Abort(c, http.StatusUnauthorized, "invalid or expired credentials")
+			return
+		}
+		c.Set(principalKey, p)
+		c.Next()
+	}
+}
+
+// RequireRole allows only principals holding one of roles.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := PrincipalFrom(c)
+		if !ok {
+			problem.Abort(c, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		for _, r := range roles {
+			if p.Role == r {
+				c.Next()
+				return
+			}
+		}
+		problem.Abort(c, http.StatusForbidden, "insufficient role")
+	}
+}
+
+// RequireAgentAccess allows operators, and owners of the agent named by the
+// :chainId/:agentId route params.
+func (a *Authenticator) RequireAgentAccess() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, ok := PrincipalFrom(c)
+		if !ok {
+			problem.Abort(c, http.StatusUnauthorized, "authentication required")
+			return
+		}
+		if p.Role == RoleOperator {
+			c.Next()
+			return
+		}
+		if p.Role != RoleOwner || p.Address == "" {
+			problem.Abort(c, http.StatusForbidden, "insufficient role")
+			return
+		}
+		owns, err := a.store.IsAgentOwner(c.Request.Context(), c.Param("chainId"), c.Param("agentId"), p.Address)
+		if err != nil {
+			log.WithError(err).Error("[auth] ownership check failed")
+			problem.Abort(c, http.StatusInternalServerError, "authorization unavailable")
+			return
+		}
+		if !owns {
+			problem.Abort(c, http.StatusForbidden, "not an owner of this agent")
+			return
+		}
+		c.Next()
+	}
+}
+
+// PrincipalFrom returns the principal set by Authenticate.
+func PrincipalFrom(c *gin.Context) (Principal, bool) {
+	v, ok := c.Get(principalKey)
+	if !ok {
+		return Principal{}, false
+	}
+	p, ok := v.(Principal)
+	return p, ok
+}
+
+// Audit records every non-GET request it wraps in the admin audit log, including
+// the JSON request body and the response status. It must run after Authenticate.
+func (a *Authenticator) Audit(action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+		var body []byte
+		if c.Request.Body != nil {
+			body, _ = io.ReadAll(io.LimitReader(c.Request.Body, maxAuditBody))
+			c.Request.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), c.Request.Body))
+		}
+
+		c.Next()
+
+		p, _ := PrincipalFrom(c)
+		e := store.AuditEntry{
+			ActorKind: p.Kind,
+			ActorID:   p.ID,
+			Role:      p.Role,
+			Action:    action,
+			Method:    c.Request.Method,
+			Path:      c.Request.URL.Path,
+			ChainID:   c.Param("chainId"),
+			AgentID:   c.Param("agentId"),
+			Status:    c.Writer.Status(),
+			RemoteIP:  c.ClientIP(),
+		}
+		if len(body) > 0 {
+			var req map[string]any
+			if json.Unmarshal(body, &req) == nil {
+				e.Request = req
+			}
+		}
+		// the response is already written; use a fresh context so a client hang-up
+		// doesn't lose the audit row
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := a.store.RecordAudit(ctx, e); err != nil {
+			log.WithError(err).WithFields(log.Fields{"action": action, "actor": p.ID}).Error("[auth] failed writing audit log")
+		}
+	}
+}
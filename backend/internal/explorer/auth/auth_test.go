@@ -0,0 +1,272 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+type fakeStore struct {
+	mu       sync.Mutex
+	keys     map[string]store.APIKey
+	nonces   map[string]time.Time
+	sessions map[string]store.AdminSession
+	owners   map[string]string // "chain/agent" -> address
+	audit    []store.AuditEntry
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		keys:     map[string]store.APIKey{},
+		nonces:   map[string]time.Time{},
+		sessions: map[string]store.AdminSession{},
+		owners:   map[string]string{},
+	}
+}
+
+func (f *fakeStore) LookupAPIKey(_ context.Context, h string) (store.APIKey, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	k, ok := f.keys[h]
+	if !ok || k.RevokedAt != nil {
+		return k, pgx.ErrNoRows
+	}
+	return k, nil
+}
+func (f *fakeStore) CreateSIWENonce(_ context.Context, n string, exp time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nonces[n] = exp
+	return nil
+}
+func (f *fakeStore) ConsumeSIWENonce(_ context.Context, n string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	exp, ok := f.nonces[n]
+	delete(f.nonces, n)
+	return ok && time.Now().Before(exp), nil
+}
+func (f *fakeStore) CreateSession(_ context.Context, h string, s store.AdminSession) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[h] = s
+	return nil
+}
+func (f *fakeStore) LookupSession(_ context.Context, h string) (store.AdminSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, ok := f.sessions[h]
+	if !ok || time.Now().After(s.ExpiresAt) {
+		return s, pgx.ErrNoRows
+	}
+	return s, nil
+}
+func (f *fakeStore) DeleteSession(_ context.Context, h string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.sessions, h)
+	return nil
+}
+func (f *fakeStore) IsAgentOwner(_ context.Context, chainID, agentID, addr string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.owners[chainID+"/"+agentID] == strings.ToLower(addr), nil
+}
+func (f *fakeStore) RecordAudit(_ context.Context, e store.AuditEntry) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.audit = append(f.audit, e)
+	return nil
+}
+
+func (f *fakeStore) addKey(role, owner string) string {
+	key, hash, prefix, _ := GenerateAPIKey()
+	f.keys[hash] = store.APIKey{Prefix: prefix, Role: role, OwnerAddress: owner}
+	return key
+}
+
+func testRouter(a *Authenticator) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	a.RegisterRoutes(r)
+	admin := r.Group("/admin", a.Authenticate())
+	admin.POST("/refresh", RequireRole(RoleOperator), a.Audit("agent.refresh"), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	admin.POST("/agents/:chainId/:agentId/refresh", a.RequireAgentAccess(), a.Audit("agent.refresh"), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	})
+	return r
+}
+
+func do(r http.Handler, method, path, token string, body any) *httptest.ResponseRecorder {
+	var buf bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestAdminRoutes_RolesAndAudit(t *testing.T) {
+	st := newFakeStore()
+	st.owners["sepolia/7"] = "0x00000000000000000000000000000000000000aa"
+	operator := st.addKey(RoleOperator, "")
+	owner := st.addKey(RoleOwner, "0x00000000000000000000000000000000000000aa")
+	r := testRouter(New(st, Config{}))
+
+	cases := []struct {
+		name, path, token string
+		want              int
+	}{
+		{"anonymous", "/admin/refresh", "", http.StatusUnauthorized},
+		{"unknown key", "/admin/refresh", "pxk_nope", http.StatusUnauthorized},
+		{"owner on operator route", "/admin/refresh", owner, http.StatusForbidden},
+		{"operator", "/admin/refresh", operator, http.StatusOK},
+		{"owner of agent", "/admin/agents/sepolia/7/refresh", owner, http.StatusOK},
+		{"owner of other agent", "/admin/agents/sepolia/8/refresh", owner, http.StatusForbidden},
+		{"operator on any agent", "/admin/agents/sepolia/8/refresh", operator, http.StatusOK},
+	}
+	for _, tc := range cases {
+		if w := do(r, http.MethodPost, tc.path, tc.token, map[string]any{"chainId": "sepolia"}); w.Code != tc.want {
+			t.Fatalf("%s: got %d want %d (%s)", tc.name, w.Code, tc.want, w.Body.String())
+		}
+	}
+
+	// only requests that passed authorization reach the audit middleware
+	if len(st.audit) != 3 {
+		t.Fatalf("expected 3 audit entries, got %d", len(st.audit))
+	}
+	e := st.audit[1]
+	if e.Role != RoleOwner || e.ChainID != "sepolia" || e.AgentID != "7" || e.Status != http.StatusOK || e.Request["chainId"] != "sepolia" {
+		t.Fatalf("unexpected audit entry: %+v", e)
+	}
+}
+
+func siweMessage(domain, addr, nonce string, issued time.Time) string {
+	return fmt.Sprintf("%s wants you to sign in with your Ethereum account:\n%s\n\nSign in to Praxis Explorer admin.\n\nURI: https://%s/admin\nVersion: 1\nChain ID: 11155111\nNonce: %s\nIssued At: %s",
+		domain, addr, domain, nonce, issued.UTC().Format(time.RFC3339))
+}
+
+func TestSIWE_SignInIssuesOwnerSession(t *testing.T) {
+	st := newFakeStore()
+	a := New(st, Config{SIWEDomain: "explorer.example"})
+	r := testRouter(a)
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	st.owners["sepolia/7"] = strings.ToLower(addr.Hex())
+
+	w := do(r, http.MethodGet, "/auth/siwe/nonce", "", nil)
+	var nonceResp struct{ Nonce string }
+	_ = json.Unmarshal(w.Body.Bytes(), &nonceResp)
+	if nonceResp.Nonce == "" {
+		t.Fatalf("no nonce issued: %s", w.Body.String())
+	}
+
+	msg := siweMessage("explorer.example", addr.Hex(), nonceResp.Nonce, time.Now())
+	sig, _ := crypto.Sign(accounts.TextHash([]byte(msg)), key)
+	sig[64] += 27 // wallets return V as 27/28
+
+	// wrong domain is rejected without burning the nonce
+	bad := siweMessage("evil.example", addr.Hex(), nonceResp.Nonce, time.Now())
+	badSig, _ := crypto.Sign(accounts.TextHash([]byte(bad)), key)
+	if w := do(r, http.MethodPost, "/auth/siwe/verify", "", map[string]string{"message": bad, "signature": hexutil.Encode(badSig)}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("wrong domain: got %d", w.Code)
+	}
+
+	w = do(r, http.MethodPost, "/auth/siwe/verify", "", map[string]string{"message": msg, "signature": hexutil.Encode(sig)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("verify: %d %s", w.Code, w.Body.String())
+	}
+	var sess struct{ Token, Role string }
+	_ = json.Unmarshal(w.Body.Bytes(), &sess)
+	if sess.Role != RoleOwner || !strings.HasPrefix(sess.Token, sessionPrefix) {
+		t.Fatalf("unexpected session: %+v", sess)
+	}
+
+	// replaying the same signed message fails: the nonce is single-use
+	if w := do(r, http.MethodPost, "/auth/siwe/verify", "", map[string]string{"message": msg, "signature": hexutil.Encode(sig)}); w.Code != http.StatusUnauthorized {
+		t.Fatalf("replay: got %d", w.Code)
+	}
+
+	if w := do(r, http.MethodPost, "/admin/agents/sepolia/7/refresh", sess.Token, nil); w.Code != http.StatusOK {
+		t.Fatalf("owner session on own agent: %d", w.Code)
+	}
+	if w := do(r, http.MethodPost, "/admin/refresh", sess.Token, nil); w.Code != http.StatusForbidden {
+		t.Fatalf("owner session on operator route: %d", w.Code)
+	}
+
+	if w := do(r, http.MethodDelete, "/auth/session", sess.Token, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("sign out: %d", w.Code)
+	}
+	if w := do(r, http.MethodPost, "/admin/agents/sepolia/7/refresh", sess.Token, nil); w.Code != http.StatusUnauthorized {
+		t.Fatalf("signed-out session still accepted: %d", w.Code)
+	}
+}
+
+func TestSIWE_RequiresConfiguredDomain(t *testing.T) {
+	st := newFakeStore()
+	r := testRouter(New(st, Config{}))
+
+	key, _ := crypto.GenerateKey()
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	st.nonces["abc"] = time.Now().Add(time.Minute)
+
+	if w := do(r, http.MethodGet, "/auth/siwe/nonce", "", nil); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("nonce without domain: got %d", w.Code)
+	}
+
+	// a message bound only to the Host header the caller sent is not accepted
+	msg := siweMessage("evil.example", addr.Hex(), "abc", time.Now())
+	sig, _ := crypto.Sign(accounts.TextHash([]byte(msg)), key)
+	body, _ := json.Marshal(map[string]string{"message": msg, "signature": hexutil.Encode(sig)})
+	req := httptest.NewRequest(http.MethodPost, "/auth/siwe/verify", bytes.NewReader(body))
+	req.Host = "evil.example"
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("verify with Host-only domain: got %d %s", w.Code, w.Body.String())
+	}
+	if _, ok := st.nonces["abc"]; !ok {
+		t.Fatal("nonce consumed by a rejected sign-in")
+	}
+}
+
+func TestParseSIWE_RejectsMalformed(t *testing.T) {
+	if _, err := ParseSIWE("hello"); err == nil {
+		t.Fatal("expected error for non-SIWE text")
+	}
+	msg := siweMessage("explorer.example", "0x00000000000000000000000000000000000000aa", "abc", time.Now())
+	if _, err := ParseSIWE(strings.Replace(msg, "Version: 1", "Version: 2", 1)); err == nil {
+		t.Fatal("expected error for unsupported version")
+	}
+	m, err := ParseSIWE(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.ChainID != 11155111 || m.Nonce != "abc" || m.Statement == "" {
+		t.Fatalf("unexpected parse: %+v", m)
+	}
+}
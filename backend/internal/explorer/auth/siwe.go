@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/gin-gonic/gin"
+	erc "github.com/praxis/praxis-explorer/internal/erc8004"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// SIWEMessage is a parsed EIP-4361 (Sign-In With Ethereum) message.
+type SIWEMessage struct {
+	Domain         string
+	Address        common.Address
+	Statement      string
+	URI            string
+	Version        string
+	ChainID        int64
+	Nonce          string
+	IssuedAt       time.Time
+	ExpirationTime *time.Time
+	NotBefore      *time.Time
+}
+
+const siweHeaderSuffix = " wants you to sign in with your Ethereum account:"
+
+// ParseSIWE parses the EIP-4361 text format.
+func ParseSIWE(msg string) (SIWEMessage, error) {
+	var m SIWEMessage
+	lines := strings.Split(strings.ReplaceAll(msg, "\r\n", "\n"), "\n")
+	if len(lines) < 2 || !strings.HasSuffix(lines[0], siweHeaderSuffix) {
+		return m, fmt.Errorf("siwe: missing header line")
+	}
+	m.Domain = strings.TrimSuffix(lines[0], siweHeaderSuffix)
+	if !common.IsHexAddress(strings.TrimSpace(lines[1])) {
+		return m, fmt.Errorf("siwe: invalid address line")
+	}
+	m.Address = common.HexToAddress(strings.TrimSpace(lines[1]))
+
+	i := 2
+	// optional statement block: blank line, statement, blank line
+	if i < len(lines) && lines[i] == "" {
+		i++
+		if i < len(lines) && !strings.Contains(lines[i], ": ") {
+			m.Statement = lines[i]
+			i++
+		}
+		if i < len(lines) && lines[i] == "" {
+			i++
+		}
+	}
+
+	for ; i < len(lines); i++ {
+		key, val, ok := strings.Cut(lines[i], ": ")
+		if !ok {
+			if strings.TrimSpace(lines[i]) == "Resources:" || strings.HasPrefix(lines[i], "- ") || strings.TrimSpace(lines[i]) == "" {
+				continue
+			}
+			return m, fmt.Errorf("siwe: unexpected line %q", lines[i])
+		}
+		switch key {
+		case "URI":
+			m.URI = val
+		case "Version":
+			m.Version = val
+		case "Chain ID":
+			id, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return m, fmt.Errorf("siwe: invalid chain id")
+			}
+			m.ChainID = id
+		case "Nonce":
+			m.Nonce = val
+		case "Issued At":
+			t, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				return m, fmt.Errorf("siwe: invalid issued at")
+			}
+			m.IssuedAt = t
+		case "Expiration Time":
+			t, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				return m, fmt.Errorf("siwe: invalid expiration time")
+			}
+			m.ExpirationTime = &t
+		case "Not Before":
+			t, err := time.Parse(time.RFC3339, val)
+			if err != nil {
+				return m, fmt.Errorf("siwe: invalid not before")
+			}
+			m.NotBefore = &t
+		}
+	}
+	if m.Version != "1" {
+		return m, fmt.Errorf("siwe: unsupported version %q", m.Version)
+	}
+	if m.URI == "" || m.Nonce == "" || m.IssuedAt.IsZero() {
+		return m, fmt.Errorf("siwe: URI, Nonce and Issued At are required")
+	}
+	return m, nil
+}
+
+// RegisterRoutes mounts the SIWE sign-in endpoints under /auth.
+func (a *Authenticator) RegisterRoutes(r gin.IRouter) {
+	g := r.Group("/auth")
+
+	// without a configured domain there is nothing to bind messages to: the
+	// request Host is the caller's to choose
+	siwe := g.Group("/siwe", func(c *gin.Context) {
+		if a.cfg.SIWEDomain == "" {
+			problem.Write(c, http.StatusServiceUnavailable, "SIWE sign-in is disabled: EXPLORER_SIWE_DOMAIN is not set")
+			c.Abort()
+			return
+		}
+		c.Next()
+	})
+
+	siwe.GET("/nonce", func(c *gin.Context) {
+		var b [16]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		nonce := hex.EncodeToString(b[:])
+		expires := a.now().Add(a.cfg.NonceTTL)
+		if err := a.store.CreateSIWENonce(c, nonce, expires); err != nil {
+			problem.Write(c, http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"nonce": nonce, "expiresAt": expires})
+	})
+
+	siwe.POST("/verify", func(c *gin.Context) {
+		var req struct {
+			Message   string `json:"message"`
+			Signature string `json:"signature"`
+		}
+		if err := c.ShouldBindJSON(&req); err != nil || req.Message == "" || req.Signature == "" {
+			problem.Write(c, http.StatusBadRequest, "message and signature required")
+			return
+		}
+		token, sess, status, err := a.signIn(c, req.Message, req.Signature)
+		if err != nil {
+			problem.Write(c, status, err.Error())
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"token": token, "address": sess.Address, "role": sess.Role, "expiresAt": sess.ExpiresAt})
+	})
+
+	authed := g.Group("", a.Authenticate())
+	authed.GET("/session", func(c *gin.Context) {
+		p, _ := PrincipalFrom(c)
+		c.JSON(http.StatusOK, p)
+	})
+	authed.DELETE("/session", func(c *gin.Context) {
+		if p, _ := PrincipalFrom(c); p.Kind == KindSession {
+			if err := a.store.DeleteSession(c, HashToken(bearer(c.Request))); err != nil {
+				problem.Write(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+		c.Status(http.StatusNoContent)
+	})
+}
+
+// signIn verifies a signed SIWE message and issues a session token.
+func (a *Authenticator) signIn(c *gin.Context, text, signature string) (string, store.AdminSession, int, error) {
+	msg, err := ParseSIWE(text)
+	if err != nil {
+		return "", store.AdminSession{}, http.StatusBadRequest, err
+	}
+	domain := a.cfg.SIWEDomain
+	if domain == "" || !strings.EqualFold(msg.Domain, domain) {
+		return "", store.AdminSession{}, http.StatusUnauthorized, fmt.Errorf("siwe: domain %q is not %q", msg.Domain, domain)
+	}
+	now := a.now()
+	if msg.ExpirationTime != nil && now.After(*msg.ExpirationTime) {
+		return "", store.AdminSession{}, http.StatusUnauthorized, fmt.Errorf("siwe: message expired")
+	}
+	if msg.NotBefore != nil && now.Before(*msg.NotBefore) {
+		return "", store.AdminSession{}, http.StatusUnauthorized, fmt.Errorf("siwe: message not yet valid")
+	}
+	if !erc.VerifyEIP191([]byte(text), signature, msg.Address) {
+		return "", store.AdminSession{}, http.StatusUnauthorized, fmt.Errorf("siwe: signature does not match address")
+	}
+	// consume the nonce only once the signature checks out, so garbage can't burn it
+	ok, err := a.store.ConsumeSIWENonce(c, msg.Nonce)
+	if err != nil {
+		return "", store.AdminSession{}, http.StatusInternalServerError, err
+	}
+	if !ok {
+		return "", store.AdminSession{}, http.StatusUnauthorized, fmt.Errorf("siwe: unknown or expired nonce")
+	}
+
+	addr := strings.ToLower(msg.Address.Hex())
+	sess := store.AdminSession{Address: addr, Role: RoleOwner, ChainID: msg.ChainID, ExpiresAt: now.Add(a.cfg.SessionTTL)}
+	if msg.ExpirationTime != nil && msg.ExpirationTime.Before(sess.ExpiresAt) {
+		sess.ExpiresAt = *msg.ExpirationTime
+	}
+	for _, op := range a.cfg.Operators {
+		if op == addr {
+			sess.Role = RoleOperator
+		}
+	}
+	token, err := randomToken(sessionPrefix)
+	if err != nil {
+		return "", store.AdminSession{}, http.StatusInternalServerError, err
+	}
+	if err := a.store.CreateSession(c, HashToken(token), sess); err != nil {
+		return "", store.AdminSession{}, http.StatusInternalServerError, err
+	}
+	log.WithFields(log.Fields{"address": addr, "role": sess.Role}).Info("[auth] siwe session issued")
+	return token, sess, http.StatusOK, nil
+}
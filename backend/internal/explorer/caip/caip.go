@@ -0,0 +1,146 @@
+// Package caip names chains by CAIP-2 chain id (eip155:11155111), the key agents
+// are stored under, and maps the network names used in config files and API
+// requests (sepolia) to them. Addresses are CAIP-10 account ids on those chains
+// (eip155:11155111:0xAbC...).
+package caip
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EVM is the CAIP-2 namespace of EVM chains, whose reference is the chain id.
+const EVM = "eip155"
+
+var (
+	namespacePattern = regexp.MustCompile(`^[-a-z0-9]{3,8}$`)
+	referencePattern = regexp.MustCompile(`^[-_a-zA-Z0-9]{1,32}$`)
+	accountPattern   = regexp.MustCompile(`^[-.%a-zA-Z0-9]{1,128}$`)
+)
+
+// EVMChain is the CAIP-2 id of the EVM chain with chain id id.
+func EVMChain(id uint64) string {
+	return EVM + ":" + strconv.FormatUint(id, 10)
+}
+
+// ParseChain splits a CAIP-2 chain id into namespace and reference.
+func ParseChain(s string) (namespace, reference string, err error) {
+	namespace, reference, ok := strings.Cut(s, ":")
+	if !ok || !namespacePattern.MatchString(namespace) || !referencePattern.MatchString(reference) {
+		return "", "", fmt.Errorf("invalid CAIP-2 chain id %q", s)
+	}
+	return namespace, reference, nil
+}
+
+// IsChain reports whether s is a CAIP-2 chain id.
+func IsChain(s string) bool {
+	_, _, err := ParseChain(s)
+	return err == nil
+}
+
+// EVMChainID returns the chain id of an eip155 CAIP-2 id.
+func EVMChainID(s string) (uint64, bool) {
+	ns, ref, err := ParseChain(s)
+	if err != nil || ns != EVM {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(ref, 10, 64)
+	return id, err == nil
+}
+
+// KnownEVMChains maps well-known network names to EVM chain ids. A configured
+// network with one of these names needs no explicit chain id.
+var KnownEVMChains = map[string]uint64{
+	"mainnet":          1,
+	"ethereum":         1,
+	"sepolia":          11155111,
+	"holesky":          17000,
+	"base":             8453,
+	"base-sepolia":     84532,
+	"optimism":         10,
+	"op-sepolia":       11155420,
+	"arbitrum":         42161,
+	"arbitrum-sepolia": 421614,
+	"polygon":          137,
+	"polygon-amoy":     80002,
+}
+
+var (
+	aliasMu sync.RWMutex
+	aliases = map[string]string{}
+)
+
+// RegisterAlias makes Normalize resolve alias, case-insensitively, to chain. The
+// indexer registers the name of every configured network.
+func RegisterAlias(alias, chain string) {
+	aliasMu.Lock()
+	defer aliasMu.Unlock()
+	aliases[strings.ToLower(alias)] = chain
+}
+
+// Normalize returns the CAIP-2 id s stands for: s itself when it is one, else the
+// chain of a registered or well-known alias. Anything else is returned unchanged,
+// so an unknown network simply matches no agents.
+func Normalize(s string) string {
+	s = strings.TrimSpace(s)
+	if IsChain(s) {
+		return s
+	}
+	key := strings.ToLower(s)
+	aliasMu.RLock()
+	chain, ok := aliases[key]
+	aliasMu.RUnlock()
+	if ok {
+		return chain
+	}
+	if id, ok := KnownEVMChains[key]; ok {
+		return EVMChain(id)
+	}
+	return s
+}
+
+// Account is the CAIP-10 id of an EVM address on chain, checksummed.
+func Account(chain string, addr common.Address) string {
+	return chain + ":" + addr.Hex()
+}
+
+// ParseAccount splits a CAIP-10 account id into its CAIP-2 chain and address. The
+// chain may also be given by network name.
+func ParseAccount(s string) (chain, address string, err error) {
+	s = strings.TrimSpace(s)
+	i := strings.LastIndex(s, ":")
+	if i < 0 {
+		return "", "", fmt.Errorf("invalid CAIP-10 account id %q", s)
+	}
+	chain, address = Normalize(s[:i]), s[i+1:]
+	if !IsChain(chain) || !accountPattern.MatchString(address) {
+		return "", "", fmt.Errorf("invalid CAIP-10 account id %q", s)
+	}
+	return chain, address, nil
+}
+
+// NormalizeAccount returns the checksummed CAIP-10 id of s, a CAIP-10 id or, on
+// chain, a bare 0x address. Accounts on other namespaces are kept as given; false
+// means s is not an account id at all (or not an EVM address on an eip155 chain).
+func NormalizeAccount(s, chain string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if common.IsHexAddress(s) && strings.HasPrefix(s, "0x") && IsChain(chain) {
+		return Account(chain, common.HexToAddress(s)), true
+	}
+	c, addr, err := ParseAccount(s)
+	if err != nil {
+		return "", false
+	}
+	if ns, _, _ := ParseChain(c); ns != EVM {
+		return c + ":" + addr, true
+	}
+	if !common.IsHexAddress(addr) || !strings.HasPrefix(addr, "0x") {
+		return "", false
+	}
+	return Account(c, common.HexToAddress(addr)), true
+}
@@ -0,0 +1,72 @@
+package caip
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestParseChain(t *testing.T) {
+	for _, s := range []string{"eip155:1", "eip155:11155111", "cosmos:cosmoshub-4", "bip122:000000000019d6689c085ae165831e93"} {
+		if !IsChain(s) {
+			t.Errorf("IsChain(%q) = false", s)
+		}
+	}
+	for _, s := range []string{"", "sepolia", "eip155:", ":1", "EIP155:1", "ab:1", "eip155:1:0xabc"} {
+		if IsChain(s) {
+			t.Errorf("IsChain(%q) = true", s)
+		}
+	}
+	if id, ok := EVMChainID("eip155:84532"); !ok || id != 84532 {
+		t.Errorf("EVMChainID = %d, %v", id, ok)
+	}
+	if _, ok := EVMChainID("cosmos:cosmoshub-4"); ok {
+		t.Error("non-EVM chain has an EVM chain id")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	RegisterAlias("devnet", "eip155:31337")
+	cases := map[string]string{
+		"eip155:11155111": "eip155:11155111",
+		"sepolia":         "eip155:11155111",
+		" Base-Sepolia ":  "eip155:84532",
+		"DEVNET":          "eip155:31337",
+		"nowhere":         "nowhere",
+	}
+	for in, want := range cases {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if got := EVMChain(1); got != "eip155:1" {
+		t.Errorf("EVMChain(1) = %q", got)
+	}
+}
+
+func TestNormalizeAccount(t *testing.T) {
+	const checksummed = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+	const sepolia = "eip155:11155111"
+	cases := []struct {
+		in, chain, want string
+		ok              bool
+	}{
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", sepolia, sepolia + ":" + checksummed, true},
+		{"eip155:1:0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED", sepolia, "eip155:1:" + checksummed, true},
+		{"sepolia:" + checksummed, "", sepolia + ":" + checksummed, true},
+		{"cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0", sepolia, "cosmos:cosmoshub-4:cosmos1t2uflqwqe0fsj0shcfkrvpukewcw40yjj6hdc0", true},
+		{"0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed", "", "", false},
+		{"eip155:1:not-an-address", sepolia, "", false},
+		{"agent.example", sepolia, "", false},
+		{"", sepolia, "", false},
+	}
+	for _, c := range cases {
+		got, ok := NormalizeAccount(c.in, c.chain)
+		if got != c.want || ok != c.ok {
+			t.Errorf("NormalizeAccount(%q, %q) = %q, %v; want %q, %v", c.in, c.chain, got, ok, c.want, c.ok)
+		}
+	}
+	if got := Account(sepolia, common.HexToAddress(checksummed)); got != sepolia+":"+checksummed {
+		t.Errorf("Account = %q", got)
+	}
+}
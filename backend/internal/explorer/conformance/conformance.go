@@ -0,0 +1,348 @@
+// Package conformance checks that an indexed agent actually speaks A2A by running a
+// scripted set of JSON-RPC calls against the url advertised in its agent card.
+package conformance
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// Check names, in the order they run.
+const (
+	CheckEndpoint       = "endpoint"
+	CheckParseError     = "parse-error"
+	CheckMethodNotFound = "method-not-found"
+	CheckMessageSend    = "message/send"
+	CheckTasksGet       = "tasks/get"
+	CheckMessageStream  = "message/stream"
+)
+
+// Check outcomes
+const (
+	StatusPass = "pass"
+	StatusFail = "fail"
+	StatusSkip = "skip"
+)
+
+// JSON-RPC / A2A error codes the checks expect.
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeTaskNotFound   = -32001
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Detail     string  `json:"detail,omitempty"`
+	DurationMs float64 `json:"durationMs"`
+}
+
+// Report is the outcome of a full run against one agent. Passed is true when no
+// check failed (skipped checks don't count against the agent).
+type Report struct {
+	ChainID   string    `json:"chainId"`
+	AgentID   int64     `json:"agentId"`
+	URL       string    `json:"url"`
+	StartedAt time.Time `json:"startedAt"`
+	Passed    bool      `json:"passed"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Checker runs the A2A conformance script.
+type Checker struct {
+	client  *http.Client
+	timeout time.Duration
+}
+
+// New returns a checker using client (or a client with a 15s timeout when nil).
+func New(client *http.Client) *Checker {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &Checker{client: client, timeout: 15 * time.Second}
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      any             `json:"id"`
+	Result  json.RawMessage `json:"result"`
+	Error   *rpcError       `json:"error"`
+}
+
+// Run executes every check against the agent's card url.
+func (c *Checker) Run(ctx context.Context, agent store.AgentRow) Report {
+	rep := Report{ChainID: agent.ChainID, AgentID: agent.AgentID, StartedAt: time.Now().UTC()}
+	rep.URL, _ = agent.CardJSON["url"].(string)
+	rep.URL = strings.TrimSpace(rep.URL)
+
+	run := func(name string, fn func() (string, string)) string {
+		start := time.Now()
+		status, detail := fn()
+		rep.Checks = append(rep.Checks, Result{Name: name, Status: status, Detail: detail, DurationMs: float64(time.Since(start).Microseconds()) / 1000})
+		return status
+	}
+
+	if run(CheckEndpoint, func() (string, string) {
+		if rep.URL == "" {
+			return StatusFail, "agent card has no url"
+		}
+		if !strings.HasPrefix(rep.URL, "http://") && !strings.HasPrefix(rep.URL, "https://") {
+			return StatusFail, "card url is not http(s): " + rep.URL
+		}
+		return StatusPass, ""
+	}) != StatusPass {
+		for _, name := range []string{CheckParseError, CheckMethodNotFound, CheckMessageSend, CheckTasksGet, CheckMessageStream} {
+			rep.Checks = append(rep.Checks, Result{Name: name, Status: StatusSkip, Detail: "no usable endpoint"})
+		}
+		return rep.finish()
+	}
+
+	run(CheckParseError, func() (string, string) {
+		resp, err := c.post(ctx, rep.URL, []byte(`{"jsonrpc":"2.0","id":`), "application/json")
+		if err != nil {
+			return StatusFail, err.Error()
+		}
+		return expectError(resp, codeParseError)
+	})
+
+	run(CheckMethodNotFound, func() (string, string) {
+		resp, err := c.call(ctx, rep.URL, newID(), "praxis/conformance-unknown", map[string]any{})
+		if err != nil {
+			return StatusFail, err.Error()
+		}
+		return expectError(resp, codeMethodNotFound)
+	})
+
+	var taskID string
+	run(CheckMessageSend, func() (string, string) {
+		id := newID()
+		resp, err := c.call(ctx, rep.URL, id, "message/send", pingParams())
+		if err != nil {
+			return StatusFail, err.Error()
+		}
+		if status, detail := expectEnvelope(resp, id); status != StatusPass {
+			return status, detail
+		}
+		if resp.Error != nil {
+			return StatusFail, fmt.Sprintf("error %d: %s", resp.Error.Code, resp.Error.Message)
+		}
+		var result struct {
+			Kind string `json:"kind"`
+			ID   string `json:"id"`
+		}
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			return StatusFail, "result is not an object: " + err.Error()
+		}
+		switch result.Kind {
+		case "message":
+			return StatusPass, "agent replied with a message"
+		case "task":
+			if result.ID == "" {
+				return StatusFail, "task result has no id"
+			}
+			taskID = result.ID
+			return StatusPass, "agent created task " + taskID
+		default:
+			return StatusFail, fmt.Sprintf("unexpected result kind %q", result.Kind)
+		}
+	})
+
+	run(CheckTasksGet, func() (string, string) {
+		id := newID()
+		if taskID == "" {
+			// no task to look up: an unknown id must yield TaskNotFoundError
+			resp, err := c.call(ctx, rep.URL, id, "tasks/get", map[string]any{"id": "praxis-conformance-" + newID()})
+			if err != nil {
+				return StatusFail, err.Error()
+			}
+			return expectError(resp, codeTaskNotFound)
+		}
+		resp, err := c.call(ctx, rep.URL, id, "tasks/get", map[string]any{"id": taskID})
+		if err != nil {
+			return StatusFail, err.Error()
+		}
+		if status, detail := expectEnvelope(resp, id); status != StatusPass {
+			return status, detail
+		}
+		if resp.Error != nil {
+			return StatusFail, fmt.Sprintf("error %d: %s", resp.Error.Code, resp.Error.Message)
+		}
+		var task struct {
+			Kind string `json:"kind"`
+			ID   string `json:"id"`
+		}
+		if err := json.Unmarshal(resp.Result, &task); err != nil || task.ID != taskID {
+			return StatusFail, "tasks/get did not return the created task"
+		}
+		return StatusPass, ""
+	})
+
+	run(CheckMessageStream, func() (string, string) {
+		if streaming, _ := agent.Capabilities["streaming"].(bool); !streaming {
+			return StatusSkip, "card does not advertise capabilities.streaming"
+		}
+		return c.checkStream(ctx, rep.URL)
+	})
+
+	return rep.finish()
+}
+
+func (r Report) finish() Report {
+	r.Passed = true
+	for _, ch := range r.Checks {
+		if ch.Status == StatusFail {
+			r.Passed = false
+		}
+	}
+	return r
+}
+
+func (c *Checker) call(ctx context.Context, url string, id string, method string, params any) (rpcResponse, error) {
+	body, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	if err != nil {
+		return rpcResponse{}, err
+	}
+	return c.post(ctx, url, body, "application/json")
+}
+
+func (c *Checker) post(ctx context.Context, url string, body []byte, accept string) (rpcResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return rpcResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", accept)
+	resp, err := c.client.Do(req) // #nosec G107 -- url comes from the indexed agent card
+	if err != nil {
+		return rpcResponse{}, err
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return rpcResponse{}, err
+	}
+	var out rpcResponse
+	if err := json.Unmarshal(payload, &out); err != nil {
+		return rpcResponse{}, fmt.Errorf("HTTP %d: response is not JSON-RPC: %w", resp.StatusCode, err)
+	}
+	return out, nil
+}
+
+// checkStream calls message/stream and requires an SSE response whose first event is
+// a JSON-RPC response carrying an A2A stream item for our request id.
+func (c *Checker) checkStream(ctx context.Context, url string) (string, string) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	id := newID()
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": "message/stream", "params": pingParams()})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.client.Do(req) // #nosec G107
+	if err != nil {
+		return StatusFail, err.Error()
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		return StatusFail, fmt.Sprintf("expected text/event-stream, got %q (HTTP %d)", ct, resp.StatusCode)
+	}
+
+	sc := bufio.NewScanner(io.LimitReader(resp.Body, 1<<20))
+	for sc.Scan() {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		var ev rpcResponse
+		if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &ev); err != nil {
+			return StatusFail, "stream event is not JSON-RPC: " + err.Error()
+		}
+		if status, detail := expectEnvelope(ev, id); status != StatusPass {
+			return status, detail
+		}
+		if ev.Error != nil {
+			return StatusFail, fmt.Sprintf("error %d: %s", ev.Error.Code, ev.Error.Message)
+		}
+		var item struct {
+			Kind string `json:"kind"`
+		}
+		_ = json.Unmarshal(ev.Result, &item)
+		switch item.Kind {
+		case "message", "task", "status-update", "artifact-update":
+			return StatusPass, "first event: " + item.Kind
+		default:
+			return StatusFail, fmt.Sprintf("unexpected stream item kind %q", item.Kind)
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return StatusFail, err.Error()
+	}
+	return StatusFail, "stream closed without events"
+}
+
+func expectEnvelope(resp rpcResponse, id string) (string, string) {
+	if resp.JSONRPC != "2.0" {
+		return StatusFail, fmt.Sprintf("jsonrpc must be \"2.0\", got %q", resp.JSONRPC)
+	}
+	if got, _ := resp.ID.(string); got != id {
+		return StatusFail, fmt.Sprintf("response id %v does not match request id %s", resp.ID, id)
+	}
+	return StatusPass, ""
+}
+
+func expectError(resp rpcResponse, code int) (string, string) {
+	if resp.JSONRPC != "2.0" {
+		return StatusFail, fmt.Sprintf("jsonrpc must be \"2.0\", got %q", resp.JSONRPC)
+	}
+	if resp.Error == nil {
+		return StatusFail, fmt.Sprintf("expected error %d, got a result", code)
+	}
+	if resp.Error.Code != code {
+		return StatusFail, fmt.Sprintf("expected error %d, got %d (%s)", code, resp.Error.Code, resp.Error.Message)
+	}
+	return StatusPass, ""
+}
+
+// pingParams is a harmless user message used to exercise message/send and message/stream.
+func pingParams() map[string]any {
+	return map[string]any{
+		"message": map[string]any{
+			"kind":      "message",
+			"role":      "user",
+			"messageId": newID(),
+			"parts":     []any{map[string]any{"kind": "text", "text": "ping"}},
+			"metadata":  map[string]any{"source": "praxis-explorer-conformance"},
+		},
+		"configuration": map[string]any{"blocking": true},
+	}
+}
+
+func newID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,117 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// a2aStandIn is a minimal A2A JSON-RPC server: message/send creates a task that
+// tasks/get can read back, and message/stream answers with one SSE event.
+func a2aStandIn(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			JSONRPC string         `json:"jsonrpc"`
+			ID      any            `json:"id"`
+			Method  string         `json:"method"`
+			Params  map[string]any `json:"params"`
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32700,"message":"Parse error"}}`))
+			return
+		}
+		reply := func(v map[string]any) {
+			v["jsonrpc"] = "2.0"
+			v["id"] = req.ID
+			_ = json.NewEncoder(w).Encode(v)
+		}
+		switch req.Method {
+		case "message/send":
+			reply(map[string]any{"result": map[string]any{"kind": "task", "id": "task-1", "status": map[string]any{"state": "completed"}}})
+		case "tasks/get":
+			if req.Params["id"] != "task-1" {
+				reply(map[string]any{"error": map[string]any{"code": -32001, "message": "Task not found"}})
+				return
+			}
+			reply(map[string]any{"result": map[string]any{"kind": "task", "id": "task-1"}})
+		case "message/stream":
+			w.Header().Set("Content-Type", "text/event-stream")
+			b, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": map[string]any{"kind": "status-update", "taskId": "task-2"}})
+			_, _ = fmt.Fprintf(w, "data: %s\n\n", b)
+		default:
+			reply(map[string]any{"error": map[string]any{"code": -32601, "message": "Method not found"}})
+		}
+	}))
+}
+
+func statuses(rep Report) map[string]string {
+	out := map[string]string{}
+	for _, c := range rep.Checks {
+		out[c.Name] = c.Status
+	}
+	return out
+}
+
+func TestRun_ConformingAgentPassesAllChecks(t *testing.T) {
+	srv := a2aStandIn(t)
+	defer srv.Close()
+
+	agent := store.AgentRow{
+		ChainID:      "sepolia",
+		AgentID:      7,
+		CardJSON:     map[string]any{"name": "stand-in", "url": srv.URL},
+		Capabilities: map[string]any{"streaming": true},
+	}
+	rep := New(nil).Run(context.Background(), agent)
+
+	if !rep.Passed {
+		t.Fatalf("expected pass, got %+v", rep.Checks)
+	}
+	got := statuses(rep)
+	for _, name := range []string{CheckEndpoint, CheckParseError, CheckMethodNotFound, CheckMessageSend, CheckTasksGet, CheckMessageStream} {
+		if got[name] != StatusPass {
+			t.Fatalf("check %s: %s (%+v)", name, got[name], rep.Checks)
+		}
+	}
+}
+
+func TestRun_StreamingSkippedWithoutCapability(t *testing.T) {
+	srv := a2aStandIn(t)
+	defer srv.Close()
+
+	rep := New(nil).Run(context.Background(), store.AgentRow{CardJSON: map[string]any{"url": srv.URL}})
+	if got := statuses(rep)[CheckMessageStream]; got != StatusSkip {
+		t.Fatalf("expected message/stream to be skipped, got %s", got)
+	}
+	if !rep.Passed {
+		t.Fatalf("skips must not fail the report: %+v", rep.Checks)
+	}
+}
+
+func TestRun_NonA2AEndpointFails(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer srv.Close()
+
+	rep := New(nil).Run(context.Background(), store.AgentRow{CardJSON: map[string]any{"url": srv.URL}})
+	if rep.Passed {
+		t.Fatal("expected a plain JSON server to fail conformance")
+	}
+	got := statuses(rep)
+	if got[CheckMessageSend] != StatusFail || got[CheckMethodNotFound] != StatusFail {
+		t.Fatalf("unexpected statuses: %v", got)
+	}
+
+	rep = New(nil).Run(context.Background(), store.AgentRow{CardJSON: map[string]any{"name": "no-url"}})
+	if rep.Passed || statuses(rep)[CheckEndpoint] != StatusFail || statuses(rep)[CheckMessageSend] != StatusSkip {
+		t.Fatalf("missing url should fail endpoint and skip the rest: %+v", rep.Checks)
+	}
+}
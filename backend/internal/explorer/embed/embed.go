@@ -0,0 +1,157 @@
+// Package embed turns agent cards and query text into fixed-size vectors for
+// similarity search, locally and without an embedding service.
+//
+// Vectors are hashed bags of n-grams: words, adjacent word pairs and character
+// trigrams (so "translate" and "translation" overlap) are weighted by ln(1+tf), folded
+// into Dim signed buckets with FNV-1a and L2-normalized, so the dot product of two
+// vectors is their cosine similarity.
+package embed
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// Dim is the length of every vector.
+	Dim = 512
+	// Model names the features; it is stored with each vector so a change here
+	// re-embeds stored agents. Bump it whenever the features or Dim change.
+	Model = "ngram-v1"
+	// MinSimilarity is the cosine below which agents are not considered related.
+	MinSimilarity = 0.1
+)
+
+// Feature weights: whole words carry most of the meaning, word pairs some phrasing
+// and trigrams only smooth over inflection.
+const (
+	wordWeight    = 1.0
+	bigramWeight  = 0.5
+	trigramWeight = 0.25
+)
+
+var stopwords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true, "be": true, "by": true,
+	"for": true, "from": true, "in": true, "is": true, "it": true, "of": true, "on": true, "or": true,
+	"that": true, "the": true, "this": true, "to": true, "with": true, "you": true, "your": true,
+}
+
+// Card embeds the parts of an A2A card or registration file that describe what the
+// agent does: its name, description and its skills' names, descriptions and tags.
+// Names and tags count double.
+func Card(card map[string]any) []float32 {
+	b := builder{}
+	b.add(str(card["name"]), 2)
+	b.add(str(card["description"]), 1)
+	if skills, ok := card["skills"].([]any); ok {
+		for _, it := range skills {
+			s, ok := it.(map[string]any)
+			if !ok {
+				continue
+			}
+			b.add(str(s["name"]), 2)
+			b.add(str(s["description"]), 1)
+			if tags, ok := s["tags"].([]any); ok {
+				for _, t := range tags {
+					b.add(str(t), 2)
+				}
+			}
+		}
+	}
+	return b.vector()
+}
+
+// Text embeds free text, such as a search query.
+func Text(s string) []float32 {
+	b := builder{}
+	b.add(s, 1)
+	return b.vector()
+}
+
+// Cosine is the cosine similarity of two vectors, 0 if either is zero.
+func Cosine(a, b []float32) float64 {
+	var dot, na, nb float64
+	for i := range min(len(a), len(b)) {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / math.Sqrt(na*nb)
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+// builder accumulates weighted term frequencies per feature.
+type builder map[string]float64
+
+func (b builder) add(text string, weight float64) {
+	words := tokenize(text)
+	for i, w := range words {
+		b["w:"+w] += weight * wordWeight
+		if i > 0 {
+			b["b:"+words[i-1]+" "+w] += weight * bigramWeight
+		}
+		r := []rune("^" + w + "$")
+		for j := 0; j+3 <= len(r); j++ {
+			b["t:"+string(r[j:j+3])] += weight * trigramWeight
+		}
+	}
+}
+
+func (b builder) vector() []float32 {
+	// fixed feature order, so the float sums and thus vectors are reproducible
+	features := make([]string, 0, len(b))
+	for f := range b {
+		features = append(features, f)
+	}
+	sort.Strings(features)
+	v := make([]float64, Dim)
+	for _, f := range features {
+		tf := b[f]
+		h := fnv.New64a()
+		h.Write([]byte(f))
+		sum := h.Sum64()
+		w := math.Log1p(tf)
+		if sum>>63 == 1 {
+			w = -w
+		}
+		v[sum%Dim] += w
+	}
+	var norm float64
+	for _, x := range v {
+		norm += x * x
+	}
+	out := make([]float32, Dim)
+	if norm == 0 {
+		return out
+	}
+	norm = math.Sqrt(norm)
+	for i, x := range v {
+		out[i] = float32(x / norm)
+	}
+	return out
+}
+
+// tokenize lowercases text and splits it into words of letters and digits, dropping
+// stopwords and single characters.
+func tokenize(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	out := fields[:0]
+	for _, f := range fields {
+		if len([]rune(f)) > 1 && !stopwords[f] {
+			out = append(out, f)
+		}
+	}
+	return out
+}
@@ -0,0 +1,54 @@
+package embed
+
+import (
+	"math"
+	"testing"
+)
+
+func card(name, desc string, skills ...map[string]any) map[string]any {
+	s := make([]any, len(skills))
+	for i, sk := range skills {
+		s[i] = sk
+	}
+	return map[string]any{"name": name, "description": desc, "skills": s}
+}
+
+func skill(name string, tags ...any) map[string]any {
+	return map[string]any{"name": name, "tags": tags}
+}
+
+func TestCardSimilarity(t *testing.T) {
+	translator := card("Polyglot", "Translates documents between languages", skill("Translate text", "translation", "language"))
+	other := card("Babel", "Translation of text into many languages", skill("Translation", "language", "nlp"))
+	weather := card("Skycast", "Weather forecasts for any city", skill("Forecast", "weather"))
+
+	near, far := Cosine(Card(translator), Card(other)), Cosine(Card(translator), Card(weather))
+	if near <= far || near < MinSimilarity {
+		t.Errorf("translator~other = %.3f, translator~weather = %.3f", near, far)
+	}
+	if q := Cosine(Text("translate a document"), Card(translator)); q < MinSimilarity || q <= Cosine(Text("translate a document"), Card(weather)) {
+		t.Errorf("query similarity = %.3f", q)
+	}
+}
+
+func TestVectors(t *testing.T) {
+	v := Card(card("Polyglot", "Translates documents", skill("Translate", "translation")))
+	if len(v) != Dim {
+		t.Fatalf("len = %d", len(v))
+	}
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	if math.Abs(norm-1) > 1e-5 {
+		t.Errorf("norm = %f, want 1", norm)
+	}
+	if c := Cosine(v, Card(card("Polyglot", "Translates documents", skill("Translate", "translation")))); math.Abs(c-1) > 1e-5 {
+		t.Errorf("same card cosine = %f, want 1", c)
+	}
+
+	empty := Text("the of a")
+	if len(empty) != Dim || Cosine(empty, v) != 0 {
+		t.Errorf("stopwords only: len %d, cosine %f", len(empty), Cosine(empty, v))
+	}
+}
@@ -0,0 +1,196 @@
+// Package export writes the agent index in bulk as NDJSON, CSV or Parquet, one row
+// at a time, for the /export/agents endpoint and the export subcommand.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// Formats an export can be written in.
+const (
+	NDJSON  = "ndjson"
+	CSV     = "csv"
+	Parquet = "parquet"
+)
+
+// Formats lists every format, the default first.
+var Formats = []string{NDJSON, CSV, Parquet}
+
+// ContentType is the media type of a format.
+func ContentType(format string) string {
+	switch format {
+	case CSV:
+		return "text/csv; charset=utf-8"
+	case Parquet:
+		return "application/vnd.apache.parquet"
+	}
+	return "application/x-ndjson"
+}
+
+// Writer writes exported agents. Close must be called to complete the output; it
+// does not close the underlying io.Writer.
+type Writer interface {
+	Write(store.ExportRow) error
+	Close() error
+}
+
+// NewWriter returns a Writer for format ("" means NDJSON) writing to w.
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case "", NDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w)}, nil
+	case CSV:
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write(columns)
+	case Parquet:
+		return &parquetWriter{w: parquet.NewGenericWriter[Row](w, parquet.Compression(&parquet.Zstd))}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %q", format)
+}
+
+// Row is an agent flattened for tabular formats: lists are kept as lists in Parquet
+// and joined with ";" in CSV, and the card is reduced to its name and description.
+type Row struct {
+	ChainID              string    `parquet:"chainId"`
+	AgentID              int64     `parquet:"agentId"`
+	RegistryAddr         string    `parquet:"registryAddr"`
+	Domain               string    `parquet:"domain"`
+	AddressCAIP10        string    `parquet:"addressCaip10"`
+	Name                 string    `parquet:"name"`
+	Description          string    `parquet:"description"`
+	TrustModels          []string  `parquet:"trustModels,list"`
+	Skills               []string  `parquet:"skills,list"`
+	Tags                 []string  `parquet:"tags,list"`
+	Capabilities         []string  `parquet:"capabilities,list"`
+	ScoreAvg             *float64  `parquet:"scoreAvg,optional"`
+	ValidationsCnt       int64     `parquet:"validationsCnt"`
+	FeedbacksCnt         int64     `parquet:"feedbacksCnt"`
+	LastSeenAt           time.Time `parquet:"lastSeenAt,timestamp(millisecond)"`
+	RegisteredAt         time.Time `parquet:"registeredAt,timestamp(millisecond)"`
+	Status               string    `parquet:"status"`
+	UptimePct            *float64  `parquet:"uptimePct,optional"`
+	CardHash             string    `parquet:"cardHash"`
+	RegistrationVerified *bool     `parquet:"registrationVerified,optional"`
+}
+
+// columns is the CSV header, in Row order.
+var columns = []string{"chainId", "agentId", "registryAddr", "domain", "addressCaip10", "name", "description",
+	"trustModels", "skills", "tags", "capabilities", "scoreAvg", "validationsCnt", "feedbacksCnt",
+	"lastSeenAt", "registeredAt", "status", "uptimePct", "cardHash", "registrationVerified"}
+
+// Flatten reduces an exported agent to a Row. Skills are listed by name (or id),
+// tags are the distinct lowercased tags of all skills, and capabilities are the keys
+// the card sets; each list is sorted.
+func Flatten(e store.ExportRow) Row {
+	r := Row{
+		ChainID: e.ChainID, AgentID: e.AgentID, RegistryAddr: e.RegistryAddr, Domain: e.Domain,
+		AddressCAIP10: e.AddressCAIP, TrustModels: sorted(e.TrustModels),
+		ScoreAvg: e.ScoreAvg, ValidationsCnt: int64(e.ValidationsCnt), FeedbacksCnt: int64(e.FeedbacksCnt),
+		LastSeenAt: e.LastSeenAt.UTC(), RegisteredAt: e.RegisteredAt.UTC(), Status: e.Status, UptimePct: e.UptimePct,
+		CardHash: e.CardHash, RegistrationVerified: e.RegistrationVerified,
+	}
+	r.Name, _ = e.CardJSON["name"].(string)
+	r.Description, _ = e.CardJSON["description"].(string)
+	tags := map[string]bool{}
+	for _, s := range e.Skills {
+		name, _ := s["name"].(string)
+		if name == "" {
+			name, _ = s["id"].(string)
+		}
+		if name != "" {
+			r.Skills = append(r.Skills, name)
+		}
+		if ts, ok := s["tags"].([]any); ok {
+			for _, t := range ts {
+				if t, ok := t.(string); ok && t != "" {
+					tags[strings.ToLower(t)] = true
+				}
+			}
+		}
+	}
+	sort.Strings(r.Skills)
+	for t := range tags {
+		r.Tags = append(r.Tags, t)
+	}
+	sort.Strings(r.Tags)
+	for k := range e.Capabilities {
+		r.Capabilities = append(r.Capabilities, k)
+	}
+	sort.Strings(r.Capabilities)
+	return r
+}
+
+func sorted(xs []string) []string {
+	out := append([]string(nil), xs...)
+	sort.Strings(out)
+	return out
+}
+
+// ndjsonWriter writes the full agent, card included, one JSON object per line.
+type ndjsonWriter struct{ enc *json.Encoder }
+
+func (w *ndjsonWriter) Write(e store.ExportRow) error { return w.enc.Encode(e) }
+func (w *ndjsonWriter) Close() error                  { return nil }
+
+type csvWriter struct{ w *csv.Writer }
+
+func (w *csvWriter) Write(e store.ExportRow) error {
+	r := Flatten(e)
+	return w.w.Write([]string{
+		r.ChainID, strconv.FormatInt(r.AgentID, 10), r.RegistryAddr, r.Domain, r.AddressCAIP10, r.Name, r.Description,
+		strings.Join(r.TrustModels, ";"), strings.Join(r.Skills, ";"), strings.Join(r.Tags, ";"), strings.Join(r.Capabilities, ";"),
+		optFloat(r.ScoreAvg), strconv.FormatInt(r.ValidationsCnt, 10), strconv.FormatInt(r.FeedbacksCnt, 10),
+		r.LastSeenAt.Format(time.RFC3339), r.RegisteredAt.Format(time.RFC3339), r.Status, optFloat(r.UptimePct),
+		r.CardHash, optBool(r.RegistrationVerified),
+	})
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func optFloat(f *float64) string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*f, 'f', -1, 64)
+}
+
+func optBool(b *bool) string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(*b)
+}
+
+// parquetRowGroup is how many rows are buffered before they are written out as a
+// row group, which bounds the writer's memory.
+const parquetRowGroup = 10000
+
+type parquetWriter struct {
+	w *parquet.GenericWriter[Row]
+	n int
+}
+
+func (w *parquetWriter) Write(e store.ExportRow) error {
+	if _, err := w.w.Write([]Row{Flatten(e)}); err != nil {
+		return err
+	}
+	if w.n++; w.n%parquetRowGroup == 0 {
+		return w.w.Flush()
+	}
+	return nil
+}
+
+func (w *parquetWriter) Close() error { return w.w.Close() }
@@ -0,0 +1,131 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+func rows() []store.ExportRow {
+	score, verified := 87.5, true
+	seen := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return []store.ExportRow{
+		{
+			AgentRow: store.AgentRow{
+				ChainID: "sepolia", AgentID: 1, Domain: "a.example", AddressCAIP: "eip155:11155111:0xabc",
+				CardJSON:    map[string]any{"name": "Polyglot, Inc.", "description": "Says \"hello\"\nin many languages"},
+				TrustModels: []string{"tee", "reputation"},
+				Skills: []map[string]any{
+					{"name": "Translate", "tags": []any{"NLP", "translation"}},
+					{"id": "summarize", "tags": []any{"nlp"}},
+				},
+				Capabilities: map[string]any{"streaming": true, "pushNotifications": false},
+				ScoreAvg:     &score, FeedbacksCnt: 3, LastSeenAt: seen, RegisteredAt: seen.Add(-time.Hour),
+			},
+			CardHash: "ab12", RegistrationVerified: &verified,
+		},
+		{
+			AgentRow: store.AgentRow{ChainID: "base", AgentID: 2, Domain: "b.example", LastSeenAt: seen, RegisteredAt: seen},
+			CardHash: "cd34",
+		},
+	}
+}
+
+func write(t *testing.T, format string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := NewWriter(format, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range rows() {
+		if err := w.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFlatten(t *testing.T) {
+	r := Flatten(rows()[0])
+	if r.Name != "Polyglot, Inc." || strings.Join(r.Skills, ",") != "Translate,summarize" ||
+		strings.Join(r.Tags, ",") != "nlp,translation" || strings.Join(r.TrustModels, ",") != "reputation,tee" ||
+		strings.Join(r.Capabilities, ",") != "pushNotifications,streaming" {
+		t.Errorf("Flatten = %+v", r)
+	}
+}
+
+func TestNDJSON(t *testing.T) {
+	sc := bufio.NewScanner(bytes.NewReader(write(t, NDJSON)))
+	var got []map[string]any
+	for sc.Scan() {
+		var m map[string]any
+		if err := json.Unmarshal(sc.Bytes(), &m); err != nil {
+			t.Fatalf("line %q: %v", sc.Text(), err)
+		}
+		got = append(got, m)
+	}
+	if len(got) != 2 || got[0]["cardHash"] != "ab12" || got[0]["registrationVerified"] != true || got[1]["registrationVerified"] != nil {
+		t.Errorf("rows = %v", got)
+	}
+	if card, _ := got[0]["card"].(map[string]any); card["name"] != "Polyglot, Inc." {
+		t.Errorf("card = %v", got[0]["card"])
+	}
+}
+
+func TestCSV(t *testing.T) {
+	recs, err := csv.NewReader(bytes.NewReader(write(t, CSV))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 3 || strings.Join(recs[0], ",") != strings.Join(columns, ",") {
+		t.Fatalf("records = %q", recs)
+	}
+	row := map[string]string{}
+	for i, c := range columns {
+		row[c] = recs[1][i]
+	}
+	want := map[string]string{
+		"name": "Polyglot, Inc.", "description": "Says \"hello\"\nin many languages", "tags": "nlp;translation",
+		"scoreAvg": "87.5", "lastSeenAt": "2026-03-01T12:00:00Z", "registrationVerified": "true",
+	}
+	for k, v := range want {
+		if row[k] != v {
+			t.Errorf("%s = %q, want %q", k, row[k], v)
+		}
+	}
+	if recs[2][11] != "" || recs[2][19] != "" {
+		t.Errorf("missing score and verification should be empty: %q", recs[2])
+	}
+}
+
+func TestParquet(t *testing.T) {
+	b := write(t, Parquet)
+	got, err := parquet.Read[Row](bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("read %d rows", len(got))
+	}
+	if got[0].ChainID != "sepolia" || *got[0].ScoreAvg != 87.5 || strings.Join(got[0].Tags, ",") != "nlp,translation" ||
+		!got[0].LastSeenAt.Equal(rows()[0].LastSeenAt) || got[1].ScoreAvg != nil || got[1].RegistrationVerified != nil {
+		t.Errorf("rows = %+v", got)
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := NewWriter("xlsx", &bytes.Buffer{}); err == nil {
+		t.Error("want an error for xlsx")
+	}
+}
@@ -0,0 +1,290 @@
+package fetcher
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Multicodec and multihash codes we understand.
+const (
+	codecRaw     = 0x55
+	codecDagPB   = 0x70
+	codecJSON    = 0x0200
+	codecDagJSON = 0x0129
+
+	hashIdentity = 0x00
+	hashSHA256   = 0x12
+)
+
+var (
+	errUnverifiable = errors.New("cid cannot be verified locally")
+	errCIDMismatch  = errors.New("content does not match cid")
+)
+
+// CID is a decoded IPFS content identifier.
+type CID struct {
+	Version  uint64
+	Codec    uint64
+	HashCode uint64
+	Digest   []byte
+}
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// ParseCID decodes a CIDv0 (base58btc "Qm...") or a CIDv1 in base32, base58btc or base16.
+func ParseCID(s string) (CID, error) {
+	s = strings.TrimSpace(s)
+	if len(s) == 46 && strings.HasPrefix(s, "Qm") {
+		mh, err := decodeBase58(s)
+		if err != nil {
+			return CID{}, err
+		}
+		code, digest, err := decodeMultihash(mh)
+		if err != nil {
+			return CID{}, err
+		}
+		return CID{Version: 0, Codec: codecDagPB, HashCode: code, Digest: digest}, nil
+	}
+	if len(s) < 2 {
+		return CID{}, fmt.Errorf("cid too short")
+	}
+
+	var raw []byte
+	var err error
+	switch s[0] {
+	case 'b':
+		raw, err = b32.DecodeString(strings.ToUpper(s[1:]))
+	case 'B':
+		raw, err = b32.DecodeString(s[1:])
+	case 'z':
+		raw, err = decodeBase58(s[1:])
+	case 'f', 'F':
+		raw, err = hex.DecodeString(s[1:])
+	default:
+		return CID{}, fmt.Errorf("unsupported multibase prefix %q", s[0])
+	}
+	if err != nil {
+		return CID{}, fmt.Errorf("cid multibase: %w", err)
+	}
+
+	version, n := uvarint(raw)
+	if n <= 0 || version != 1 {
+		return CID{}, fmt.Errorf("unsupported cid version")
+	}
+	raw = raw[n:]
+	codec, n := uvarint(raw)
+	if n <= 0 {
+		return CID{}, fmt.Errorf("cid codec: bad varint")
+	}
+	code, digest, err := decodeMultihash(raw[n:])
+	if err != nil {
+		return CID{}, err
+	}
+	return CID{Version: 1, Codec: codec, HashCode: code, Digest: digest}, nil
+}
+
+// String renders the CID in its canonical form (base58btc for v0, base32 for v1).
+func (c CID) String() string {
+	mh := appendUvarint(nil, c.HashCode)
+	mh = appendUvarint(mh, uint64(len(c.Digest)))
+	mh = append(mh, c.Digest...)
+	if c.Version == 0 {
+		return encodeBase58(mh)
+	}
+	raw := appendUvarint(nil, 1)
+	raw = appendUvarint(raw, c.Codec)
+	raw = append(raw, mh...)
+	return "b" + strings.ToLower(b32.EncodeToString(raw))
+}
+
+// Verifiable reports whether Verify can check blocks against the CID's hash.
+func (c CID) Verifiable() bool {
+	return c.HashCode == hashSHA256 || c.HashCode == hashIdentity
+}
+
+// Verify hashes a raw block and compares it with the CID's digest.
+func (c CID) Verify(block []byte) error {
+	switch c.HashCode {
+	case hashSHA256:
+		sum := sha256.Sum256(block)
+		if !bytes.Equal(sum[:], c.Digest) {
+			return errCIDMismatch
+		}
+	case hashIdentity:
+		if !bytes.Equal(block, c.Digest) {
+			return errCIDMismatch
+		}
+	default:
+		return fmt.Errorf("%w: multihash 0x%x", errUnverifiable, c.HashCode)
+	}
+	return nil
+}
+
+// Content extracts the file bytes from a verified block. dag-pb is only understood
+// for single-block UnixFS files; larger DAGs would need the linked blocks as well.
+func (c CID) Content(block []byte) ([]byte, error) {
+	switch c.Codec {
+	case codecRaw, codecJSON, codecDagJSON:
+		return block, nil
+	case codecDagPB:
+		return unixfsFileData(block)
+	default:
+		return nil, fmt.Errorf("%w: codec 0x%x", errUnverifiable, c.Codec)
+	}
+}
+
+func decodeMultihash(mh []byte) (uint64, []byte, error) {
+	code, n := uvarint(mh)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("multihash code: bad varint")
+	}
+	mh = mh[n:]
+	size, n := uvarint(mh)
+	if n <= 0 || uint64(len(mh)-n) != size {
+		return 0, nil, fmt.Errorf("multihash length mismatch")
+	}
+	return code, mh[n:], nil
+}
+
+// unixfsFileData decodes a dag-pb node and returns the inline UnixFS file data.
+func unixfsFileData(block []byte) ([]byte, error) {
+	var data []byte
+	links := 0
+	err := walkProto(block, func(field uint64, wire uint64, val []byte, _ uint64) {
+		switch {
+		case field == 1 && wire == 2:
+			data = val
+		case field == 2 && wire == 2:
+			links++
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dag-pb: %w", err)
+	}
+	if links > 0 {
+		return nil, fmt.Errorf("%w: multi-block unixfs file", errUnverifiable)
+	}
+
+	var typ uint64
+	var content []byte
+	err = walkProto(data, func(field uint64, wire uint64, val []byte, num uint64) {
+		switch {
+		case field == 1 && wire == 0:
+			typ = num
+		case field == 2 && wire == 2:
+			content = val
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unixfs: %w", err)
+	}
+	// 0 = Raw, 2 = File
+	if typ != 0 && typ != 2 {
+		return nil, fmt.Errorf("unixfs node is not a file (type %d)", typ)
+	}
+	return content, nil
+}
+
+// walkProto iterates the fields of a protobuf message, supporting varint and
+// length-delimited wire types only (all dag-pb and UnixFS need).
+func walkProto(b []byte, fn func(field, wire uint64, val []byte, num uint64)) error {
+	for len(b) > 0 {
+		key, n := uvarint(b)
+		if n <= 0 {
+			return fmt.Errorf("bad field key")
+		}
+		b = b[n:]
+		field, wire := key>>3, key&7
+		switch wire {
+		case 0:
+			v, n := uvarint(b)
+			if n <= 0 {
+				return fmt.Errorf("bad varint")
+			}
+			b = b[n:]
+			fn(field, wire, nil, v)
+		case 2:
+			l, n := uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < l {
+				return fmt.Errorf("bad length")
+			}
+			fn(field, wire, b[n:n+int(l)], 0)
+			b = b[n+int(l):]
+		default:
+			return fmt.Errorf("unsupported wire type %d", wire)
+		}
+	}
+	return nil
+}
+
+func uvarint(b []byte) (uint64, int) {
+	var x uint64
+	var s uint
+	for i, c := range b {
+		if i == 10 {
+			return 0, -1
+		}
+		if c < 0x80 {
+			return x | uint64(c)<<s, i + 1
+		}
+		x |= uint64(c&0x7f) << s
+		s += 7
+	}
+	return 0, 0
+}
+
+func appendUvarint(b []byte, x uint64) []byte {
+	for x >= 0x80 {
+		b = append(b, byte(x)|0x80)
+		x >>= 7
+	}
+	return append(b, byte(x))
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+func decodeBase58(s string) ([]byte, error) {
+	n := new(big.Int)
+	radix := big.NewInt(58)
+	for _, r := range s {
+		i := strings.IndexRune(base58Alphabet, r)
+		if i < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", r)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(i)))
+	}
+	out := n.Bytes()
+	zeros := 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	return append(make([]byte, zeros), out...), nil
+}
+
+func encodeBase58(b []byte) string {
+	n := new(big.Int).SetBytes(b)
+	radix := big.NewInt(58)
+	mod := new(big.Int)
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for _, c := range b {
+		if c != 0 {
+			break
+		}
+		out = append(out, '1')
+	}
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
@@ -0,0 +1,322 @@
+// Package fetcher resolves the URIs found in ERC-8004 registrations (http(s),
+// ipfs://, ar://, data: and bare CIDs) to their raw bytes.
+package fetcher
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/metrics"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Config controls how content-addressed URIs are fetched. Gateways are tried in
+// the listed order; with the "race" strategy they are queried concurrently and the
+// first valid answer wins.
+type Config struct {
+	IPFSGateways    []string      `yaml:"ipfsGateways"`
+	ArweaveGateways []string      `yaml:"arweaveGateways"`
+	Strategy        string        `yaml:"strategy"` // race | fallback
+	VerifyCID       bool          `yaml:"verifyCid"`
+	Timeout         time.Duration `yaml:"timeout"`
+	MaxBytes        int64         `yaml:"maxBytes"`
+}
+
+// Gateway strategies
+const (
+	StrategyRace     = "race"
+	StrategyFallback = "fallback"
+)
+
+// DefaultConfig mirrors the previous hard-coded behaviour (ipfs.io) plus a couple
+// of public fallbacks.
+func DefaultConfig() Config {
+	return Config{
+		IPFSGateways:    []string{"https://ipfs.io", "https://dweb.link", "https://w3s.link"},
+		ArweaveGateways: []string{"https://arweave.net"},
+		Strategy:        StrategyFallback,
+		VerifyCID:       true,
+		Timeout:         15 * time.Second,
+		MaxBytes:        1 << 20,
+	}
+}
+
+// Result is what a resolver returns for a URI. Verified is set when the bytes were
+// checked locally against the URI itself (CID hash match or inline data: payload).
+type Result struct {
+	Body     []byte
+	Source   string
+	Verified bool
+}
+
+// Resolver fetches the content behind a URI.
+type Resolver interface {
+	Fetch(ctx context.Context, uri string) (Result, error)
+}
+
+// HandlerFunc resolves URIs of a single scheme.
+type HandlerFunc func(ctx context.Context, uri string) (Result, error)
+
+// URIResolver dispatches to a handler per URI scheme. Bare CIDs are treated as ipfs.
+type URIResolver struct {
+	cfg      Config
+	client   *http.Client
+	handlers map[string]HandlerFunc
+}
+
+// New builds a resolver with the built-in http, https, ipfs, ar and data handlers.
+// Zero-valued config fields fall back to DefaultConfig.
+func New(cfg Config, client *http.Client) *URIResolver {
+	def := DefaultConfig()
+	if len(cfg.IPFSGateways) == 0 {
+		cfg.IPFSGateways = def.IPFSGateways
+	}
+	if len(cfg.ArweaveGateways) == 0 {
+		cfg.ArweaveGateways = def.ArweaveGateways
+	}
+	if cfg.Strategy == "" {
+		cfg.Strategy = def.Strategy
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.MaxBytes <= 0 {
+		cfg.MaxBytes = def.MaxBytes
+	}
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout}
+	}
+	r := &URIResolver{cfg: cfg, client: client, handlers: map[string]HandlerFunc{}}
+	r.Register("http", r.fetchHTTP)
+	r.Register("https", r.fetchHTTP)
+	r.Register("ipfs", r.fetchIPFS)
+	r.Register("ar", r.fetchArweave)
+	r.Register("data", fetchData)
+	return r
+}
+
+// Register installs (or replaces) the handler for a scheme.
+func (r *URIResolver) Register(scheme string, h HandlerFunc) {
+	r.handlers[strings.ToLower(scheme)] = h
+}
+
+func (r *URIResolver) Fetch(ctx context.Context, uri string) (Result, error) {
+	scheme, res, err := r.fetch(ctx, uri)
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	metrics.URIFetches.WithLabelValues(scheme, outcome).Inc()
+	return res, err
+}
+
+// fetch resolves uri and also returns the scheme it is counted under: a registered
+// one, or "unsupported".
+func (r *URIResolver) fetch(ctx context.Context, uri string) (string, Result, error) {
+	u := strings.TrimSpace(uri)
+	if u == "" {
+		return "unsupported", Result{}, fmt.Errorf("empty uri")
+	}
+	scheme := ""
+	if i := strings.Index(u, ":"); i > 0 {
+		scheme = strings.ToLower(u[:i])
+	}
+	if h, ok := r.handlers[scheme]; ok {
+		res, err := h(ctx, u)
+		return scheme, res, err
+	}
+	// bare CID or /ipfs/CID path
+	if p := strings.TrimPrefix(u, "/ipfs/"); isCIDPath(p) {
+		res, err := r.fetchIPFS(ctx, "ipfs://"+p)
+		return "ipfs", res, err
+	}
+	return "unsupported", Result{}, fmt.Errorf("unsupported uri scheme %q", scheme)
+}
+
+func isCIDPath(p string) bool {
+	root, _, _ := strings.Cut(p, "/")
+	_, err := ParseCID(root)
+	return err == nil
+}
+
+func (r *URIResolver) fetchHTTP(ctx context.Context, uri string) (Result, error) {
+	if _, err := url.Parse(uri); err != nil {
+		return Result{}, fmt.Errorf("invalid uri: %w", err)
+	}
+	body, err := r.get(ctx, uri, "")
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Body: body, Source: uri}, nil
+}
+
+func (r *URIResolver) get(ctx context.Context, uri string, accept string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := r.client.Do(req) // #nosec G107
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("GET %s: status %d", uri, resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, r.cfg.MaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(body)) > r.cfg.MaxBytes {
+		return nil, fmt.Errorf("GET %s: body exceeds %d bytes", uri, r.cfg.MaxBytes)
+	}
+	return body, nil
+}
+
+// fetchData decodes an RFC 2397 data: URI without any network access.
+func fetchData(_ context.Context, uri string) (Result, error) {
+	meta, payload, ok := strings.Cut(strings.TrimPrefix(uri, "data:"), ",")
+	if !ok {
+		return Result{}, fmt.Errorf("data uri: missing ','")
+	}
+	var body []byte
+	if strings.HasSuffix(strings.ToLower(meta), ";base64") {
+		b, err := base64.StdEncoding.DecodeString(payload)
+		if err != nil {
+			// some registries emit unpadded or url-safe payloads
+			if b, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(payload, "=")); err != nil {
+				return Result{}, fmt.Errorf("data uri: %w", err)
+			}
+		}
+		body = b
+	} else {
+		s, err := url.PathUnescape(payload)
+		if err != nil {
+			return Result{}, fmt.Errorf("data uri: %w", err)
+		}
+		body = []byte(s)
+	}
+	return Result{Body: body, Source: "data:", Verified: true}, nil
+}
+
+// fetchArweave fetches ar://TXID[/path] through the configured gateways. Arweave
+// content is not verified locally.
+func (r *URIResolver) fetchArweave(ctx context.Context, uri string) (Result, error) {
+	id := strings.TrimPrefix(uri, "ar://")
+	if id == "" {
+		return Result{}, fmt.Errorf("ar uri: missing transaction id")
+	}
+	return r.viaGateways(ctx, r.cfg.ArweaveGateways, func(ctx context.Context, gw string) (Result, error) {
+		src := strings.TrimRight(gw, "/") + "/" + id
+		body, err := r.get(ctx, src, "")
+		return Result{Body: body, Source: src}, err
+	})
+}
+
+// fetchIPFS fetches ipfs://CID[/path]. For a bare CID with VerifyCID set it asks the
+// gateway for the raw block (trustless gateway format) and checks it against the CID
+// hash; a gateway serving mismatching bytes, or refusing the raw block of a CID
+// whose hash we can check, is treated as failed. Paths and DAGs spanning several
+// blocks can't be checked locally and are fetched unverified.
+func (r *URIResolver) fetchIPFS(ctx context.Context, uri string) (Result, error) {
+	p := strings.TrimPrefix(uri, "ipfs://")
+	p = strings.TrimPrefix(p, "ipfs/")
+	root, rest, _ := strings.Cut(p, "/")
+	cid, err := ParseCID(root)
+	if err != nil {
+		return Result{}, fmt.Errorf("ipfs uri: %w", err)
+	}
+
+	verify := r.cfg.VerifyCID && rest == ""
+	return r.viaGateways(ctx, r.cfg.IPFSGateways, func(ctx context.Context, gw string) (Result, error) {
+		base := strings.TrimRight(gw, "/") + "/ipfs/" + p
+		if verify {
+			block, err := r.get(ctx, base+"?format=raw", "application/vnd.ipld.raw")
+			if err != nil {
+				// a gateway that won't serve the block of a CID we could check must not
+				// get to serve unchecked bytes instead
+				if cid.Verifiable() {
+					return Result{}, fmt.Errorf("%s: raw block: %w", gw, err)
+				}
+			} else if err := cid.Verify(block); err != nil {
+				if errors.Is(err, errCIDMismatch) {
+					return Result{}, fmt.Errorf("%s: %w", gw, err)
+				}
+			} else if content, err := cid.Content(block); err == nil {
+				return Result{Body: content, Source: base, Verified: true}, nil
+			}
+		}
+		body, err := r.get(ctx, base, "")
+		return Result{Body: body, Source: base}, err
+	})
+}
+
+// viaGateways runs fetch against each gateway, either one after another or all at
+// once, returning the first success or the combined errors.
+func (r *URIResolver) viaGateways(ctx context.Context, gateways []string, fetch func(ctx context.Context, gw string) (Result, error)) (Result, error) {
+	if len(gateways) == 0 {
+		return Result{}, fmt.Errorf("no gateways configured")
+	}
+	if r.cfg.Strategy != StrategyRace {
+		var errs []error
+		for _, gw := range gateways {
+			res, err := fetch(ctx, gw)
+			if err == nil {
+				return res, nil
+			}
+			errs = append(errs, err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+		return Result{}, errors.Join(errs...)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	type answer struct {
+		res Result
+		err error
+	}
+	ch := make(chan answer, len(gateways))
+	for _, gw := range gateways {
+		go func(gw string) {
+			res, err := fetch(ctx, gw)
+			ch <- answer{res, err}
+		}(gw)
+	}
+	var errs []error
+	for range gateways {
+		a := <-ch
+		if a.err == nil {
+			return a.res, nil
+		}
+		errs = append(errs, a.err)
+	}
+	return Result{}, errors.Join(errs...)
+}
+
+// CardURL builds the well-known A2A agent card URL for an indexed domain, which may
+// be a bare host, a base URL or already the full card URL.
+func CardURL(domain string) string {
+	d := strings.TrimSpace(domain)
+	if !strings.HasPrefix(d, "http://") && !strings.HasPrefix(d, "https://") {
+		return fmt.Sprintf("http://%s/.well-known/agent-card.json", d)
+	}
+	if !strings.Contains(d, "/.well-known/agent-card.json") {
+		return strings.TrimRight(d, "/") + "/.well-known/agent-card.json"
+	}
+	return d
+}
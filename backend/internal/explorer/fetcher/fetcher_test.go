@@ -0,0 +1,190 @@
+package fetcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func rawCID(content []byte) CID {
+	sum := sha256.Sum256(content)
+	return CID{Version: 1, Codec: codecRaw, HashCode: hashSHA256, Digest: sum[:]}
+}
+
+func TestParseCID_RoundTrips(t *testing.T) {
+	v1 := rawCID([]byte(`{"name":"agent"}`))
+	got, err := ParseCID(v1.String())
+	if err != nil {
+		t.Fatalf("parse v1: %v", err)
+	}
+	if got.Codec != codecRaw || got.HashCode != hashSHA256 || string(got.Digest) != string(v1.Digest) {
+		t.Fatalf("v1 mismatch: %+v", got)
+	}
+
+	// well-known CIDv0 of the empty unixfs directory
+	v0, err := ParseCID("QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn")
+	if err != nil {
+		t.Fatalf("parse v0: %v", err)
+	}
+	if v0.Version != 0 || v0.Codec != codecDagPB || len(v0.Digest) != 32 {
+		t.Fatalf("v0 mismatch: %+v", v0)
+	}
+	if v0.String() != "QmUNLLsPACCz1vLxQVkXqqLX5R1X345qqfHbsf67hvA3Nn" {
+		t.Fatalf("v0 string mismatch: %s", v0.String())
+	}
+
+	if _, err := ParseCID("not-a-cid"); err == nil {
+		t.Fatal("expected error for garbage cid")
+	}
+}
+
+func TestFetch_DataURIsDecodeWithoutNetwork(t *testing.T) {
+	r := New(Config{}, &http.Client{Transport: roundTripFunc(func(*http.Request) (*http.Response, error) {
+		t.Fatal("data: uri must not hit the network")
+		return nil, nil
+	})})
+
+	payload := `{"name":"inline"}`
+	b64 := "data:application/json;base64," + base64.StdEncoding.EncodeToString([]byte(payload))
+	res, err := r.Fetch(context.Background(), b64)
+	if err != nil || string(res.Body) != payload || !res.Verified {
+		t.Fatalf("base64 data uri: %q %v %+v", res.Body, err, res)
+	}
+
+	res, err = r.Fetch(context.Background(), "data:application/json,%7B%22name%22%3A%22inline%22%7D")
+	if err != nil || string(res.Body) != payload {
+		t.Fatalf("percent-encoded data uri: %q %v", res.Body, err)
+	}
+}
+
+func TestFetch_IPFSVerifiesAndFallsBackPastTamperingGateway(t *testing.T) {
+	content := []byte(`{"name":"pinned"}`)
+	cid := rawCID(content)
+
+	var badHits, goodHits int32
+	bad := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&badHits, 1)
+		_, _ = w.Write([]byte(`{"name":"tampered"}`))
+	}))
+	defer bad.Close()
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ipfs/"+cid.String() || r.URL.Query().Get("format") != "raw" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&goodHits, 1)
+		_, _ = w.Write(content)
+	}))
+	defer good.Close()
+
+	r := New(Config{IPFSGateways: []string{bad.URL, good.URL}, Strategy: StrategyFallback, VerifyCID: true}, nil)
+	res, err := r.Fetch(context.Background(), "ipfs://"+cid.String())
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if string(res.Body) != string(content) || !res.Verified || !strings.HasPrefix(res.Source, good.URL) {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if atomic.LoadInt32(&badHits) != 1 || atomic.LoadInt32(&goodHits) != 1 {
+		t.Fatalf("hits bad=%d good=%d", badHits, goodHits)
+	}
+
+	// bare CIDs resolve through the same path
+	if res, err := r.Fetch(context.Background(), cid.String()); err != nil || !res.Verified {
+		t.Fatalf("bare cid: %+v %v", res, err)
+	}
+}
+
+func TestFetch_IPFSGatewayRefusingRawBlockFails(t *testing.T) {
+	content := []byte(`{"name":"pinned"}`)
+	cid := rawCID(content)
+
+	// refuses the trustless format, serves tampered bytes otherwise
+	evasive := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("format") == "raw" {
+			w.WriteHeader(http.StatusNotAcceptable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"tampered"}`))
+	}))
+	defer evasive.Close()
+	good := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// answers after the evasive gateway, so a race would pick its bytes
+		time.Sleep(50 * time.Millisecond)
+		_, _ = w.Write(content)
+	}))
+	defer good.Close()
+
+	for _, strategy := range []string{StrategyFallback, StrategyRace} {
+		r := New(Config{IPFSGateways: []string{evasive.URL}, Strategy: strategy, VerifyCID: true}, nil)
+		if res, err := r.Fetch(context.Background(), "ipfs://"+cid.String()); err == nil {
+			t.Fatalf("%s: unverified bytes accepted: %+v", strategy, res)
+		}
+
+		r = New(Config{IPFSGateways: []string{evasive.URL, good.URL}, Strategy: strategy, VerifyCID: true}, nil)
+		res, err := r.Fetch(context.Background(), "ipfs://"+cid.String())
+		if err != nil || string(res.Body) != string(content) || !res.Verified || !strings.HasPrefix(res.Source, good.URL) {
+			t.Fatalf("%s: unexpected result: %+v %v", strategy, res, err)
+		}
+	}
+}
+
+func TestFetch_IPFSUnwrapsSingleBlockUnixFS(t *testing.T) {
+	content := []byte(`{"name":"unixfs"}`)
+	// UnixFS Data{Type: File, Data: content} wrapped in PBNode{Data: ...}
+	unixfs := append([]byte{0x08, 0x02, 0x12}, appendUvarint(nil, uint64(len(content)))...)
+	unixfs = append(unixfs, content...)
+	node := append([]byte{0x0a}, appendUvarint(nil, uint64(len(unixfs)))...)
+	node = append(node, unixfs...)
+	sum := sha256.Sum256(node)
+	cid := CID{Version: 0, Codec: codecDagPB, HashCode: hashSHA256, Digest: sum[:]}
+
+	gw := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(node)
+	}))
+	defer gw.Close()
+
+	r := New(Config{IPFSGateways: []string{gw.URL}, VerifyCID: true}, nil)
+	res, err := r.Fetch(context.Background(), "ipfs://"+cid.String())
+	if err != nil || string(res.Body) != string(content) || !res.Verified {
+		t.Fatalf("unixfs: %q %v %+v", res.Body, err, res)
+	}
+}
+
+func TestFetch_RaceReturnsFastestGateway(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+		_, _ = w.Write([]byte(`{"from":"slow"}`))
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"from":"fast"}`))
+	}))
+	defer fast.Close()
+
+	r := New(Config{ArweaveGateways: []string{slow.URL, fast.URL}, Strategy: StrategyRace}, nil)
+	start := time.Now()
+	res, err := r.Fetch(context.Background(), "ar://tx123")
+	if err != nil {
+		t.Fatalf("race: %v", err)
+	}
+	if string(res.Body) != `{"from":"fast"}` || res.Verified {
+		t.Fatalf("unexpected result: %+v", res)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("race waited for the slow gateway")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
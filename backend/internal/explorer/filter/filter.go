@@ -0,0 +1,440 @@
+// Package filter parses the agent search filter language into an AST.
+//
+// A filter is a boolean expression over field:value terms:
+//
+//	tag:finance AND (trust:tee OR trust:reputation) -network:sepolia
+//
+// Terms next to each other are ANDed; AND binds tighter than OR; NOT or a leading
+// "-" negates; parentheses group; values with spaces are double-quoted
+// (skill:"web search"). Keywords are case-insensitive. The store compiles the AST to
+// parameterized SQL and in-memory matchers evaluate it with Eval, so both agree on
+// what a filter means.
+package filter
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Fields a term can filter on, named like the SearchParams they extend.
+const (
+	Network    = "network"
+	TrustModel = "trustModel"
+	Skill      = "skill"
+	Tag        = "tag"
+	Capability = "capability"
+	Endpoint   = "endpoint"
+	Status     = "status"
+	Tool       = "tool"
+)
+
+// Fields lists every field, in documentation order.
+var Fields = []string{Network, TrustModel, Skill, Tag, Capability, Endpoint, Status, Tool}
+
+// aliases maps the spellings accepted in filter text to fields.
+var aliases = map[string]string{
+	"network": Network, "chain": Network,
+	"trustmodel": TrustModel, "trust": TrustModel,
+	"skill":      Skill,
+	"tag":        Tag,
+	"capability": Capability, "cap": Capability,
+	"endpoint": Endpoint,
+	"status":   Status,
+	"tool":     Tool,
+}
+
+// Limits on filter text, so one request can't build an arbitrarily large query.
+// MaxTerms bounds a whole search: callers combining several expressions (repeated
+// query parameters and filter text) check their total with Terms.
+const (
+	MaxLength = 1000
+	MaxTerms  = 50
+	maxDepth  = 20
+)
+
+// Expr is a node of the filter AST: Term, Not, And or Or.
+type Expr interface {
+	String() string
+}
+
+// Term matches agents whose Field has Value, with the field's search semantics.
+type Term struct {
+	Field string
+	Value string
+}
+
+// Not negates X.
+type Not struct{ X Expr }
+
+// And matches when every operand does.
+type And []Expr
+
+// Or matches when any operand does.
+type Or []Expr
+
+func (t Term) String() string {
+	v := t.Value
+	if v == "" || strings.ContainsAny(v, " \t\"()") {
+		v = strconv.Quote(v)
+	}
+	return t.Field + ":" + v
+}
+
+func (n Not) String() string { return "-" + group(n.X) }
+
+func (a And) String() string { return join(a, " AND ") }
+
+func (o Or) String() string { return join(o, " OR ") }
+
+func join(xs []Expr, sep string) string {
+	parts := make([]string, len(xs))
+	for i, x := range xs {
+		parts[i] = group(x)
+	}
+	return strings.Join(parts, sep)
+}
+
+func group(x Expr) string {
+	switch x.(type) {
+	case And, Or:
+		return "(" + x.String() + ")"
+	}
+	return x.String()
+}
+
+// Eval reports whether match holds for the expression, calling it once per term.
+func Eval(x Expr, match func(Term) bool) bool {
+	switch x := x.(type) {
+	case Term:
+		return match(x)
+	case Not:
+		return !Eval(x.X, match)
+	case And:
+		for _, y := range x {
+			if !Eval(y, match) {
+				return false
+			}
+		}
+		return true
+	case Or:
+		for _, y := range x {
+			if Eval(y, match) {
+				return true
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// FieldsOf returns the distinct fields x filters on, sorted.
+func FieldsOf(x Expr) []string {
+	seen := map[string]bool{}
+	var walk func(Expr)
+	walk = func(x Expr) {
+		switch x := x.(type) {
+		case Term:
+			seen[x.Field] = true
+		case Not:
+			walk(x.X)
+		case And:
+			for _, y := range x {
+				walk(y)
+			}
+		case Or:
+			for _, y := range x {
+				walk(y)
+			}
+		}
+	}
+	walk(x)
+	out := make([]string, 0, len(seen))
+	for f := range seen {
+		out = append(out, f)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Terms returns the number of terms in the expressions.
+func Terms(xs ...Expr) int {
+	n := 0
+	for _, x := range xs {
+		switch x := x.(type) {
+		case Term:
+			n++
+		case Not:
+			n += Terms(x.X)
+		case And:
+			n += Terms(x...)
+		case Or:
+			n += Terms(x...)
+		}
+	}
+	return n
+}
+
+// Field resolves a field name or alias, case-insensitively.
+func Field(name string) (string, bool) {
+	f, ok := aliases[strings.ToLower(name)]
+	return f, ok
+}
+
+// Values combines the values of a repeated query parameter into one expression: a
+// value starting with "-" is negated, and the terms are ORed when matchAny is set and
+// ANDed otherwise. It returns nil when there are no non-empty values.
+func Values(field string, values []string, matchAny bool) Expr {
+	var xs []Expr
+	for _, v := range values {
+		neg := strings.HasPrefix(v, "-")
+		v = strings.TrimSpace(strings.TrimPrefix(v, "-"))
+		if v == "" {
+			continue
+		}
+		var x Expr = Term{Field: field, Value: v}
+		if neg {
+			x = Not{x}
+		}
+		xs = append(xs, x)
+	}
+	switch {
+	case len(xs) == 0:
+		return nil
+	case len(xs) == 1:
+		return xs[0]
+	case matchAny:
+		return Or(xs)
+	}
+	return And(xs)
+}
+
+// Error is a syntax error at a byte offset of the filter text.
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string { return fmt.Sprintf("filter: %s at offset %d", e.Msg, e.Pos) }
+
+// Parse parses filter text. Empty text parses to nil.
+func Parse(s string) (Expr, error) {
+	if len(s) > MaxLength {
+		return nil, &Error{Pos: MaxLength, Msg: fmt.Sprintf("longer than %d bytes", MaxLength)}
+	}
+	toks, err := lex(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(toks) == 1 { // just EOF
+		return nil, nil
+	}
+	p := &parser{toks: toks}
+	x, err := p.or(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s", t)}
+	}
+	return x, nil
+}
+
+type tokKind int
+
+const (
+	tokEOF tokKind = iota
+	tokTerm
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokKind
+	pos  int
+	term Term
+	text string
+}
+
+func (t token) String() string {
+	if t.kind == tokEOF {
+		return "end of filter"
+	}
+	return strconv.Quote(t.text)
+}
+
+func lex(s string) ([]token, error) {
+	var toks []token
+	terms := 0
+	i := 0
+	for {
+		for i < len(s) && (s[i] == ' ' || s[i] == '\t' || s[i] == '\n' || s[i] == '\r') {
+			i++
+		}
+		if i == len(s) {
+			return append(toks, token{kind: tokEOF, pos: i}), nil
+		}
+		start := i
+		switch s[i] {
+		case '(':
+			toks = append(toks, token{kind: tokLParen, pos: i, text: "("})
+			i++
+			continue
+		case ')':
+			toks = append(toks, token{kind: tokRParen, pos: i, text: ")"})
+			i++
+			continue
+		case '-':
+			toks = append(toks, token{kind: tokNot, pos: i, text: "-"})
+			i++
+			continue
+		case '"':
+			return nil, &Error{Pos: i, Msg: "quoted value without a field"}
+		}
+		for i < len(s) && !strings.ContainsRune(" \t\n\r()\"", rune(s[i])) {
+			i++
+		}
+		word := s[start:i]
+		switch strings.ToUpper(word) {
+		case "AND":
+			toks = append(toks, token{kind: tokAnd, pos: start, text: word})
+			continue
+		case "OR":
+			toks = append(toks, token{kind: tokOr, pos: start, text: word})
+			continue
+		case "NOT":
+			toks = append(toks, token{kind: tokNot, pos: start, text: word})
+			continue
+		}
+		name, value, ok := strings.Cut(word, ":")
+		if !ok {
+			return nil, &Error{Pos: start, Msg: fmt.Sprintf("expected field:value, got %q", word)}
+		}
+		field, known := Field(name)
+		if !known {
+			return nil, &Error{Pos: start, Msg: fmt.Sprintf("unknown field %q", name)}
+		}
+		if value == "" && i < len(s) && s[i] == '"' {
+			end := i + 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return nil, &Error{Pos: i, Msg: "unterminated quoted value"}
+			}
+			v, err := strconv.Unquote(s[i : end+1])
+			if err != nil {
+				return nil, &Error{Pos: i, Msg: "invalid quoted value"}
+			}
+			value = v
+			i = end + 1
+		}
+		if strings.TrimSpace(value) == "" {
+			return nil, &Error{Pos: start, Msg: fmt.Sprintf("empty value for %q", name)}
+		}
+		if terms++; terms > MaxTerms {
+			return nil, &Error{Pos: start, Msg: fmt.Sprintf("more than %d terms", MaxTerms)}
+		}
+		toks = append(toks, token{kind: tokTerm, pos: start, term: Term{Field: field, Value: value}, text: s[start:i]})
+	}
+}
+
+type parser struct {
+	toks []token
+	i    int
+}
+
+func (p *parser) peek() token { return p.toks[p.i] }
+
+func (p *parser) next() token {
+	t := p.toks[p.i]
+	if t.kind != tokEOF {
+		p.i++
+	}
+	return t
+}
+
+// or := and { OR and }
+func (p *parser) or(depth int) (Expr, error) {
+	x, err := p.and(depth)
+	if err != nil {
+		return nil, err
+	}
+	xs := []Expr{x}
+	for p.peek().kind == tokOr {
+		p.next()
+		y, err := p.and(depth)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, y)
+	}
+	if len(xs) == 1 {
+		return x, nil
+	}
+	return Or(xs), nil
+}
+
+// and := unary { [AND] unary }
+func (p *parser) and(depth int) (Expr, error) {
+	x, err := p.unary(depth)
+	if err != nil {
+		return nil, err
+	}
+	xs := []Expr{x}
+	for {
+		switch p.peek().kind {
+		case tokAnd:
+			p.next()
+		case tokTerm, tokNot, tokLParen:
+		default:
+			if len(xs) == 1 {
+				return x, nil
+			}
+			return And(xs), nil
+		}
+		y, err := p.unary(depth)
+		if err != nil {
+			return nil, err
+		}
+		xs = append(xs, y)
+	}
+}
+
+// unary := NOT unary | '(' or ')' | term
+func (p *parser) unary(depth int) (Expr, error) {
+	if depth > maxDepth {
+		return nil, &Error{Pos: p.peek().pos, Msg: fmt.Sprintf("nested deeper than %d", maxDepth)}
+	}
+	t := p.next()
+	switch t.kind {
+	case tokNot:
+		x, err := p.unary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if n, ok := x.(Not); ok {
+			return n.X, nil
+		}
+		return Not{x}, nil
+	case tokLParen:
+		x, err := p.or(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if r := p.next(); r.kind != tokRParen {
+			return nil, &Error{Pos: r.pos, Msg: fmt.Sprintf("expected \")\", got %s", r)}
+		}
+		return x, nil
+	case tokTerm:
+		return t.term, nil
+	}
+	return nil, &Error{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s", t)}
+}
@@ -0,0 +1,102 @@
+package filter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct{ in, want string }{
+		{"", "<nil>"},
+		{"tag:finance", "tag:finance"},
+		{"tag:finance AND (trust:tee OR trust:reputation)", "tag:finance AND (trustModel:tee OR trustModel:reputation)"},
+		{"tag:a tag:b OR tag:c", "(tag:a AND tag:b) OR tag:c"},
+		{"tag:a OR tag:b tag:c", "tag:a OR (tag:b AND tag:c)"},
+		{"-tag:foo", "-tag:foo"},
+		{"not Chain:sepolia and cap:streaming", "-network:sepolia AND capability:streaming"},
+		{"- -tag:x", "tag:x"},
+		{"-(tag:a OR tag:b)", "-(tag:a OR tag:b)"},
+		{`skill:"web search" trustModel:crypto-economic`, `skill:"web search" AND trustModel:crypto-economic`},
+		{"endpoint:did:web", "endpoint:did:web"},
+	}
+	for _, c := range cases {
+		x, err := Parse(c.in)
+		if err != nil {
+			t.Errorf("%q: %v", c.in, err)
+			continue
+		}
+		got := "<nil>"
+		if x != nil {
+			got = x.String()
+		}
+		if got != c.want {
+			t.Errorf("%q parsed as %s, want %s", c.in, got, c.want)
+		}
+		if x != nil {
+			// String output parses back to the same tree
+			y, err := Parse(got)
+			if err != nil || y.String() != got {
+				t.Errorf("%q: round trip gave %v, %v", got, y, err)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"finance":           "expected field:value",
+		"color:red":         `unknown field "color"`,
+		"tag:":              "empty value",
+		"(tag:a":            `expected ")"`,
+		"tag:a)":            `unexpected ")"`,
+		"tag:a OR":          "unexpected end of filter",
+		`tag:"unterminated`: "unterminated",
+		`"quoted"`:          "without a field",
+		strings.Repeat("(", 30) + "tag:a" + strings.Repeat(")", 30): "nested deeper",
+		strings.Repeat("tag:a ", 51):                                "more than 50 terms",
+		strings.Repeat("x", MaxLength+1):                            "longer than",
+	}
+	for in, want := range cases {
+		_, err := Parse(in)
+		var fe *Error
+		if !errors.As(err, &fe) || !strings.Contains(err.Error(), want) {
+			t.Errorf("%.40q: error %v, want one containing %q", in, err, want)
+		}
+	}
+}
+
+func TestEvalAndValues(t *testing.T) {
+	has := map[string]bool{"tag:finance": true, "trustModel:tee": true}
+	match := func(t Term) bool { return has[t.Field+":"+t.Value] }
+
+	for in, want := range map[string]bool{
+		"tag:finance AND (trust:tee OR trust:reputation)": true,
+		"tag:finance -trust:tee":                          false,
+		"tag:web OR -trust:reputation":                    true,
+	} {
+		x, err := Parse(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := Eval(x, match); got != want {
+			t.Errorf("%q = %v, want %v", in, got, want)
+		}
+	}
+
+	if x := Values(Tag, []string{"finance", "-web"}, false); x.String() != "tag:finance AND -tag:web" {
+		t.Errorf("all: %s", x)
+	}
+	if x := Values(Tag, []string{"finance", "web"}, true); x.String() != "tag:finance OR tag:web" {
+		t.Errorf("any: %s", x)
+	}
+	if x := Values(Tag, []string{"", " "}, true); x != nil {
+		t.Errorf("empty values: %s", x)
+	}
+	if got := FieldsOf(And{Term{Tag, "a"}, Not{Or{Term{Network, "b"}, Term{Tag, "c"}}}}); strings.Join(got, ",") != "network,tag" {
+		t.Errorf("FieldsOf = %v", got)
+	}
+	if got := Terms(And{Term{Tag, "a"}, Not{Or{Term{Network, "b"}, Term{Tag, "c"}}}}, Term{Skill, "d"}, nil); got != 4 {
+		t.Errorf("Terms = %d", got)
+	}
+}
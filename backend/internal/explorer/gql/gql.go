@@ -0,0 +1,152 @@
+// Package gql serves the GraphQL API: agents with their skills, registration,
+// endpoints, probes and chain, as cursor connections over the store's keyset
+// pagination. Nested lookups are batched per query level and queries are bounded
+// by depth and complexity.
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// Store is the part of *store.Postgres the resolvers use.
+type Store interface {
+	SearchAgents(ctx context.Context, p store.SearchParams) ([]store.AgentRow, string, error)
+	GetAgentsByKeys(ctx context.Context, keys []store.AgentKey) (map[store.AgentKey]store.AgentRow, error)
+	GetRegistrationsByKeys(ctx context.Context, keys []store.AgentKey) (map[store.AgentKey]store.Registration, error)
+	ListEndpointsByKeys(ctx context.Context, keys []store.AgentKey) (map[store.AgentKey][]store.Endpoint, error)
+	ListProbesByKeys(ctx context.Context, keys []store.AgentKey, perAgent int) (map[store.AgentKey][]store.ProbeResult, error)
+	ListChainStats(ctx context.Context) ([]store.ChainStats, error)
+}
+
+// Network is a configured chain as exposed by the Chain type.
+type Network struct {
+	// ID is the CAIP-2 chain id, Alias the network's name in the config file.
+	ID                 string
+	Alias              string
+	IdentityRegistry   string
+	ReputationRegistry string
+	ValidationRegistry string
+}
+
+// Config bounds the queries we execute.
+type Config struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// ConfigFromEnv reads EXPLORER_GRAPHQL_MAX_DEPTH and EXPLORER_GRAPHQL_MAX_COMPLEXITY.
+func ConfigFromEnv() Config {
+	cfg := Config{MaxDepth: 10, MaxComplexity: 5000}
+	for name, dst := range map[string]*int{
+		"EXPLORER_GRAPHQL_MAX_DEPTH":      &cfg.MaxDepth,
+		"EXPLORER_GRAPHQL_MAX_COMPLEXITY": &cfg.MaxComplexity,
+	} {
+		if v := os.Getenv(name); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				*dst = n
+			} else {
+				log.WithField("value", v).Warnf("invalid %s; using default", name)
+			}
+		}
+	}
+	return cfg
+}
+
+// Server executes GraphQL queries against the store.
+type Server struct {
+	st       Store
+	networks func() []Network
+	cfg      Config
+	schema   graphql.Schema
+}
+
+// New builds the schema. networks is called per request so it can reflect config reloads.
+func New(st Store, networks func() []Network, cfg Config) (*Server, error) {
+	schema, err := newSchema()
+	if err != nil {
+		return nil, fmt.Errorf("graphql schema: %w", err)
+	}
+	if networks == nil {
+		networks = func() []Network { return nil }
+	}
+	return &Server{st: st, networks: networks, cfg: cfg, schema: schema}, nil
+}
+
+// Request is a GraphQL-over-HTTP request body.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Execute parses, validates, checks limits and runs one query.
+func (s *Server) Execute(ctx context.Context, req Request) *graphql.Result {
+	src := source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"})
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if v := graphql.ValidateDocument(&s.schema, doc, nil); !v.IsValid {
+		return &graphql.Result{Errors: v.Errors}
+	}
+	depth, complexity, err := measure(doc, req.OperationName, req.Variables)
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}
+	}
+	if s.cfg.MaxDepth > 0 && depth > s.cfg.MaxDepth {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("query depth %d exceeds the limit of %d", depth, s.cfg.MaxDepth))}
+	}
+	if s.cfg.MaxComplexity > 0 && complexity > s.cfg.MaxComplexity {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, s.cfg.MaxComplexity))}
+	}
+
+	ctx = context.WithValue(ctx, ctxKey{}, newRequest(ctx, s.st, s.networks()))
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+}
+
+// Handler serves GET (query, operationName, variables as query parameters) and POST
+// (JSON body). Query errors are reported in the GraphQL response with status 200;
+// malformed HTTP requests get a 400 problem.
+func (s *Server) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req Request
+		if c.Request.Method == http.MethodGet {
+			req.Query = c.Query("query")
+			req.OperationName = c.Query("operationName")
+			if v := c.Query("variables"); v != "" {
+				if err := json.Unmarshal([]byte(v), &req.Variables); err != nil {
+					problem.Write(c, http.StatusBadRequest, "variables must be a JSON object")
+					return
+				}
+			}
+		} else if err := c.ShouldBindJSON(&req); err != nil {
+			problem.Write(c, http.StatusBadRequest, "body must be a JSON object with a query")
+			return
+		}
+		if req.Query == "" {
+			problem.Write(c, http.StatusBadRequest, "query required")
+			return
+		}
+		c.JSON(http.StatusOK, s.Execute(c.Request.Context(), req))
+	}
+}
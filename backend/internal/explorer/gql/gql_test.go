@@ -0,0 +1,255 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// fakeStore serves three agents on sepolia (eip155:11155111) and counts batch calls.
+type fakeStore struct {
+	agents []store.AgentRow
+	calls  map[string][]int // method -> number of keys per call
+}
+
+func newFakeStore() *fakeStore {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	f := &fakeStore{calls: map[string][]int{}}
+	for i := int64(1); i <= 3; i++ {
+		f.agents = append(f.agents, store.AgentRow{
+			ChainID: "eip155:11155111", AgentID: i, Domain: "a" + string(rune('0'+i)) + ".example",
+			CardJSON:   map[string]any{"name": "Agent " + string(rune('0'+i))},
+			Skills:     []map[string]any{{"id": "s1", "name": "Search", "tags": []any{"web"}}},
+			LastSeenAt: now.Add(-time.Duration(i) * time.Minute),
+		})
+	}
+	f.agents[1].TrustModels = []string{"tee"}
+	return f
+}
+
+func (f *fakeStore) record(method string, n int) { f.calls[method] = append(f.calls[method], n) }
+
+func (f *fakeStore) SearchAgents(_ context.Context, p store.SearchParams) ([]store.AgentRow, string, error) {
+	f.record("SearchAgents", 0)
+	agents := []store.AgentRow{}
+	for _, a := range f.agents {
+		ok := true
+		for _, x := range p.Filters {
+			ok = ok && a.Matches(x)
+		}
+		if ok {
+			agents = append(agents, a)
+		}
+	}
+	start := 0
+	if p.Cursor != "" {
+		for i, a := range agents {
+			if store.CursorFor(a) == p.Cursor {
+				start = i + 1
+			}
+		}
+	}
+	end := start + p.Limit
+	next := ""
+	if end < len(agents) {
+		next = store.CursorFor(agents[end-1])
+	} else {
+		end = len(agents)
+	}
+	return agents[start:end], next, nil
+}
+
+func (f *fakeStore) GetAgentsByKeys(_ context.Context, keys []store.AgentKey) (map[store.AgentKey]store.AgentRow, error) {
+	f.record("GetAgentsByKeys", len(keys))
+	out := map[store.AgentKey]store.AgentRow{}
+	for _, a := range f.agents {
+		out[keyOf(a)] = a
+	}
+	return out, nil
+}
+
+func (f *fakeStore) GetRegistrationsByKeys(_ context.Context, keys []store.AgentKey) (map[store.AgentKey]store.Registration, error) {
+	f.record("GetRegistrationsByKeys", len(keys))
+	out := map[store.AgentKey]store.Registration{}
+	for _, k := range keys {
+		if k.AgentID != 2 { // agent 2 has no v1 registration
+			out[k] = store.Registration{TokenURI: "ipfs://reg", Endpoints: []store.Endpoint{}, SupportedTrust: []string{}, Wallets: []string{}}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStore) ListEndpointsByKeys(_ context.Context, keys []store.AgentKey) (map[store.AgentKey][]store.Endpoint, error) {
+	f.record("ListEndpointsByKeys", len(keys))
+	return map[store.AgentKey][]store.Endpoint{}, nil
+}
+
+func (f *fakeStore) ListProbesByKeys(_ context.Context, keys []store.AgentKey, perAgent int) (map[store.AgentKey][]store.ProbeResult, error) {
+	f.record("ListProbesByKeys", len(keys))
+	out := map[store.AgentKey][]store.ProbeResult{}
+	for _, k := range keys {
+		for i := 0; i < perAgent; i++ {
+			out[k] = append(out[k], store.ProbeResult{Kind: store.ProbeCard, URL: "https://x/card"})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStore) ListChainStats(context.Context) ([]store.ChainStats, error) {
+	f.record("ListChainStats", 0)
+	return []store.ChainStats{{ChainID: "eip155:11155111", Agents: 3}}, nil
+}
+
+func newServer(t *testing.T, f *fakeStore, cfg Config) *Server {
+	t.Helper()
+	s, err := New(f, func() []Network {
+		return []Network{{ID: "eip155:11155111", Alias: "sepolia", IdentityRegistry: "0xid"}, {ID: "eip155:8453", Alias: "base"}}
+	}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func run(t *testing.T, s *Server, query string, vars map[string]any) map[string]any {
+	t.Helper()
+	res := s.Execute(context.Background(), Request{Query: query, Variables: vars})
+	if len(res.Errors) > 0 {
+		t.Fatalf("errors: %v", res.Errors)
+	}
+	b, _ := json.Marshal(res.Data)
+	var out map[string]any
+	_ = json.Unmarshal(b, &out)
+	return out
+}
+
+func TestNestedQueryIsBatched(t *testing.T) {
+	f := newFakeStore()
+	s := newServer(t, f, Config{MaxDepth: 10, MaxComplexity: 5000})
+	data := run(t, s, `{
+		agents(first: 3) {
+			nodes {
+				name
+				skills { name tags }
+				registration { tokenUri }
+				endpoints { name }
+				probes(first: 2) { url }
+				chain { id agentCount }
+			}
+		}
+	}`, nil)
+
+	nodes := data["agents"].(map[string]any)["nodes"].([]any)
+	if len(nodes) != 3 {
+		t.Fatalf("got %d nodes", len(nodes))
+	}
+	second := nodes[1].(map[string]any)
+	if second["registration"] != nil || second["name"] != "Agent 2" || len(second["probes"].([]any)) != 2 {
+		t.Errorf("agent 2 = %v", second)
+	}
+	for _, m := range []string{"GetRegistrationsByKeys", "ListEndpointsByKeys", "ListProbesByKeys", "ListChainStats"} {
+		if calls := f.calls[m]; len(calls) != 1 {
+			t.Errorf("%s called %d times (%v), want once", m, len(calls), calls)
+		}
+	}
+	if got := f.calls["GetRegistrationsByKeys"]; len(got) == 1 && got[0] != 3 {
+		t.Errorf("registrations batch size %d, want 3", got[0])
+	}
+}
+
+func TestAgentLookupsAreBatched(t *testing.T) {
+	f := newFakeStore()
+	s := newServer(t, f, Config{})
+	data := run(t, s, `{ a: agent(chainId: "sepolia", agentId: "1") { domain } b: agent(chainId: "sepolia", agentId: "3") { domain } c: agent(chainId: "sepolia", agentId: "9") { domain } }`, nil)
+	if data["c"] != nil || data["a"].(map[string]any)["domain"] != "a1.example" {
+		t.Errorf("data = %v", data)
+	}
+	if calls := f.calls["GetAgentsByKeys"]; len(calls) != 1 || calls[0] != 3 {
+		t.Errorf("GetAgentsByKeys calls = %v, want one call with 3 keys", calls)
+	}
+}
+
+func TestConnectionPagination(t *testing.T) {
+	s := newServer(t, newFakeStore(), Config{})
+	q := `query($after: String) { agents(first: 2, after: $after) { edges { cursor node { agentId } } pageInfo { hasNextPage endCursor } } }`
+
+	page := run(t, s, q, nil)["agents"].(map[string]any)
+	info := page["pageInfo"].(map[string]any)
+	if info["hasNextPage"] != true || len(page["edges"].([]any)) != 2 {
+		t.Fatalf("first page = %v", page)
+	}
+	last := page["edges"].([]any)[1].(map[string]any)
+	if last["cursor"] != info["endCursor"] {
+		t.Errorf("last edge cursor %v != endCursor %v", last["cursor"], info["endCursor"])
+	}
+
+	page = run(t, s, q, map[string]any{"after": info["endCursor"]})["agents"].(map[string]any)
+	edges := page["edges"].([]any)
+	if len(edges) != 1 || edges[0].(map[string]any)["node"].(map[string]any)["agentId"] != "3" {
+		t.Errorf("second page = %v", page)
+	}
+	if page["pageInfo"].(map[string]any)["hasNextPage"] != false {
+		t.Errorf("second page should be the last")
+	}
+}
+
+func TestAgentsFilter(t *testing.T) {
+	s := newServer(t, newFakeStore(), Config{})
+	nodes := run(t, s, `{ agents(filter: "tag:web -trust:tee") { nodes { agentId } } }`, nil)["agents"].(map[string]any)["nodes"].([]any)
+	if len(nodes) != 2 || nodes[0].(map[string]any)["agentId"] != "1" || nodes[1].(map[string]any)["agentId"] != "3" {
+		t.Errorf("nodes = %v, want agents 1 and 3", nodes)
+	}
+	res := s.Execute(context.Background(), Request{Query: `{ agents(filter: "tag:web OR") { nodes { agentId } } }`})
+	if len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, "filter:") {
+		t.Errorf("errors = %v, want a filter syntax error", res.Errors)
+	}
+	res = s.Execute(context.Background(), Request{Query: `{ agents(sort: "newest") { nodes { agentId } } }`})
+	if len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, "unknown sort") {
+		t.Errorf("errors = %v, want an unknown sort error", res.Errors)
+	}
+}
+
+func TestChains(t *testing.T) {
+	s := newServer(t, newFakeStore(), Config{})
+	chains := run(t, s, `{ chains { id identityRegistry agentCount } }`, nil)["chains"].([]any)
+	if len(chains) != 2 {
+		t.Fatalf("chains = %v", chains)
+	}
+	first := chains[0].(map[string]any)
+	if first["id"] != "eip155:11155111" || first["agentCount"] != float64(3) || first["identityRegistry"] != "0xid" {
+		t.Errorf("sepolia = %v", first)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	s := newServer(t, newFakeStore(), Config{MaxDepth: 4, MaxComplexity: 100})
+
+	deep := `{ agents { nodes { chain { agents { nodes { domain } } } } } }`
+	if res := s.Execute(context.Background(), Request{Query: deep}); len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, "depth") {
+		t.Errorf("deep query errors = %v", res.Errors)
+	}
+
+	// agents 1 + 50 * (nodes 1 + domain 1 + probes (1 + 10*url 1)) = 651
+	wide := `query($n: Int) { agents(first: $n) { nodes { domain probes { url } } } }`
+	res := s.Execute(context.Background(), Request{Query: wide, Variables: map[string]any{"n": float64(50)}})
+	if len(res.Errors) == 0 || !strings.Contains(res.Errors[0].Message, "complexity 651") {
+		t.Errorf("wide query errors = %v", res.Errors)
+	}
+
+	// fragments count too
+	frag := `{ agents(first: 50) { ...page } } fragment page on AgentConnection { nodes { probes { url } } }`
+	if res := s.Execute(context.Background(), Request{Query: frag}); len(res.Errors) == 0 {
+		t.Error("fragment query passed the complexity limit")
+	}
+
+	if res := s.Execute(context.Background(), Request{Query: `{ agents(first: 2) { nodes { domain } } }`}); len(res.Errors) > 0 {
+		t.Errorf("small query rejected: %v", res.Errors)
+	}
+	if res := s.Execute(context.Background(), Request{Query: `{ __schema { types { name fields { name type { name ofType { name ofType { name } } } } } } }`}); len(res.Errors) > 0 {
+		t.Errorf("introspection rejected: %v", res.Errors)
+	}
+}
@@ -0,0 +1,100 @@
+package gql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// cost walks the selected operation and computes its depth and complexity. Every
+// field costs 1; the cost of a field's selection is multiplied by its `first`
+// argument (or defaultFirst for connection fields without one), so
+// agents(first: 100) { nodes { probes(first: 10) { url } } } is 1 + 100*(1 + (1 + 10*1)).
+// Introspection fields (__schema, __type, ...) are free so tooling keeps working.
+type cost struct {
+	frags map[string]*ast.FragmentDefinition
+	vars  map[string]any
+}
+
+// defaultFirst is what list fields taking `first` return when it is omitted.
+var defaultFirst = map[string]int{"agents": defaultPageSize, "probes": defaultProbes}
+
+func measure(doc *ast.Document, operationName string, vars map[string]any) (depth, complexity int, err error) {
+	c := cost{frags: map[string]*ast.FragmentDefinition{}, vars: vars}
+	var op *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		switch d := def.(type) {
+		case *ast.FragmentDefinition:
+			c.frags[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operationName == "" || (d.Name != nil && d.Name.Value == operationName) {
+				op = d
+			}
+		}
+	}
+	if op == nil {
+		return 0, 0, fmt.Errorf("unknown operation %q", operationName)
+	}
+	depth, complexity = c.selection(op.SelectionSet, 0)
+	return depth, complexity, nil
+}
+
+func (c cost) selection(set *ast.SelectionSet, seen int) (depth, complexity int) {
+	if set == nil || seen > 64 { // fragment cycles are rejected by validation; this is a backstop
+		return 0, 0
+	}
+	for _, sel := range set.Selections {
+		var d, x int
+		switch s := sel.(type) {
+		case *ast.Field:
+			if strings.HasPrefix(s.Name.Value, "__") {
+				continue
+			}
+			d, x = c.selection(s.SelectionSet, seen+1)
+			d++
+			x = 1 + x*c.multiplier(s)
+		case *ast.InlineFragment:
+			d, x = c.selection(s.SelectionSet, seen+1)
+		case *ast.FragmentSpread:
+			if f, ok := c.frags[s.Name.Value]; ok {
+				d, x = c.selection(f.SelectionSet, seen+1)
+			}
+		}
+		if d > depth {
+			depth = d
+		}
+		complexity += x
+	}
+	return depth, complexity
+}
+
+func (c cost) multiplier(f *ast.Field) int {
+	for _, arg := range f.Arguments {
+		if arg.Name.Value != "first" {
+			continue
+		}
+		switch v := arg.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(v.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			switch n := c.vars[v.Name.Value].(type) {
+			case float64:
+				if n > 0 {
+					return int(n)
+				}
+			case int:
+				if n > 0 {
+					return n
+				}
+			}
+		}
+	}
+	if n, ok := defaultFirst[f.Name.Value]; ok {
+		return n
+	}
+	return 1
+}
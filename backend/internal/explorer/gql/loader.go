@@ -0,0 +1,59 @@
+package gql
+
+import "sync"
+
+// loader batches lookups made while one level of a query is resolved. Resolvers call
+// load, which only records the key and returns a thunk; graphql-go runs thunks after
+// every sibling at that depth has been resolved, so the first thunk fetches all
+// recorded keys in one query and the rest are served from the cache.
+type loader[K comparable, V any] struct {
+	mu      sync.Mutex
+	fetch   func(keys []K) (map[K]V, error)
+	pending []K
+	cache   map[K]*loaded[V]
+}
+
+type loaded[V any] struct {
+	value V
+	found bool
+	err   error
+	done  bool
+}
+
+func newLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{fetch: fetch, cache: map[K]*loaded[V]{}}
+}
+
+// load schedules k and returns a thunk yielding its value and whether it exists.
+func (l *loader[K, V]) load(k K) func() (V, bool, error) {
+	l.mu.Lock()
+	e, ok := l.cache[k]
+	if !ok {
+		e = &loaded[V]{}
+		l.cache[k] = e
+		l.pending = append(l.pending, k)
+	}
+	l.mu.Unlock()
+
+	return func() (V, bool, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !e.done {
+			l.dispatch()
+		}
+		return e.value, e.found, e.err
+	}
+}
+
+// dispatch fetches every pending key; l.mu must be held.
+func (l *loader[K, V]) dispatch() {
+	keys := l.pending
+	l.pending = nil
+	values, err := l.fetch(keys)
+	for _, k := range keys {
+		e := l.cache[k]
+		e.value, e.found = values[k]
+		e.err = err
+		e.done = true
+	}
+}
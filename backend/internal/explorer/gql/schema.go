@@ -0,0 +1,551 @@
+package gql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	defaultProbes   = 10
+	maxProbes       = 100
+)
+
+// request holds the per-query loaders; a fresh one is put in the context of every request.
+type request struct {
+	st            Store
+	networks      []Network
+	agents        *loader[store.AgentKey, store.AgentRow]
+	registrations *loader[store.AgentKey, store.Registration]
+	endpoints     *loader[store.AgentKey, []store.Endpoint]
+
+	mu     sync.Mutex
+	probes map[int]*loader[store.AgentKey, []store.ProbeResult] // keyed by `first`
+	chains map[string]store.ChainStats
+}
+
+type ctxKey struct{}
+
+func newRequest(ctx context.Context, st Store, networks []Network) *request {
+	return &request{
+		st:       st,
+		networks: networks,
+		agents: newLoader(func(keys []store.AgentKey) (map[store.AgentKey]store.AgentRow, error) {
+			return st.GetAgentsByKeys(ctx, keys)
+		}),
+		registrations: newLoader(func(keys []store.AgentKey) (map[store.AgentKey]store.Registration, error) {
+			return st.GetRegistrationsByKeys(ctx, keys)
+		}),
+		endpoints: newLoader(func(keys []store.AgentKey) (map[store.AgentKey][]store.Endpoint, error) {
+			return st.ListEndpointsByKeys(ctx, keys)
+		}),
+		probes: map[int]*loader[store.AgentKey, []store.ProbeResult]{},
+	}
+}
+
+func from(ctx context.Context) *request {
+	return ctx.Value(ctxKey{}).(*request)
+}
+
+func (r *request) probeLoader(ctx context.Context, first int) *loader[store.AgentKey, []store.ProbeResult] {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.probes[first]
+	if !ok {
+		l = newLoader(func(keys []store.AgentKey) (map[store.AgentKey][]store.ProbeResult, error) {
+			return r.st.ListProbesByKeys(ctx, keys, first)
+		})
+		r.probes[first] = l
+	}
+	return l
+}
+
+// chainStats loads agent counts for every chain once per request.
+func (r *request) chainStats(ctx context.Context) (map[string]store.ChainStats, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.chains == nil {
+		list, err := r.st.ListChainStats(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r.chains = make(map[string]store.ChainStats, len(list))
+		for _, c := range list {
+			r.chains[c.ChainID] = c
+		}
+	}
+	return r.chains, nil
+}
+
+// chain is what Chain fields resolve from: configuration merged with stats.
+type chain struct {
+	Network
+	stats store.ChainStats
+}
+
+func (r *request) chainList(ctx context.Context) ([]chain, error) {
+	stats, err := r.chainStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := []chain{}
+	seen := map[string]bool{}
+	for _, n := range r.networks {
+		seen[n.ID] = true
+		out = append(out, chain{Network: n, stats: stats[n.ID]})
+	}
+	// chains indexed earlier but no longer configured are still browsable
+	for id, s := range stats {
+		if !seen[id] {
+			out = append(out, chain{Network: Network{ID: id}, stats: s})
+		}
+	}
+	return out, nil
+}
+
+// connection is a page of agents in Relay cursor-connection shape.
+type connection struct {
+	items []store.AgentRow
+	next  string
+}
+
+// JSON passes arbitrary JSON (cards, capabilities, raw registration files) through.
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:         "JSON",
+	Description:  "Arbitrary JSON value",
+	Serialize:    func(v any) any { return v },
+	ParseValue:   func(v any) any { return v },
+	ParseLiteral: func(ast.Value) any { return nil },
+})
+
+func nonNullList(t graphql.Type) graphql.Output {
+	return graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(t)))
+}
+
+// str is a non-null string field read from the source with get.
+func str[T any](get func(T) string) *graphql.Field {
+	return &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+		return get(p.Source.(T)), nil
+	}}
+}
+
+// optStr is a nullable string field; "" resolves to null.
+func optStr[T any](get func(T) string) *graphql.Field {
+	return &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (any, error) {
+		if v := get(p.Source.(T)); v != "" {
+			return v, nil
+		}
+		return nil, nil
+	}}
+}
+
+func timeStr(t time.Time) string { return t.UTC().Format(time.RFC3339Nano) }
+
+func keyOf(a store.AgentRow) store.AgentKey {
+	return store.AgentKey{ChainID: a.ChainID, AgentID: a.AgentID}
+}
+
+func newSchema() (graphql.Schema, error) {
+	endpointType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Endpoint",
+		Fields: graphql.Fields{
+			"source":   str(func(e store.Endpoint) string { return e.Source }),
+			"name":     str(func(e store.Endpoint) string { return e.Name }),
+			"endpoint": str(func(e store.Endpoint) string { return e.Endpoint }),
+			"version":  optStr(func(e store.Endpoint) string { return e.Version }),
+			"extra": &graphql.Field{Type: jsonScalar, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(store.Endpoint).Extra, nil
+			}},
+		},
+	})
+
+	skillType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Skill",
+		Description: "A skill declared in the agent card",
+		Fields: graphql.Fields{
+			"id":          optStr(func(s map[string]any) string { v, _ := s["id"].(string); return v }),
+			"name":        optStr(func(s map[string]any) string { v, _ := s["name"].(string); return v }),
+			"description": optStr(func(s map[string]any) string { v, _ := s["description"].(string); return v }),
+			"tags": &graphql.Field{Type: nonNullList(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				tags := []string{}
+				raw, _ := p.Source.(map[string]any)["tags"].([]any)
+				for _, t := range raw {
+					if s, ok := t.(string); ok {
+						tags = append(tags, s)
+					}
+				}
+				return tags, nil
+			}},
+		},
+	})
+
+	registrationType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Registration",
+		Description: "The agent's ERC-8004 v1 registration file",
+		Fields: graphql.Fields{
+			"tokenUri":    str(func(r store.Registration) string { return r.TokenURI }),
+			"owner":       optStr(func(r store.Registration) string { return r.Owner }),
+			"name":        optStr(func(r store.Registration) string { return r.Name }),
+			"description": optStr(func(r store.Registration) string { return r.Description }),
+			"endpoints": &graphql.Field{Type: nonNullList(endpointType), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(store.Registration).Endpoints, nil
+			}},
+			"supportedTrust": &graphql.Field{Type: nonNullList(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(store.Registration).SupportedTrust, nil
+			}},
+			"wallets": &graphql.Field{Type: nonNullList(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(store.Registration).Wallets, nil
+			}},
+			"verified": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(store.Registration).Verified, nil
+			}},
+			"raw": &graphql.Field{Type: jsonScalar, Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(store.Registration).Raw, nil
+			}},
+		},
+	})
+
+	probeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Probe",
+		Description: "One liveness check of an agent endpoint",
+		Fields: graphql.Fields{
+			"kind":     str(func(r store.ProbeResult) string { return r.Kind }),
+			"url":      str(func(r store.ProbeResult) string { return r.URL }),
+			"probedAt": str(func(r store.ProbeResult) string { return timeStr(r.ProbedAt) }),
+			"error":    optStr(func(r store.ProbeResult) string { return r.Error }),
+			"httpStatus": &graphql.Field{Type: graphql.Int, Resolve: func(p graphql.ResolveParams) (any, error) {
+				if s := p.Source.(store.ProbeResult).HTTPStatus; s != 0 {
+					return s, nil
+				}
+				return nil, nil
+			}},
+			"latencyMs": &graphql.Field{Type: graphql.NewNonNull(graphql.Float), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(store.ProbeResult).LatencyMs, nil
+			}},
+			"tlsValid": &graphql.Field{Type: graphql.Boolean, Resolve: func(p graphql.ResolveParams) (any, error) {
+				if v := p.Source.(store.ProbeResult).TLSValid; v != nil {
+					return *v, nil
+				}
+				return nil, nil
+			}},
+			"handshakeOk": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(store.ProbeResult).HandshakeOK, nil
+			}},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(connection).next != "", nil
+			}},
+			"endCursor": optStr(func(c connection) string { return c.next }),
+		},
+	})
+
+	// Agent and Chain reference each other, so their fields are thunks.
+	var agentType, chainType *graphql.Object
+	var agentConnectionType *graphql.Object
+
+	nullable := func(v any, err error) (any, error) {
+		if err != nil || v == nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	num := func(v *float64) any {
+		if v == nil {
+			return nil
+		}
+		return *v
+	}
+
+	agentType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Agent",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":          str(func(a store.AgentRow) string { return a.ChainID + "/" + strconv.FormatInt(a.AgentID, 10) }),
+				"chainId":     str(func(a store.AgentRow) string { return a.ChainID }),
+				"agentId":     str(func(a store.AgentRow) string { return strconv.FormatInt(a.AgentID, 10) }),
+				"domain":      str(func(a store.AgentRow) string { return a.Domain }),
+				"name":        optStr(func(a store.AgentRow) string { v, _ := a.CardJSON["name"].(string); return v }),
+				"description": optStr(func(a store.AgentRow) string { v, _ := a.CardJSON["description"].(string); return v }),
+				"address":     optStr(func(a store.AgentRow) string { return a.AddressCAIP }),
+				"status":      optStr(func(a store.AgentRow) string { return a.Status }),
+				"lastSeenAt":  str(func(a store.AgentRow) string { return timeStr(a.LastSeenAt) }),
+				"registeredAt": optStr(func(a store.AgentRow) string {
+					if a.RegisteredAt.IsZero() {
+						return ""
+					}
+					return timeStr(a.RegisteredAt)
+				}),
+				"lastProbedAt": optStr(func(a store.AgentRow) string {
+					if a.LastProbedAt == nil {
+						return ""
+					}
+					return timeStr(*a.LastProbedAt)
+				}),
+				"trustModels": &graphql.Field{Type: nonNullList(graphql.String), Resolve: func(p graphql.ResolveParams) (any, error) {
+					if tm := p.Source.(store.AgentRow).TrustModels; tm != nil {
+						return tm, nil
+					}
+					return []string{}, nil
+				}},
+				"skills": &graphql.Field{Type: nonNullList(skillType), Resolve: func(p graphql.ResolveParams) (any, error) {
+					if sk := p.Source.(store.AgentRow).Skills; sk != nil {
+						return sk, nil
+					}
+					return []map[string]any{}, nil
+				}},
+				"capabilities": &graphql.Field{Type: jsonScalar, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(store.AgentRow).Capabilities, nil
+				}},
+				"card": &graphql.Field{Type: jsonScalar, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(store.AgentRow).CardJSON, nil
+				}},
+				"uptimePct": &graphql.Field{Type: graphql.Float, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return num(p.Source.(store.AgentRow).UptimePct), nil
+				}},
+				"latencyP50Ms": &graphql.Field{Type: graphql.Float, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return num(p.Source.(store.AgentRow).LatencyP50Ms), nil
+				}},
+				"latencyP95Ms": &graphql.Field{Type: graphql.Float, Resolve: func(p graphql.ResolveParams) (any, error) {
+					return num(p.Source.(store.AgentRow).LatencyP95Ms), nil
+				}},
+				"scoreAvg": &graphql.Field{Type: graphql.Float, Description: "Average reputation score, once reputation events are indexed",
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return num(p.Source.(store.AgentRow).ScoreAvg), nil
+					}},
+				"feedbackCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(store.AgentRow).FeedbacksCnt, nil
+				}},
+				"validationCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(store.AgentRow).ValidationsCnt, nil
+				}},
+				"endpoints": &graphql.Field{Type: nonNullList(endpointType), Resolve: func(p graphql.ResolveParams) (any, error) {
+					thunk := from(p.Context).endpoints.load(keyOf(p.Source.(store.AgentRow)))
+					return func() (any, error) {
+						eps, _, err := thunk()
+						if eps == nil && err == nil {
+							eps = []store.Endpoint{}
+						}
+						return eps, err
+					}, nil
+				}},
+				"registration": &graphql.Field{Type: registrationType, Resolve: func(p graphql.ResolveParams) (any, error) {
+					thunk := from(p.Context).registrations.load(keyOf(p.Source.(store.AgentRow)))
+					return func() (any, error) {
+						reg, ok, err := thunk()
+						if !ok {
+							return nil, err
+						}
+						return reg, err
+					}, nil
+				}},
+				"probes": &graphql.Field{
+					Type:        nonNullList(probeType),
+					Description: "Most recent probe results, newest first",
+					Args: graphql.FieldConfigArgument{
+						"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultProbes},
+					},
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						first, _ := p.Args["first"].(int)
+						if first < 1 || first > maxProbes {
+							return nil, fmt.Errorf("first must be between 1 and %d", maxProbes)
+						}
+						thunk := from(p.Context).probeLoader(p.Context, first).load(keyOf(p.Source.(store.AgentRow)))
+						return func() (any, error) {
+							probes, _, err := thunk()
+							if probes == nil && err == nil {
+								probes = []store.ProbeResult{}
+							}
+							return probes, err
+						}, nil
+					},
+				},
+				"chain": &graphql.Field{Type: graphql.NewNonNull(chainType), Resolve: func(p graphql.ResolveParams) (any, error) {
+					id := p.Source.(store.AgentRow).ChainID
+					chains, err := from(p.Context).chainList(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					for _, c := range chains {
+						if c.ID == id {
+							return c, nil
+						}
+					}
+					return chain{Network: Network{ID: id}}, nil
+				}},
+			}
+		}),
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "AgentEdge",
+		Fields: graphql.Fields{
+			"cursor": str(func(a store.AgentRow) string { return store.CursorFor(a) }),
+			"node": &graphql.Field{Type: graphql.NewNonNull(agentType), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source, nil
+			}},
+		},
+	})
+
+	agentConnectionType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "AgentConnection",
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{Type: nonNullList(edgeType), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(connection).items, nil
+			}},
+			"nodes": &graphql.Field{Type: nonNullList(agentType), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(connection).items, nil
+			}},
+			"pageInfo": &graphql.Field{Type: graphql.NewNonNull(pageInfoType), Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source, nil
+			}},
+		},
+	})
+
+	searchArgs := func(withNetwork bool) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+			"after": &graphql.ArgumentConfig{Type: graphql.String},
+		}
+		names := []string{"q", "capability", "skill", "tag", "trustModel", "endpoint", "status", "tool", "filter", "sort", "order", "semantic"}
+		if withNetwork {
+			names = append(names, "network")
+		}
+		for _, n := range names {
+			args[n] = &graphql.ArgumentConfig{Type: graphql.String}
+		}
+		return args
+	}
+	search := func(p graphql.ResolveParams, network string) (any, error) {
+		first, _ := p.Args["first"].(int)
+		if first < 1 || first > maxPageSize {
+			return nil, fmt.Errorf("first must be between 1 and %d", maxPageSize)
+		}
+		arg := func(n string) string { s, _ := p.Args[n].(string); return s }
+		if network == "" {
+			network = arg("network")
+		}
+		params := store.SearchParams{
+			Q: arg("q"), Network: network, Capability: arg("capability"), Skill: arg("skill"), Tag: arg("tag"),
+			TrustModel: arg("trustModel"), Endpoint: arg("endpoint"), Status: arg("status"), Tool: arg("tool"),
+			Semantic: arg("semantic"), Cursor: arg("after"), Limit: first,
+		}
+		// filter: the same expression language as GET /agents?filter=
+		x, err := filter.Parse(arg("filter"))
+		if err != nil {
+			return nil, err
+		}
+		if x != nil {
+			params.Filters = []filter.Expr{x}
+		}
+		// sort, order: as on GET /agents; a cursor only resumes the sort it came from
+		if params.Sort, err = store.ParseSort(arg("sort"), arg("order")); err != nil {
+			return nil, err
+		}
+		items, next, err := from(p.Context).st.SearchAgents(p.Context, params)
+		if err != nil {
+			return nil, err
+		}
+		return connection{items: items, next: next}, nil
+	}
+
+	chainType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Chain",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"id":                 str(func(c chain) string { return c.ID }),
+				"alias":              optStr(func(c chain) string { return c.Alias }),
+				"identityRegistry":   optStr(func(c chain) string { return c.IdentityRegistry }),
+				"reputationRegistry": optStr(func(c chain) string { return c.ReputationRegistry }),
+				"validationRegistry": optStr(func(c chain) string { return c.ValidationRegistry }),
+				"lastSeenAt": optStr(func(c chain) string {
+					if c.stats.LastSeenAt == nil {
+						return ""
+					}
+					return timeStr(*c.stats.LastSeenAt)
+				}),
+				"agentCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: func(p graphql.ResolveParams) (any, error) {
+					return p.Source.(chain).stats.Agents, nil
+				}},
+				"agents": &graphql.Field{Type: graphql.NewNonNull(agentConnectionType), Args: searchArgs(false),
+					Resolve: func(p graphql.ResolveParams) (any, error) {
+						return search(p, p.Source.(chain).ID)
+					}},
+			}
+		}),
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"agent": &graphql.Field{
+				Type: agentType,
+				Args: graphql.FieldConfigArgument{
+					"chainId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"agentId": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := strconv.ParseInt(p.Args["agentId"].(string), 10, 64)
+					if err != nil {
+						return nil, errors.New("agentId must be an integer")
+					}
+					thunk := from(p.Context).agents.load(store.AgentKey{ChainID: caip.Normalize(p.Args["chainId"].(string)), AgentID: id})
+					return func() (any, error) {
+						a, ok, err := thunk()
+						if !ok {
+							return nil, err
+						}
+						return a, err
+					}, nil
+				},
+			},
+			"agents": &graphql.Field{
+				Type: graphql.NewNonNull(agentConnectionType),
+				Args: searchArgs(true),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return search(p, "")
+				},
+			},
+			"chains": &graphql.Field{
+				Type: nonNullList(chainType),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return nullable(from(p.Context).chainList(p.Context))
+				},
+			},
+			"chain": &graphql.Field{
+				Type: chainType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)}},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					chains, err := from(p.Context).chainList(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					for _, c := range chains {
+						if strings.EqualFold(c.ID, p.Args["id"].(string)) {
+							return c, nil
+						}
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query})
+}
@@ -0,0 +1,80 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrChainMismatch reports an RPC endpoint serving a different chain than its
+// network is configured for.
+var ErrChainMismatch = errors.New("RPC serves a different chain")
+
+// dialTimeout bounds dialing one network and asking for its chain id.
+const dialTimeout = 15 * time.Second
+
+// dialChain connects to the network's RPC endpoint and checks that eth_chainId
+// matches the configured chain id, so a misconfigured RPC cannot file another
+// chain's agents under this one.
+func (ix *Indexer) dialChain(ctx context.Context, n Chain) (*ethclient.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, os.ExpandEnv(n.RPC))
+	if err != nil {
+		return nil, err
+	}
+	began := time.Now()
+	id, err := client.ChainID(ctx)
+	ix.observeRPC(n.Name, "eth_chainId", began, err)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("eth_chainId: %w", err)
+	}
+	if !id.IsUint64() || id.Uint64() != n.ChainID {
+		client.Close()
+		return nil, fmt.Errorf("%w: network %s is configured as chain id %d, RPC reports %s", ErrChainMismatch, n.Alias, n.ChainID, id)
+	}
+	return client, nil
+}
+
+// prepareChains runs at startup: it makes every configured network name resolve to
+// its CAIP-2 id, moves agents stored under the name to the id, and checks each RPC
+// endpoint's chain id. A mismatch refuses startup; an unreachable RPC is only
+// logged, and the watcher tries again.
+func (ix *Indexer) prepareChains(ctx context.Context) error {
+	for _, n := range ix.nets {
+		caip.RegisterAlias(n.Alias, n.Name)
+		if ix.store != nil && n.Alias != n.Name {
+			moved, err := ix.store.RenameChain(ctx, n.Alias, n.Name)
+			if err != nil {
+				return fmt.Errorf("move agents of %s to %s: %w", n.Alias, n.Name, err)
+			}
+			if moved > 0 {
+				log.WithFields(log.Fields{"alias": n.Alias, "chain": n.Name, "agents": moved}).Info("moved agents to CAIP-2 chain id")
+			}
+		}
+		if strings.TrimSpace(n.RPC) == "" {
+			continue
+		}
+		client, err := ix.dialChain(ctx, n)
+		if errors.Is(err, ErrChainMismatch) {
+			return err
+		}
+		if err != nil {
+			log.WithError(err).WithField("chain", n.Name).Warn("could not verify chain id; will retry when watching")
+			ix.chainError(n.Name, err)
+			continue
+		}
+		ix.chainMu.Lock()
+		ix.clients[n.Name] = client
+		ix.chainMu.Unlock()
+	}
+	return nil
+}
@@ -1,38 +1,67 @@
 package indexer
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	"github.com/praxis/praxis-explorer/internal/explorer/fetcher"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v3"
 )
 
+// Config is the parsed erc8004.yaml: the networks to index plus indexer settings.
+type Config struct {
+	Networks []Chain
+	Fetch    fetcher.Config
+	Probes   ProbeConfig
+	MCP      MCPConfig
+}
+
 type yamlConfig struct {
 	Networks map[string]struct {
+		// ChainID is the EVM chain id the RPC must report; optional for
+		// well-known network names and CAIP-2 keys
+		ChainID    uint64 `yaml:"chainId"`
 		RPC        string `yaml:"rpc"`
 		Identity   string `yaml:"identity"`
 		Reputation string `yaml:"reputation"`
 		Validation string `yaml:"validation"`
 	} `yaml:"networks"`
+	Fetch  fetcher.Config `yaml:"fetch"`
+	Probes ProbeConfig    `yaml:"probes"`
+	MCP    MCPConfig      `yaml:"mcp"`
 }
 
-func loadConfig(path string) ([]Chain, error) {
+func loadConfig(path string) (Config, error) {
 	if path == "" {
-		return []Chain{}, nil
+		return Config{Networks: []Chain{}, Fetch: fetcher.DefaultConfig(), Probes: defaultProbeConfig(), MCP: defaultMCPConfig()}, nil
 	}
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
-		return []Chain{}, err
+		return Config{}, err
 	}
-	var cfg yamlConfig
+	cfg := yamlConfig{Fetch: fetcher.DefaultConfig(), Probes: defaultProbeConfig(), MCP: defaultMCPConfig()}
 	if err := yaml.Unmarshal(b, &cfg); err != nil {
-		return []Chain{}, err
+		return Config{}, err
 	}
 	out := make([]Chain, 0, len(cfg.Networks))
+	seen := map[uint64]string{}
 	for name, n := range cfg.Networks {
+		id, err := networkChainID(name, n.ChainID)
+		if err != nil {
+			return Config{}, err
+		}
+		if other, dup := seen[id]; dup {
+			return Config{}, fmt.Errorf("networks %q and %q are both chain id %d", other, name, id)
+		}
+		seen[id] = name
 		c := Chain{
-			Name:       name,
+			Name:       caip.EVMChain(id),
+			Alias:      name,
+			ChainID:    id,
 			RPC:        os.ExpandEnv(n.RPC),
 			Identity:   n.Identity,
 			Reputation: n.Reputation,
@@ -41,6 +70,7 @@ func loadConfig(path string) ([]Chain, error) {
 		// 👇 add a log entry here
 		log.WithFields(log.Fields{
 			"chain":      c.Name,
+			"alias":      c.Alias,
 			"rpc":        c.RPC,
 			"identity":   c.Identity,
 			"reputation": c.Reputation,
@@ -49,5 +79,34 @@ func loadConfig(path string) ([]Chain, error) {
 
 		out = append(out, c)
 	}
-	return out, nil
+	log.WithFields(log.Fields{
+		"ipfsGateways":    cfg.Fetch.IPFSGateways,
+		"arweaveGateways": cfg.Fetch.ArweaveGateways,
+		"strategy":        cfg.Fetch.Strategy,
+		"verifyCid":       cfg.Fetch.VerifyCID,
+	}).Info("loaded fetch config")
+	log.WithFields(log.Fields{
+		"interval": cfg.Probes.Interval,
+		"window":   cfg.Probes.Window,
+	}).Info("loaded probe config")
+	return Config{Networks: out, Fetch: cfg.Fetch, Probes: cfg.Probes, MCP: cfg.MCP}, nil
+}
+
+// networkChainID is the EVM chain id of the network configured under name: the
+// explicit chainId, else the id of a CAIP-2 key (eip155:8453) or well-known name.
+// An explicit id must agree with the one the name implies.
+func networkChainID(name string, explicit uint64) (uint64, error) {
+	implied, ok := caip.EVMChainID(name)
+	if !ok {
+		implied = caip.KnownEVMChains[strings.ToLower(name)]
+	}
+	switch {
+	case explicit == 0 && implied == 0:
+		return 0, fmt.Errorf("network %q: chainId is required for networks without a well-known name", name)
+	case explicit == 0:
+		return implied, nil
+	case implied != 0 && caip.IsChain(name) && implied != explicit:
+		return 0, fmt.Errorf("network %q: chainId %d contradicts the key", name, explicit)
+	}
+	return explicit, nil
 }
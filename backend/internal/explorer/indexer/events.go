@@ -0,0 +1,154 @@
+package indexer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/praxis/praxis-explorer/internal/explorer/logging"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// blockTimeCacheSize bounds the block timestamps remembered between logs; logs
+// arrive in block order, so a small cache saves most header lookups.
+const blockTimeCacheSize = 1024
+
+// recordAgentEvent stores lg, an ev log about agentID, in the agent's timeline
+// with its block timestamp and transaction sender. Without an RPC client for the
+// chain, or when a lookup fails, the event is stored without them.
+func (ix *Indexer) recordAgentEvent(ctx context.Context, chain string, agentID int64, ev abi.Event, lg types.Log) {
+	args, err := decodeEventArgs(ev, lg)
+	if err != nil {
+		logging.From(ctx).WithError(err).Warn("failed decoding event arguments")
+	}
+	e := store.AgentEvent{
+		ChainID:     chain,
+		AgentID:     agentID,
+		BlockNumber: lg.BlockNumber,
+		TxHash:      lg.TxHash.Hex(),
+		LogIndex:    lg.Index,
+		Type:        ev.Name,
+		Args:        args,
+	}
+	ix.chainMu.RLock()
+	client := ix.clients[chain]
+	ix.chainMu.RUnlock()
+	if client != nil {
+		if t, err := ix.blockTime(ctx, chain, client, lg); err == nil {
+			e.BlockTime = &t
+		} else {
+			logging.From(ctx).WithError(err).Warn("failed reading block timestamp")
+		}
+		if from, err := ix.txSender(ctx, chain, client, lg); err == nil {
+			e.From = from.Hex()
+		} else {
+			logging.From(ctx).WithError(err).Warn("failed reading transaction sender")
+		}
+	}
+	if ix.store == nil {
+		return
+	}
+	if err := ix.store.RecordAgentEvent(ctx, e); err != nil {
+		logging.From(ctx).WithError(err).Warn("failed to record agent event")
+	}
+}
+
+// blockTime returns the timestamp of the block lg was emitted in.
+func (ix *Indexer) blockTime(ctx context.Context, chain string, client chainReader, lg types.Log) (time.Time, error) {
+	ix.blockTimesMu.Lock()
+	t, ok := ix.blockTimes[lg.BlockHash]
+	ix.blockTimesMu.Unlock()
+	if ok {
+		return t, nil
+	}
+	began := time.Now()
+	method := "eth_getBlockByHash"
+	var h *types.Header
+	var err error
+	if lg.BlockHash != (common.Hash{}) {
+		h, err = client.HeaderByHash(ctx, lg.BlockHash)
+	} else {
+		method = "eth_getBlockByNumber"
+		h, err = client.HeaderByNumber(ctx, new(big.Int).SetUint64(lg.BlockNumber))
+	}
+	ix.observeRPC(chain, method, began, err)
+	if err != nil {
+		return time.Time{}, err
+	}
+	t = time.Unix(int64(h.Time), 0).UTC()
+	ix.blockTimesMu.Lock()
+	if ix.blockTimes == nil || len(ix.blockTimes) >= blockTimeCacheSize {
+		ix.blockTimes = map[common.Hash]time.Time{}
+	}
+	ix.blockTimes[lg.BlockHash] = t
+	ix.blockTimesMu.Unlock()
+	return t, nil
+}
+
+// txSender returns the sender of the transaction that emitted lg.
+func (ix *Indexer) txSender(ctx context.Context, chain string, client chainReader, lg types.Log) (common.Address, error) {
+	began := time.Now()
+	tx, _, err := client.TransactionByHash(ctx, lg.TxHash)
+	ix.observeRPC(chain, "eth_getTransactionByHash", began, err)
+	if err != nil {
+		return common.Address{}, err
+	}
+	// the client remembers the sender the node reported with the transaction, so
+	// this only calls out for transactions it could not fetch that way
+	return client.TransactionSender(ctx, tx, lg.BlockHash, lg.TxIndex)
+}
+
+// chainReader is the part of *ethclient.Client recordAgentEvent needs.
+type chainReader interface {
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	TransactionSender(ctx context.Context, tx *types.Transaction, block common.Hash, index uint) (common.Address, error)
+}
+
+// decodeEventArgs decodes the indexed and data arguments of lg into JSON-friendly
+// values: integers as decimal strings (they may exceed 2^53), addresses and
+// hashes as hex.
+func decodeEventArgs(ev abi.Event, lg types.Log) (map[string]any, error) {
+	args := map[string]any{}
+	var indexed abi.Arguments
+	for _, in := range ev.Inputs {
+		if in.Indexed {
+			indexed = append(indexed, in)
+		}
+	}
+	if len(lg.Topics) < len(indexed)+1 {
+		return args, fmt.Errorf("%s: %d topics, want %d", ev.Name, len(lg.Topics), len(indexed)+1)
+	}
+	if err := abi.ParseTopicsIntoMap(args, indexed, lg.Topics[1:]); err != nil {
+		return args, err
+	}
+	if err := ev.Inputs.NonIndexed().UnpackIntoMap(args, lg.Data); err != nil {
+		return args, err
+	}
+	for k, v := range args {
+		args[k] = jsonArg(v)
+	}
+	return args, nil
+}
+
+func jsonArg(v any) any {
+	switch x := v.(type) {
+	case *big.Int:
+		return x.String()
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	case [32]byte:
+		return common.Hash(x).Hex()
+	case []byte:
+		return hexutil.Encode(x)
+	}
+	return v
+}
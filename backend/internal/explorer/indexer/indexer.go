@@ -12,25 +12,35 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	erc "github.com/praxis/praxis-explorer/internal/erc8004"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	"github.com/praxis/praxis-explorer/internal/explorer/fetcher"
+	"github.com/praxis/praxis-explorer/internal/explorer/logging"
+	"github.com/praxis/praxis-explorer/internal/explorer/metrics"
 	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	"github.com/praxis/praxis-explorer/internal/explorer/tracing"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"io"
 	"math/big"
 	"net/http"
-	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
-func init() {
-	log.SetFormatter(&log.TextFormatter{
-		FullTimestamp: true,
-	})
-	log.SetLevel(log.InfoLevel)
-}
+var tracer = otel.Tracer("github.com/praxis/praxis-explorer/internal/explorer/indexer")
 
 type Chain struct {
-	Name       string
+	// Name is the CAIP-2 chain id (eip155:11155111) agents are stored under.
+	Name string
+	// Alias is the network's name in the config file (sepolia); the API accepts it too.
+	Alias string
+	// ChainID is the EVM chain id the RPC endpoint must report.
+	ChainID    uint64
 	RPC        string
 	Identity   string
 	Reputation string
@@ -41,22 +51,43 @@ type Indexer struct {
 	store *store.Postgres
 	nets  []Chain
 	seeds []string // optional list of seed domains to crawl if logs are unavailable
-	// runtime
+	// CAIP-2 chain seed agents are stored under
+	seedChain string
+	uris      fetcher.Resolver
+	// liveness prober
+	probes      ProbeConfig
+	probeClient *http.Client
+	probeOnce   sync.Once
+	mcp         MCPConfig
+	// runtime; clients and idents are also read by API-triggered refreshes
+	chainMu sync.RWMutex
 	clients map[string]*ethclient.Client
 	idents  map[string]common.Address
 	idABI   abi.ABI
+	// what /status reports
+	statusMu   sync.Mutex
+	chains     map[string]*ChainStatus
+	seedStatus SeedStatus
+	// block timestamps of recent logs, for agent_events
+	blockTimesMu sync.Mutex
+	blockTimes   map[common.Hash]time.Time
+	// domains and accounts no registry knew, until when; see ResolveDomain
+	unresolvedMu sync.Mutex
+	unresolved   map[string]time.Time
 }
 
 func New(st *store.Postgres, cfgPath string) (*Indexer, error) {
-	nets, err := loadConfig(cfgPath)
+	cfg, err := loadConfig(cfgPath)
 	if err != nil {
 		log.WithError(err).WithField("cfgPath", cfgPath).Error("failed to load config")
 		return nil, err
 	}
+	nets := cfg.Networks
 
 	for _, n := range nets {
 		log.WithFields(log.Fields{
 			"chain":      n.Name,
+			"alias":      n.Alias,
 			"rpc":        n.RPC,
 			"identity":   n.Identity,
 			"reputation": n.Reputation,
@@ -74,14 +105,23 @@ func New(st *store.Postgres, cfgPath string) (*Indexer, error) {
 		return nil, fmt.Errorf("identity abi parse: %w", err)
 	}
 
-	return &Indexer{
-		store:   st,
-		nets:    nets,
-		seeds:   seeds,
-		clients: map[string]*ethclient.Client{},
-		idents:  map[string]common.Address{},
-		idABI:   parsed,
-	}, nil
+	ix := &Indexer{
+		store:     st,
+		nets:      nets,
+		seeds:     seeds,
+		seedChain: seedChainFromEnv(),
+		uris:      fetcher.New(cfg.Fetch, nil),
+		probes:    cfg.Probes,
+		mcp:       cfg.MCP,
+		clients:   map[string]*ethclient.Client{},
+		idents:    map[string]common.Address{},
+		idABI:     parsed,
+	}
+	if err := ix.prepareChains(context.Background()); err != nil {
+		log.WithError(err).Error("refusing to start")
+		return nil, err
+	}
+	return ix, nil
 }
 
 func (ix *Indexer) Start(ctx context.Context) {
@@ -93,6 +133,8 @@ func (ix *Indexer) Start(ctx context.Context) {
 	ix.crawlSeeds(ctx)
 	ix.upgradeZeroIDs(ctx)
 	ix.startOnchainWatchers(ctx)
+	go ix.runProber(ctx)
+	go ix.runMCPRefresher(ctx)
 
 	for {
 		select {
@@ -107,6 +149,10 @@ func (ix *Indexer) Start(ctx context.Context) {
 }
 
 func (ix *Indexer) crawlSeeds(ctx context.Context) {
+	if len(ix.seeds) == 0 {
+		return
+	}
+	fetched, failed := 0, 0
 	for _, domain := range ix.seeds {
 		d := strings.TrimSpace(domain)
 		if d == "" {
@@ -118,16 +164,36 @@ func (ix *Indexer) crawlSeeds(ctx context.Context) {
 		resp, err := http.Get(url) // #nosec G107 (operator-provided domains)
 		if err != nil {
 			log.WithError(err).Warn("seed fetch error")
+			failed++
 			continue
 		}
 		var card map[string]any
 		if err := json.NewDecoder(resp.Body).Decode(&card); err == nil {
 			// registry unknown when crawling seeds
-			_ = ix.store.UpsertAgentFromCard(ctx, "sepolia", "", 0, d, card)
+			_ = ix.store.UpsertAgentFromCard(ctx, ix.seedChain, "", 0, d, card)
+			fetched++
+		} else {
+			failed++
 		}
 		resp.Body.Close()
 		log.WithField("domain", domain).Info("seed card fetched")
 	}
+	now := time.Now().UTC()
+	ix.statusMu.Lock()
+	ix.seedStatus = SeedStatus{LastCrawlAt: &now, LastFetched: fetched, LastFailed: failed}
+	ix.statusMu.Unlock()
+}
+
+// seedChainFromEnv reads EXPLORER_SEED_CHAIN, a CAIP-2 id or network name
+// (default sepolia).
+func seedChainFromEnv() string {
+	if v := os.Getenv("EXPLORER_SEED_CHAIN"); v != "" {
+		if c := caip.Normalize(v); caip.IsChain(c) {
+			return c
+		}
+		log.WithField("value", v).Warn("invalid EXPLORER_SEED_CHAIN; using default")
+	}
+	return caip.EVMChain(caip.KnownEVMChains["sepolia"])
 }
 
 func readSeedsFromEnv() []string {
@@ -150,6 +216,7 @@ func readSeedsFromEnv() []string {
 func (ix *Indexer) startOnchainWatchers(ctx context.Context) {
 	for _, n := range ix.nets {
 		if strings.TrimSpace(n.RPC) == "" || strings.TrimSpace(n.Identity) == "" {
+			ix.setMode(n.Name, ModeDisabled)
 			continue
 		}
 		log.WithFields(log.Fields{
@@ -157,15 +224,56 @@ func (ix *Indexer) startOnchainWatchers(ctx context.Context) {
 			"rpc":   n.RPC,
 		}).Info("connecting to chain")
 
-		client, err := ethclient.Dial(os.ExpandEnv(n.RPC))
+		// prepareChains already dialed reachable RPCs
+		ix.chainMu.RLock()
+		client := ix.clients[n.Name]
+		ix.chainMu.RUnlock()
+		if client == nil {
+			var err error
+			if client, err = ix.dialChain(ctx, n); err != nil {
+				log.WithError(err).WithField("rpc", n.RPC).Error("failed to dial RPC")
+				ix.chainError(n.Name, err)
+				ix.setMode(n.Name, ModeDown)
+				continue
+			}
+		}
+		ix.setMode(n.Name, ModeConnecting)
+		idAddr := common.HexToAddress(n.Identity)
+		ix.chainMu.Lock()
+		ix.clients[n.Name] = client
+		ix.idents[n.Name] = idAddr
+		ix.chainMu.Unlock()
+		go ix.watchIdentity(ctx, n.Name, client, idAddr)
+		go ix.backfillAgents(ctx, n.Name, client, idAddr)
+		go ix.reportLag(ctx, n.Name, client, idAddr)
+	}
+}
+
+// lagInterval is how often head_lag_blocks is refreshed.
+const lagInterval = 30 * time.Second
+
+// reportLag keeps the chain head, block checkpoint and the lag between them
+// current for /status and the head_lag_blocks gauge.
+func (ix *Indexer) reportLag(ctx context.Context, chain string, client *ethclient.Client, idAddr common.Address) {
+	ticker := time.NewTicker(lagInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		began := time.Now()
+		head, err := client.BlockNumber(ctx)
+		ix.observeRPC(chain, "eth_blockNumber", began, err)
 		if err != nil {
-			log.WithError(err).WithField("rpc", n.RPC).Error("failed to dial RPC")
 			continue
 		}
-		ix.clients[n.Name] = client
-		ix.idents[n.Name] = common.HexToAddress(n.Identity)
-		go ix.watchIdentity(ctx, n.Name, client, ix.idents[n.Name])
-		go ix.backfillAgents(ctx, n.Name, client, ix.idents[n.Name])
+		cp, err := ix.store.GetCheckpoint(ctx, chain, idAddr.Hex())
+		if err != nil || cp.LastBlock == 0 {
+			continue
+		}
+		ix.setProgress(chain, head, cp.LastBlock)
 	}
 }
 
@@ -179,7 +287,9 @@ func (ix *Indexer) backfillAgents(ctx context.Context, chain string, client *eth
 	if err != nil {
 		return
 	}
+	began := time.Now()
 	count, err := ident.GetAgentCount(ctx, &bind.CallOpts{Context: ctx})
+	ix.observeRPC(chain, "getAgentCount", began, err)
 	if err != nil || count == nil {
 		log.WithError(err).Error("failed to get agent count")
 		return
@@ -195,12 +305,24 @@ func (ix *Indexer) backfillAgents(ctx context.Context, chain string, client *eth
 		"count": total,
 	}).Info("found agents on chain")
 
-	for i := int64(1); i <= total; i++ {
+	// resume after the last agent a previous run (or a restored snapshot) visited;
+	// the checkpoint only advances while every agent before it was read
+	cp := ix.checkpoint(ctx, chain, idAddr)
+	contiguous := true
+	for i := cp.AgentsBackfilled + 1; i <= total; i++ {
+		began := time.Now()
 		ai, err := ident.GetAgent(ctx, &bind.CallOpts{Context: ctx}, big.NewInt(i))
+		ix.observeRPC(chain, "getAgent", began, err)
 		if err != nil || ai.AgentId == nil {
 			log.WithError(err).Error("failed to get agent")
+			contiguous = false
 			continue
 		}
+		if contiguous {
+			if err := ix.store.SaveBackfillCheckpoint(ctx, chain, idAddr.Hex(), i); err != nil {
+				log.WithError(err).WithField("chain", chain).Warn("failed to save backfill checkpoint")
+			}
+		}
 		domain := strings.TrimSpace(ai.AgentDomain)
 		if domain == "" {
 			log.Error("domain is empty string")
@@ -211,14 +333,93 @@ func (ix *Indexer) backfillAgents(ctx context.Context, chain string, client *eth
 			"chain":    chain,
 			"count":    total,
 		}).Info("storing card")
-		ix.fetchAndStoreCard(ctx, chain, idAddr.Hex(), ai.AgentId.Int64(), domain)
+		ix.fetchAndStoreCard(ctx, chain, idAddr.Hex(), ai.AgentId.Int64(), domain, ai.AgentAddress)
 	}
 }
 
+// checkpoint returns how far indexing of a registry got, or the zero Checkpoint if
+// it cannot be read.
+func (ix *Indexer) checkpoint(ctx context.Context, chain string, idAddr common.Address) store.Checkpoint {
+	cp, err := ix.store.GetCheckpoint(ctx, chain, idAddr.Hex())
+	if err != nil {
+		log.WithError(err).WithField("chain", chain).Warn("failed to read indexer checkpoint; starting fresh")
+	}
+	return cp
+}
+
+// logRangeBlocks is the widest block range asked of FilterLogs at once; many
+// providers reject larger ones.
+const logRangeBlocks = 2000
+
+// blockRanges splits [from, to] into inclusive ranges of at most size blocks.
+func blockRanges(from, to, size uint64) [][2]uint64 {
+	var out [][2]uint64
+	for from <= to {
+		end := to
+		if to-from >= size {
+			end = from + size - 1
+		}
+		out = append(out, [2]uint64{from, end})
+		from = end + 1
+	}
+	return out
+}
+
+// catchUp replays identity logs from the block after the checkpoint up to the
+// current head, so a restarted (or snapshot-restored) explorer misses nothing it
+// was offline for. It returns the first block not yet handled, or 0 when it could
+// not tell.
+func (ix *Indexer) catchUp(ctx context.Context, chain string, client *ethclient.Client, idAddr common.Address) uint64 {
+	began := time.Now()
+	latest, err := client.BlockNumber(ctx)
+	ix.observeRPC(chain, "eth_blockNumber", began, err)
+	if err != nil {
+		log.WithError(err).WithField("chain", chain).Warn("cannot get latest block to catch up")
+		return 0
+	}
+	cp := ix.checkpoint(ctx, chain, idAddr)
+	if cp.LastBlock == 0 {
+		// nothing indexed from logs yet: the backfill covers history, so start here
+		ix.saveBlock(ctx, chain, idAddr, latest)
+		return latest + 1
+	}
+	if cp.LastBlock >= latest {
+		return cp.LastBlock + 1
+	}
+	log.WithFields(log.Fields{
+		"chain": chain,
+		"from":  cp.LastBlock + 1,
+		"to":    latest,
+	}).Info("catching up identity logs from checkpoint")
+	for _, r := range blockRanges(cp.LastBlock+1, latest, logRangeBlocks) {
+		began := time.Now()
+		logs, err := client.FilterLogs(ctx, ethereum.FilterQuery{
+			Addresses: []common.Address{idAddr},
+			FromBlock: new(big.Int).SetUint64(r[0]),
+			ToBlock:   new(big.Int).SetUint64(r[1]),
+		})
+		ix.observeRPC(chain, "eth_getLogs", began, err)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{
+				"chain": chain, "from": r[0], "to": r[1],
+			}).Warn("catch-up: FilterLogs error")
+			return r[0]
+		}
+		for _, lg := range logs {
+			ix.handleIdentityLog(ctx, chain, lg)
+		}
+		ix.saveBlock(ctx, chain, idAddr, r[1])
+	}
+	return latest + 1
+}
+
 func (ix *Indexer) watchIdentity(ctx context.Context, chain string, client *ethclient.Client, idAddr common.Address) {
+	next := ix.catchUp(ctx, chain, client, idAddr)
 	q := ethereum.FilterQuery{Addresses: []common.Address{idAddr}}
 	logsCh := make(chan types.Log)
+	began := time.Now()
 	sub, err := client.SubscribeFilterLogs(ctx, q, logsCh)
+	metrics.ObserveRPC(chain, "eth_subscribe", began, err)
 	if err != nil {
 		// Infura HTTPS and some providers will return this
 		if strings.Contains(strings.ToLower(err.Error()), "notifications not supported") {
@@ -226,12 +427,15 @@ func (ix *Indexer) watchIdentity(ctx context.Context, chain string, client *ethc
 				"chain": chain,
 				"addr":  idAddr.Hex(),
 			}).Warn("provider does not support subscriptions; falling back to polling")
-			ix.pollIdentity(ctx, chain, client, idAddr) // blocking loop
+			ix.pollIdentity(ctx, chain, client, idAddr, next) // blocking loop
 			return
 		}
 		log.WithError(err).Error("failed to connect to the Ethereum Chain")
+		ix.chainError(chain, err)
+		ix.setMode(chain, ModeDown)
 		return
 	}
+	ix.setMode(chain, ModeSubscription)
 	for {
 		select {
 		case <-ctx.Done():
@@ -239,24 +443,36 @@ func (ix *Indexer) watchIdentity(ctx context.Context, chain string, client *ethc
 		case err := <-sub.Err():
 			if err != nil && !errors.Is(err, context.Canceled) {
 				log.WithError(err).Warn("subscription error; restarting watcher")
+				ix.chainError(chain, err)
+				ix.setMode(chain, ModeConnecting)
 				time.Sleep(3 * time.Second)
 				go ix.watchIdentity(ctx, chain, client, idAddr)
 			}
 			return
 		case lg := <-logsCh:
 			ix.handleIdentityLog(ctx, chain, lg)
+			// more logs of the same block may follow, so only the one before it is done
+			if lg.BlockNumber > 0 {
+				ix.saveBlock(ctx, chain, idAddr, lg.BlockNumber-1)
+			}
 		}
 	}
 }
 
-func (ix *Indexer) pollIdentity(ctx context.Context, chain string, client *ethclient.Client, idAddr common.Address) {
-	// Start from the latest block to avoid reprocessing large history
-	start, err := client.BlockNumber(ctx)
-	if err != nil {
-		log.WithError(err).WithField("chain", chain).Error("cannot get latest block for polling")
-		return
+// pollIdentity polls for identity logs from block from, or from the latest block
+// when from is 0.
+func (ix *Indexer) pollIdentity(ctx context.Context, chain string, client *ethclient.Client, idAddr common.Address, from uint64) {
+	if from == 0 {
+		began := time.Now()
+		start, err := client.BlockNumber(ctx)
+		ix.observeRPC(chain, "eth_blockNumber", began, err)
+		if err != nil {
+			log.WithError(err).WithField("chain", chain).Error("cannot get latest block for polling")
+			return
+		}
+		from = start
 	}
-	from := start // start at latest; adjust if you want history
+	ix.setMode(chain, ModePolling)
 	ticker := time.NewTicker(15 * time.Second)
 	defer ticker.Stop()
 
@@ -271,7 +487,9 @@ func (ix *Indexer) pollIdentity(ctx context.Context, chain string, client *ethcl
 		case <-ctx.Done():
 			return
 		case <-ticker.C:
+			began := time.Now()
 			latest, err := client.BlockNumber(ctx)
+			ix.observeRPC(chain, "eth_blockNumber", began, err)
 			if err != nil {
 				log.WithError(err).WithField("chain", chain).Warn("poll: failed to fetch latest block")
 				continue
@@ -290,7 +508,9 @@ func (ix *Indexer) pollIdentity(ctx context.Context, chain string, client *ethcl
 				FromBlock: new(big.Int).SetUint64(from),
 				ToBlock:   new(big.Int).SetUint64(latest),
 			}
+			began = time.Now()
 			logs, err := client.FilterLogs(ctx, q)
+			ix.observeRPC(chain, "eth_getLogs", began, err)
 			if err != nil {
 				log.WithError(err).WithFields(log.Fields{
 					"chain": chain, "from": from, "to": latest,
@@ -300,25 +520,31 @@ func (ix *Indexer) pollIdentity(ctx context.Context, chain string, client *ethcl
 			for _, lg := range logs {
 				ix.handleIdentityLog(ctx, chain, lg)
 			}
+			ix.saveBlock(ctx, chain, idAddr, latest)
 			from = latest + 1
 		}
 	}
 }
 
 func (ix *Indexer) handleIdentityLog(ctx context.Context, chain string, lg types.Log) {
+	ctx, span := tracer.Start(ctx, "indexer.handleIdentityLog", trace.WithAttributes(
+		attribute.String("chain", chain),
+		attribute.Int64("block", int64(lg.BlockNumber)),
+		attribute.String("tx", lg.TxHash.Hex()),
+	))
+	defer span.End()
+	ctx = logging.With(ctx, log.Fields{"chain": chain, "block": lg.BlockNumber, "tx_hash": lg.TxHash.Hex()})
 	// Try AgentRegistered / AgentUpdated
 	if len(lg.Topics) == 0 {
-		log.Error("Topics are zero")
+		logging.From(ctx).Error("Topics are zero")
 		return
 	}
 
-	log.WithFields(log.Fields{
-		"chain": chain,
-		"block": lg.BlockNumber,
-	}).Debug("log received")
+	logging.From(ctx).Debug("log received")
 
 	// v1 event
 	if evV1, ok := ix.idABI.Events["Registered"]; ok && lg.Topics[0] == evV1.ID {
+		metrics.IdentityEvents.WithLabelValues(chain, "registered_v1").Inc()
 		ix.handleRegistrationV1(ctx, chain, lg, evV1)
 		return
 	}
@@ -328,224 +554,256 @@ func (ix *Indexer) handleIdentityLog(ctx context.Context, chain string, lg types
 
 	switch lg.Topics[0] {
 	case evReg.ID:
+		metrics.IdentityEvents.WithLabelValues(chain, "registered").Inc()
 		if len(lg.Topics) < 2 {
-			log.Error("Number of topics is less thant two")
+			logging.From(ctx).Error("Number of topics is less thant two")
 			return
 		}
 		id := new(big.Int).SetBytes(lg.Topics[1].Bytes())
+		ctx = logging.With(ctx, log.Fields{"agent_id": id.Int64()})
 
-		log.WithFields(log.Fields{
-			"chain": chain,
-			"id":    id.String(),
-		}).Info("AgentRegistered event")
+		logging.From(ctx).Info("AgentRegistered event")
 
 		var data struct {
 			AgentDomain  string
 			AgentAddress common.Address
 		}
 		if err := ix.idABI.UnpackIntoInterface(&data, "AgentRegistered", lg.Data); err != nil {
-			log.WithError(err).Error("failed unpacking agent data from registered event")
+			logging.From(ctx).WithError(err).Error("failed unpacking agent data from registered event")
 			return
 		}
-		reg := ix.idents[chain].Hex()
-		log.WithFields(log.Fields{
-			"agent_id":   id.String(),
-			"chain":      chain,
-			"event_type": "registered",
-		}).Info("storing card")
-		ix.fetchAndStoreCard(ctx, chain, reg, id.Int64(), data.AgentDomain)
+		reg := ix.registryAddr(chain)
+		logging.From(ctx).WithField("event_type", "registered").Info("storing card")
+		ix.fetchAndStoreCard(ctx, chain, reg, id.Int64(), data.AgentDomain, data.AgentAddress)
+		ix.recordAgentEvent(ctx, chain, id.Int64(), evReg, lg)
 
 	case evUpd.ID:
+		metrics.IdentityEvents.WithLabelValues(chain, "updated").Inc()
 		if len(lg.Topics) < 2 {
-			log.Error("Number of topics is less thant two")
+			logging.From(ctx).Error("Number of topics is less thant two")
 			return
 		}
 		id := new(big.Int).SetBytes(lg.Topics[1].Bytes())
+		ctx = logging.With(ctx, log.Fields{"agent_id": id.Int64()})
 
-		log.WithFields(log.Fields{
-			"chain": chain,
-			"id":    id.String(),
-		}).Info("AgentUpdated event")
+		logging.From(ctx).Info("AgentUpdated event")
 
 		var data struct {
 			AgentDomain  string
 			AgentAddress common.Address
 		}
 		if err := ix.idABI.UnpackIntoInterface(&data, "AgentUpdated", lg.Data); err != nil {
-			log.WithError(err).Error("failed unpacking agent data from updating event")
+			logging.From(ctx).WithError(err).Error("failed unpacking agent data from updating event")
 			return
 		}
-		reg := ix.idents[chain].Hex()
-		log.WithFields(log.Fields{
-			"agent_id":   id.String(),
-			"chain":      chain,
-			"event_type": "updated",
-		}).Info("storing card")
-		ix.fetchAndStoreCard(ctx, chain, reg, id.Int64(), data.AgentDomain)
+		reg := ix.registryAddr(chain)
+		logging.From(ctx).WithField("event_type", "updated").Info("storing card")
+		ix.fetchAndStoreCard(ctx, chain, reg, id.Int64(), data.AgentDomain, data.AgentAddress)
+		ix.recordAgentEvent(ctx, chain, id.Int64(), evUpd, lg)
+
+	default:
+		metrics.IdentityEvents.WithLabelValues(chain, "unknown").Inc()
 	}
 }
 
 func (ix *Indexer) handleRegistrationV1(ctx context.Context, chain string, lg types.Log, ev abi.Event) {
 	// Topics: [signature, agentId (indexed), owner (indexed)]
 	if len(lg.Topics) < 3 {
-		log.Error("Registered v1: not enough topics")
+		logging.From(ctx).Error("Registered v1: not enough topics")
 		return
 	}
 	agentID := new(big.Int).SetBytes(lg.Topics[1].Bytes())
 	owner := common.BytesToAddress(lg.Topics[2].Bytes()[12:]) // right-padded 32 bytes
+	ctx = logging.With(ctx, log.Fields{"agent_id": agentID.Int64()})
 
 	// Data: NonIndexed = tokenURI (string)
 	nonargs := ev.Inputs.NonIndexed()
 	vals, err := abi.Arguments(nonargs).Unpack(lg.Data)
 	if err != nil {
-		log.WithError(err).Error("v1 Registered: unpack tokenURI failed")
+		logging.From(ctx).WithError(err).Error("v1 Registered: unpack tokenURI failed")
 		return
 	}
 	if len(vals) != 1 {
-		log.WithField("got", len(vals)).Error("v1 Registered: unexpected outputs arity")
+		logging.From(ctx).WithField("got", len(vals)).Error("v1 Registered: unexpected outputs arity")
 		return
 	}
 	tokenURI, _ := vals[0].(string)
 
-	log.WithFields(log.Fields{
-		"chain":    chain,
-		"agent_id": agentID.String(),
+	logging.From(ctx).WithFields(log.Fields{
 		"owner":    owner.Hex(),
 		"tokenURI": tokenURI,
 	}).Info("Registered v1 event")
 
+	if err := ix.indexRegistration(ctx, chain, agentID.Int64(), owner.Hex(), tokenURI); err != nil {
+		logging.From(ctx).WithError(err).WithField("tokenURI", tokenURI).Warn("registration not indexed")
+	}
+	ix.recordAgentEvent(ctx, chain, agentID.Int64(), ev, lg)
+}
+
+// indexRegistration fetches a v1 registration file and indexes everything it declares.
+func (ix *Indexer) indexRegistration(ctx context.Context, chain string, agentID int64, owner, tokenURI string) error {
+	ctx = logging.With(ctx, log.Fields{"chain": chain, "agent_id": agentID})
 	// 1) Fetch registration JSON
-	reg, err := ix.fetchJSON(ctx, tokenURI)
+	raw, res, err := ix.fetchJSON(ctx, tokenURI)
 	if err != nil {
-		log.WithError(err).WithField("tokenURI", tokenURI).Warn("registration fetch error")
-		return
+		return fmt.Errorf("registration fetch: %w", err)
 	}
 
-	// 2) Parse endpoints (A2A, MCP, DID)
-	a2aURL, mcpURL, did := extractEndpoints(reg)
-	if a2aURL == "" {
-		log.WithFields(log.Fields{
-			"agent_id": agentID.String(),
-			"chain":    chain,
-			"mcp":      mcpURL,
-			"did":      did,
-		}).Warn("v1 registration has no A2A endpoint; skipping card fetch")
-		return
-	}
+	// 2) Parse every declared endpoint (A2A, MCP, DID, wallets, ...)
+	reg := parseRegistration(tokenURI, owner, raw)
+	reg.Verified = res.Verified
+	registry := ix.registryAddr(chain)
 
-	// 3) Fetch agent card via existing path
-	registry := ix.idents[chain].Hex()
-	ix.fetchAndStoreCard(ctx, chain, registry, agentID.Int64(), a2aURL)
-}
-
-// Helper: fetch arbitrary JSON (supports http(s) and ipfs://)
-func (ix *Indexer) fetchJSON(ctx context.Context, uri string) (map[string]any, error) {
-	u := strings.TrimSpace(uri)
-	if u == "" {
-		return nil, fmt.Errorf("empty uri")
+	// 3) Fetch agent card via existing path when an A2A endpoint is declared
+	a2aURL := reg.Endpoint("A2A")
+	if a2aURL != "" {
+		ix.fetchAndStoreCard(ctx, chain, registry, agentID, a2aURL, common.Address{})
+	} else {
+		logging.From(ctx).WithFields(log.Fields{
+			"mcp": reg.Endpoint("MCP"),
+			"did": reg.Endpoint("DID"),
+		}).Info("v1 registration has no A2A endpoint; indexing from registration")
 	}
 
-	// Support ipfs://CID[/path]
-	if strings.HasPrefix(u, "ipfs://") {
-		// naive gateway transform; can be made configurable
-		u = "https://ipfs.io/ipfs/" + strings.TrimPrefix(u, "ipfs://")
+	// 4) Persist the registration itself; without an A2A card it doubles as the card
+	if err := ix.store.UpsertRegistration(ctx, chain, registry, agentID, registrationDomain(reg), reg, a2aURL == ""); err != nil {
+		return fmt.Errorf("upsert registration: %w", err)
+	}
+	// the owner from the Registered log is the agent's address, whatever the card says
+	if common.IsHexAddress(owner) {
+		if err := ix.store.SetAgentAddress(ctx, chain, agentID, caip.Account(chain, common.HexToAddress(owner))); err != nil {
+			return fmt.Errorf("set agent address: %w", err)
+		}
 	}
+	logging.From(ctx).WithField("endpoints", len(reg.Endpoints)).Info("registration stored")
 
-	// Validate URL
-	if _, err := url.Parse(u); err != nil {
-		return nil, fmt.Errorf("invalid uri: %w", err)
+	// 5) Introspect the MCP server so its tools become searchable
+	if mcpURL := reg.Endpoint("MCP"); mcpURL != "" {
+		ix.introspectMCP(ctx, chain, agentID, mcpURL)
 	}
+	return nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
-	if err != nil {
-		return nil, err
+// Helper: fetch arbitrary JSON through the URI resolver (http(s), ipfs://, ar://, data:)
+func (ix *Indexer) fetchJSON(ctx context.Context, uri string) (_ map[string]any, _ fetcher.Result, err error) {
+	ctx, span := tracer.Start(ctx, "indexer.fetchJSON", trace.WithAttributes(attribute.String("uri", uri)))
+	defer func() { tracing.End(span, err) }()
+	if ix.uris == nil {
+		ix.uris = fetcher.New(fetcher.DefaultConfig(), nil)
 	}
-	resp, err := http.DefaultClient.Do(req) // #nosec G107
+	res, err := ix.uris.Fetch(ctx, uri)
 	if err != nil {
-		return nil, err
+		return nil, fetcher.Result{}, err
 	}
-	defer resp.Body.Close()
 
 	var out map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, err
+	if err := json.Unmarshal(res.Body, &out); err != nil {
+		return nil, fetcher.Result{}, err
 	}
-	return out, nil
+	return out, res, nil
 }
 
-// Helper: extract A2A, MCP, DID from a v1 registration JSON
-func extractEndpoints(reg map[string]any) (a2a, mcp, did string) {
-	eps, ok := reg["endpoints"]
-	if !ok {
-		return "", "", ""
+func (ix *Indexer) fetchAndStoreCard(ctx context.Context, chain string, registryAddr string, agentID int64, domain string, agentAddr common.Address) {
+	if err := ix.storeCard(ctx, chain, registryAddr, agentID, domain, agentAddr); err != nil {
+		logging.From(ctx).WithError(err).WithFields(log.Fields{"chain": chain, "agent_id": agentID, "domain": domain}).Warn("card not stored")
 	}
-	arr, ok := eps.([]interface{})
-	if !ok {
-		return "", "", ""
+}
+
+// cardError is a card fetch failure with the outcome it is counted under.
+type cardError struct {
+	class string
+	err   error
+}
+
+func (e *cardError) Error() string { return e.err.Error() }
+func (e *cardError) Unwrap() error { return e.err }
+
+// cardOutcome is the card_fetches_total outcome of a storeCard result.
+func cardOutcome(err error) string {
+	var ce *cardError
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.As(err, &ce):
+		return ce.class
+	}
+	return "other"
+}
+
+// storeCard fetches the agent card served for domain and upserts the agent from it.
+// A non-zero agentAddr is the on-chain AgentAddress and becomes the agent's address
+// whatever the card declares.
+func (ix *Indexer) storeCard(ctx context.Context, chain string, registryAddr string, agentID int64, domain string, agentAddr common.Address) (err error) {
+	ctx, span := tracer.Start(ctx, "indexer.fetchAndStoreCard", trace.WithAttributes(
+		attribute.String("chain", chain),
+		attribute.Int64("agent_id", agentID),
+		attribute.String("domain", domain),
+	))
+	defer func() {
+		metrics.CardFetches.WithLabelValues(chain, cardOutcome(err)).Inc()
+		span.SetAttributes(attribute.String("outcome", cardOutcome(err)))
+		tracing.End(span, err)
+	}()
+	ctx = logging.With(ctx, log.Fields{"chain": chain, "agent_id": agentID})
+	d := strings.TrimSpace(domain)
+	card, err := FetchCard(ctx, d)
+	if err != nil {
+		return err
 	}
-	for _, raw := range arr {
-		m, ok := raw.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		name, _ := m["name"].(string)
-		endpoint, _ := m["endpoint"].(string)
 
-		switch strings.ToUpper(strings.TrimSpace(name)) {
-		case "A2A":
-			a2a = endpoint
-		case "MCP":
-			mcp = endpoint
-		case "DID":
-			did = endpoint
+	if err := ix.store.UpsertAgentFromCard(ctx, chain, registryAddr, agentID, d, card); err != nil {
+		return &cardError{"store", fmt.Errorf("upsert agent: %w", err)}
+	}
+	if agentAddr != (common.Address{}) {
+		if err := ix.store.SetAgentAddress(ctx, chain, agentID, caip.Account(chain, agentAddr)); err != nil {
+			return &cardError{"store", fmt.Errorf("set agent address: %w", err)}
 		}
 	}
-	return
+	logging.From(ctx).WithField("domain", d).Info("card stored")
+	return nil
 }
 
-func (ix *Indexer) fetchAndStoreCard(ctx context.Context, chain string, registryAddr string, agentID int64, domain string) {
+// Card fetches are bounded: a slow or oversized card must not hold up the indexer
+// or a /resolve request.
+const (
+	cardFetchTimeout = 10 * time.Second
+	maxCardBytes     = 1 << 20
+)
+
+var cardClient = &http.Client{Timeout: cardFetchTimeout}
+
+// FetchCard fetches the agent card served for domain (a host, or a URL the card
+// lives under). Errors are *cardError, classed for card_fetches_total; a non-2xx
+// reply is a "status" error whatever its body.
+func FetchCard(ctx context.Context, domain string) (map[string]any, error) {
 	d := strings.TrimSpace(domain)
 	if d == "" {
-		log.Error("Domain is zero")
-		return
+		return nil, &cardError{"invalid", errors.New("empty domain")}
 	}
 	// heuristic: build .well-known URL if needed
-	url := d
-	if !strings.HasPrefix(d, "http://") && !strings.HasPrefix(d, "https://") {
-		url = fmt.Sprintf("http://%s/.well-known/agent-card.json", d)
-	} else if !strings.Contains(d, "/.well-known/agent-card.json") {
-		url = strings.TrimRight(d, "/") + "/.well-known/agent-card.json"
-	}
+	url := fetcher.CardURL(d)
 
-	log.WithFields(log.Fields{
-		"chain":   chain,
-		"agentID": agentID,
-		"url":     url,
-	}).Info("fetching agent card")
+	logging.From(ctx).WithField("url", url).Info("fetching agent card")
 
-	resp, err := http.Get(url) // #nosec G107
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		log.WithError(err).WithField("url", url).Warn("card fetch error")
-		return
+		return nil, &cardError{"invalid", err}
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	resp, err := cardClient.Do(req) // #nosec G107
+	if err != nil {
+		return nil, &cardError{metrics.NetClass(err), fmt.Errorf("card fetch: %w", err)}
 	}
 	defer resp.Body.Close()
-
-	var card map[string]any
-	if err := json.NewDecoder(resp.Body).Decode(&card); err != nil {
-		log.WithError(err).WithField("url", url).Warn("card decode error")
-		return
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &cardError{"status", fmt.Errorf("card fetch %s: HTTP %d", url, resp.StatusCode)}
 	}
 
-	err = ix.store.UpsertAgentFromCard(ctx, chain, registryAddr, agentID, d, card)
-	if err != nil {
-		log.WithError(err).WithField("agentID", agentID).Error("failed upserting agent from card")
-		return
+	var card map[string]any
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxCardBytes)).Decode(&card); err != nil {
+		return nil, &cardError{"decode", fmt.Errorf("card decode %s: %w", url, err)}
 	}
-	log.WithFields(log.Fields{
-		"chain":   chain,
-		"agentID": agentID,
-		"domain":  d,
-	}).Info("card stored")
+	return card, nil
 }
 
 // upgradeZeroIDs resolves agentId on-chain for domains saved with placeholder agent_id=0
@@ -553,19 +811,9 @@ func (ix *Indexer) upgradeZeroIDs(ctx context.Context) {
 	for _, n := range ix.nets {
 		log.WithField("chain", n.Name).Info("checking zero-id agents")
 
-		client := ix.clients[n.Name]
-		if client == nil {
-			c, err := ethclient.Dial(os.ExpandEnv(n.RPC))
-			if err != nil {
-				continue
-			}
-			ix.clients[n.Name] = c
-			client = c
-		}
-		idAddr, ok := ix.idents[n.Name]
-		if !ok || (idAddr == common.Address{}) {
-			ix.idents[n.Name] = common.HexToAddress(n.Identity)
-			idAddr = ix.idents[n.Name]
+		client, idAddr, err := ix.chainClient(n.Name)
+		if err != nil {
+			continue
 		}
 
 		domains, err := ix.store.ListZeroIDAgents(ctx, n.Name, 200)
@@ -583,7 +831,7 @@ func (ix *Indexer) upgradeZeroIDs(ctx context.Context) {
 			if err != nil || ai.AgentId == nil || ai.AgentId.Int64() == 0 {
 				continue
 			}
-			ix.fetchAndStoreCard(ctx, n.Name, idAddr.Hex(), ai.AgentId.Int64(), d)
+			ix.fetchAndStoreCard(ctx, n.Name, idAddr.Hex(), ai.AgentId.Int64(), d, ai.AgentAddress)
 			_ = ix.store.DeleteAgent(ctx, n.Name, 0)
 		}
 	}
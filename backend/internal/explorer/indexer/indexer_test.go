@@ -5,7 +5,10 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -18,6 +21,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 	erc "github.com/praxis/praxis-explorer/internal/erc8004"
+	"github.com/praxis/praxis-explorer/internal/explorer/metrics"
 )
 
 // helper: compute topic for uint256 id
@@ -249,3 +253,168 @@ func randomHash(s string) string {
 	sum := sha256.Sum256([]byte(s))
 	return hex.EncodeToString(sum[:])
 }
+
+func TestBlockRanges(t *testing.T) {
+	cases := []struct {
+		from, to, size uint64
+		want           [][2]uint64
+	}{
+		{10, 10, 5, [][2]uint64{{10, 10}}},
+		{1, 5, 5, [][2]uint64{{1, 5}}},
+		{1, 12, 5, [][2]uint64{{1, 5}, {6, 10}, {11, 12}}},
+		{7, 6, 5, nil},
+	}
+	for _, c := range cases {
+		got := blockRanges(c.from, c.to, c.size)
+		if len(got) != len(c.want) {
+			t.Errorf("blockRanges(%d, %d, %d) = %v, want %v", c.from, c.to, c.size, got, c.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Errorf("blockRanges(%d, %d, %d) = %v, want %v", c.from, c.to, c.size, got, c.want)
+				break
+			}
+		}
+	}
+}
+
+func TestCardOutcome(t *testing.T) {
+	dnsErr := &net.DNSError{Err: "no such host", Name: "agent.invalid"}
+	cases := map[string]error{
+		"ok":      nil,
+		"decode":  &cardError{"decode", errors.New("bad json")},
+		"dns":     &cardError{metrics.NetClass(fmt.Errorf("dial: %w", dnsErr)), dnsErr},
+		"timeout": &cardError{metrics.NetClass(context.DeadlineExceeded), context.DeadlineExceeded},
+		"network": &cardError{metrics.NetClass(errors.New("connection refused")), errors.New("connection refused")},
+		"store":   fmt.Errorf("refresh: %w", &cardError{"store", errors.New("down")}),
+		"other":   errors.New("unclassified"),
+	}
+	for want, err := range cases {
+		if got := cardOutcome(err); got != want {
+			t.Errorf("cardOutcome(%v) = %q, want %q", err, got, want)
+		}
+	}
+}
+
+func TestStatus(t *testing.T) {
+	ix := &Indexer{
+		nets:  []Chain{{Name: "sepolia"}, {Name: "base"}, {Name: "mainnet"}},
+		seeds: []string{"a.example"},
+	}
+	ix.setMode("sepolia", ModePolling)
+	ix.setProgress("sepolia", 120, 100)
+	ix.setProgress("sepolia", 0, 90) // checkpoints never move back
+	ix.setMode("base", ModeDown)
+	ix.chainError("base", errors.New("dial tcp: connection refused"))
+
+	st, err := ix.Status(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(st.Chains) != 3 || st.Seeds.Domains != 1 {
+		t.Fatalf("status = %+v", st)
+	}
+	sep, base, main := st.Chains[0], st.Chains[1], st.Chains[2]
+	if sep.Mode != ModePolling || sep.Head != 120 || sep.LastBlock != 100 || sep.HeadLag != 20 {
+		t.Errorf("sepolia = %+v", sep)
+	}
+	if base.Mode != ModeDown || base.LastError == "" || base.LastErrorAt == nil {
+		t.Errorf("base = %+v", base)
+	}
+	if main.Chain != "mainnet" || main.Mode != ModeDisabled {
+		t.Errorf("unwatched chain = %+v", main)
+	}
+}
+
+func TestDecodeEventArgs(t *testing.T) {
+	parsed, err := abi.JSON(strings.NewReader(erc.IdentityABI()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := parsed.Events["Registered"]
+	owner := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	data, err := ev.Inputs.NonIndexed().Pack("ipfs://bafy/agent.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	lg := types.Log{
+		Topics: []common.Hash{ev.ID, topicForUint256(id), common.BytesToHash(owner.Bytes())},
+		Data:   data,
+	}
+
+	args, err := decodeEventArgs(ev, lg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]any{"agentId": id.String(), "owner": owner.Hex(), "tokenURI": "ipfs://bafy/agent.json"}
+	if fmt.Sprint(args) != fmt.Sprint(want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+	if _, err := json.Marshal(args); err != nil {
+		t.Errorf("args do not marshal: %v", err)
+	}
+
+	lg.Topics = lg.Topics[:2]
+	if _, err := decodeEventArgs(ev, lg); err == nil {
+		t.Error("missing indexed topic was not reported")
+	}
+}
+
+func TestNetworkChainID(t *testing.T) {
+	cases := []struct {
+		name     string
+		explicit uint64
+		want     uint64
+		wantErr  bool
+	}{
+		{"sepolia", 0, 11155111, false},
+		{"Base-Sepolia", 0, 84532, false},
+		{"eip155:8453", 0, 8453, false},
+		{"devnet", 31337, 31337, false},
+		{"devnet", 0, 0, true},
+		{"eip155:8453", 1, 0, true},
+	}
+	for _, c := range cases {
+		got, err := networkChainID(c.name, c.explicit)
+		if (err != nil) != c.wantErr || got != c.want {
+			t.Errorf("networkChainID(%q, %d) = %d, %v", c.name, c.explicit, got, err)
+		}
+	}
+}
+
+func TestFetchCardChecksStatusAndSize(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/down/") {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_, _ = w.Write([]byte(`{"name":"ok"}`))
+	}))
+	defer srv.Close()
+
+	card, err := FetchCard(context.Background(), srv.URL)
+	if err != nil || card["name"] != "ok" {
+		t.Fatalf("FetchCard = %v, %v", card, err)
+	}
+
+	// a 5xx with a JSON body is not the agent's card
+	_, err = FetchCard(context.Background(), srv.URL+"/down")
+	var ce *cardError
+	if !errors.As(err, &ce) || ce.class != "status" {
+		t.Fatalf("5xx card: err = %v, want a status error", err)
+	}
+}
+
+func TestFetchCardLimitsBody(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"` + strings.Repeat("x", maxCardBytes) + `"}`))
+	}))
+	defer srv.Close()
+
+	_, err := FetchCard(context.Background(), srv.URL)
+	var ce *cardError
+	if !errors.As(err, &ce) || ce.class != "decode" {
+		t.Fatalf("oversized card: err = %v, want a decode error", err)
+	}
+}
@@ -0,0 +1,89 @@
+package indexer
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/mcp"
+	log "github.com/sirupsen/logrus"
+)
+
+// MCPConfig controls introspection of agents' MCP endpoints. Catalogs are fetched
+// when a registration is indexed and refreshed every Interval (zero disables the refresh).
+type MCPConfig struct {
+	Interval time.Duration `yaml:"interval"`
+	Timeout  time.Duration `yaml:"timeout"`
+}
+
+// mcpStoreTimeout bounds storing an introspection's outcome, which runs after the
+// introspection timeout may have expired.
+const mcpStoreTimeout = 10 * time.Second
+
+func defaultMCPConfig() MCPConfig {
+	return MCPConfig{Interval: 6 * time.Hour, Timeout: 20 * time.Second}
+}
+
+// runMCPRefresher periodically re-introspects every agent that declares an MCP endpoint.
+func (ix *Indexer) runMCPRefresher(ctx context.Context) {
+	if ix.mcp.Interval <= 0 {
+		return
+	}
+	log.WithField("interval", ix.mcp.Interval).Info("[mcp] starting catalog refresher")
+
+	t := time.NewTicker(ix.mcp.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+		targets, err := ix.store.ListProbeTargets(ctx)
+		if err != nil {
+			log.WithError(err).Warn("[mcp] failed listing agents")
+			continue
+		}
+		for _, tg := range targets {
+			if ctx.Err() != nil {
+				return
+			}
+			if tg.MCPURL != "" {
+				ix.introspectMCP(ctx, tg.ChainID, tg.AgentID, tg.MCPURL)
+			}
+		}
+	}
+}
+
+// introspectMCP runs the MCP handshake against endpoint and stores the advertised
+// tools, resources and prompts. Failures are recorded but keep the previous catalog.
+func (ix *Indexer) introspectMCP(ctx context.Context, chain string, agentID int64, endpoint string) {
+	timeout := ix.mcp.Timeout
+	if timeout <= 0 {
+		timeout = defaultMCPConfig().Timeout
+	}
+	introspectCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	// the outcome is stored even when the handshake used up its timeout, which is
+	// how most introspections fail
+	storeCtx, cancelStore := context.WithTimeout(context.WithoutCancel(ctx), mcpStoreTimeout)
+	defer cancelStore()
+
+	fields := log.Fields{"chain": chain, "agentID": agentID, "mcp": endpoint}
+	cat, err := mcp.Introspect(introspectCtx, &http.Client{Timeout: timeout}, endpoint)
+	if err != nil {
+		log.WithError(err).WithFields(fields).Warn("[mcp] introspection failed")
+		if err := ix.store.RecordMCPError(storeCtx, chain, agentID, endpoint, err.Error()); err != nil {
+			log.WithError(err).WithFields(fields).Warn("[mcp] failed recording introspection error")
+		}
+		return
+	}
+	if err := ix.store.SaveMCPCatalog(storeCtx, chain, agentID, cat); err != nil {
+		log.WithError(err).WithFields(fields).Error("[mcp] failed storing catalog")
+		return
+	}
+	fields["tools"] = len(cat.Tools)
+	fields["resources"] = len(cat.Resources)
+	fields["prompts"] = len(cat.Prompts)
+	log.WithFields(fields).Info("[mcp] catalog stored")
+}
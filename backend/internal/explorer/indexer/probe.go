@@ -0,0 +1,316 @@
+package indexer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/fetcher"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// ProbeConfig controls the background liveness prober. A zero Interval disables it.
+type ProbeConfig struct {
+	Interval    time.Duration `yaml:"interval"`
+	Timeout     time.Duration `yaml:"timeout"`
+	Concurrency int           `yaml:"concurrency"`
+	Window      time.Duration `yaml:"window"`    // uptime and latency percentiles are computed over this window
+	Retention   time.Duration `yaml:"retention"` // probe rows and stream events older than this are pruned
+}
+
+func defaultProbeConfig() ProbeConfig {
+	return ProbeConfig{
+		Interval:    5 * time.Minute,
+		Timeout:     10 * time.Second,
+		Concurrency: 8,
+		Window:      24 * time.Hour,
+		Retention:   7 * 24 * time.Hour,
+	}
+}
+
+// runProber periodically probes every indexed agent's card, A2A and MCP endpoints.
+func (ix *Indexer) runProber(ctx context.Context) {
+	if ix.probes.Interval <= 0 {
+		return
+	}
+	log.WithField("interval", ix.probes.Interval).Info("[prober] starting")
+
+	t := time.NewTicker(ix.probes.Interval)
+	defer t.Stop()
+	for {
+		ix.probeAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (ix *Indexer) probeAll(ctx context.Context) {
+	targets, err := ix.store.ListProbeTargets(ctx)
+	if err != nil {
+		log.WithError(err).Warn("[prober] failed listing targets")
+		return
+	}
+
+	conc := ix.probes.Concurrency
+	if conc <= 0 {
+		conc = 1
+	}
+	sem := make(chan struct{}, conc)
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		if ctx.Err() != nil {
+			break
+		}
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(t store.ProbeTarget) {
+			defer func() { <-sem; wg.Done() }()
+			results := ix.probeAgent(ctx, t)
+			if len(results) == 0 {
+				return
+			}
+			status := probeStatus(results)
+			if err := ix.store.RecordProbes(ctx, t.ChainID, t.AgentID, results, status, ix.probes.Window); err != nil {
+				log.WithError(err).WithFields(log.Fields{"chain": t.ChainID, "agentID": t.AgentID}).Warn("[prober] failed recording probes")
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	if ix.probes.Retention > 0 {
+		if err := ix.store.PruneProbes(ctx, ix.probes.Retention); err != nil {
+			log.WithError(err).Warn("[prober] failed pruning probes")
+		}
+		if err := ix.store.PruneEvents(ctx, ix.probes.Retention); err != nil {
+			log.WithError(err).Warn("[prober] failed pruning events")
+		}
+	}
+	log.WithField("agents", len(targets)).Info("[prober] round complete")
+}
+
+// probeAgent checks each endpoint the agent is known to expose.
+func (ix *Indexer) probeAgent(ctx context.Context, t store.ProbeTarget) []store.ProbeResult {
+	var out []store.ProbeResult
+	if t.HasA2A {
+		out = append(out, ix.probe(ctx, store.ProbeCard, fetcher.CardURL(t.Domain), nil, checkCard))
+	}
+	if t.A2AURL != "" {
+		ping := []byte(`{"jsonrpc":"2.0","id":"praxis-probe","method":"tasks/get","params":{"id":"praxis-probe"}}`)
+		out = append(out, ix.probe(ctx, store.ProbeA2A, t.A2AURL, ping, checkJSONRPC))
+	}
+	if t.MCPURL != "" {
+		initReq := []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"praxis-explorer-probe","version":"1.0.0"}}}`)
+		out = append(out, ix.probe(ctx, store.ProbeMCP, t.MCPURL, initReq, checkMCPInitialize))
+	}
+	return out
+}
+
+// probeStatus: up when every probe handshook, down when none did, degraded otherwise.
+func probeStatus(results []store.ProbeResult) string {
+	ok := 0
+	for _, r := range results {
+		if r.HandshakeOK {
+			ok++
+		}
+	}
+	switch {
+	case ok == len(results):
+		return store.StatusUp
+	case ok == 0:
+		return store.StatusDown
+	default:
+		return store.StatusDegraded
+	}
+}
+
+// probe issues a GET (or a JSON POST when body is set) and runs check on the response.
+// Certificates are not enforced by the transport so that latency and handshake are
+// still recorded for misconfigured TLS; validity is checked separately.
+func (ix *Indexer) probe(ctx context.Context, kind, url string, body []byte, check func(*http.Response, []byte) error) store.ProbeResult {
+	res := store.ProbeResult{Kind: kind, URL: url, ProbedAt: time.Now().UTC()}
+
+	timeout := ix.probes.Timeout
+	if timeout <= 0 {
+		timeout = defaultProbeConfig().Timeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method := http.MethodGet
+	var rd io.Reader
+	if body != nil {
+		method = http.MethodPost
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, rd)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/json, text/event-stream")
+	}
+
+	start := time.Now()
+	resp, err := ix.probeHTTPClient().Do(req) // #nosec G107
+	if err != nil {
+		res.LatencyMs = msSince(start)
+		res.Error = err.Error()
+		return res
+	}
+	defer resp.Body.Close()
+	payload, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	res.LatencyMs = msSince(start)
+	res.HTTPStatus = resp.StatusCode
+	if resp.TLS != nil {
+		valid := verifyTLS(resp.TLS, resp.Request.URL.Hostname())
+		res.TLSValid = &valid
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	if sid := resp.Header.Get("Mcp-Session-Id"); sid != "" && kind == store.ProbeMCP {
+		ix.closeMCPSession(url, sid)
+	}
+
+	if err := check(resp, payload); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.HandshakeOK = true
+	return res
+}
+
+func (ix *Indexer) probeHTTPClient() *http.Client {
+	ix.probeOnce.Do(func() {
+		if ix.probeClient != nil {
+			return
+		}
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402 -- validity is verified and recorded per probe
+		ix.probeClient = &http.Client{Transport: tr}
+	})
+	return ix.probeClient
+}
+
+// closeMCPSession terminates the session opened by the initialize probe (best effort).
+func (ix *Indexer) closeMCPSession(url, sid string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, url, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("Mcp-Session-Id", sid)
+	if resp, err := ix.probeHTTPClient().Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func verifyTLS(cs *tls.ConnectionState, host string) bool {
+	if len(cs.PeerCertificates) == 0 {
+		return false
+	}
+	opts := x509.VerifyOptions{DNSName: host, Intermediates: x509.NewCertPool()}
+	for _, c := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err == nil
+}
+
+func msSince(t time.Time) float64 {
+	return float64(time.Since(t).Microseconds()) / 1000
+}
+
+func checkCard(resp *http.Response, body []byte) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	var card map[string]any
+	if err := json.Unmarshal(body, &card); err != nil {
+		return fmt.Errorf("card decode: %w", err)
+	}
+	if name, _ := card["name"].(string); strings.TrimSpace(name) == "" {
+		return fmt.Errorf("card has no name")
+	}
+	return nil
+}
+
+// checkJSONRPC accepts any well-formed JSON-RPC 2.0 response, including errors: an
+// agent answering "task not found" to our ping is alive and speaking the protocol.
+func checkJSONRPC(resp *http.Response, body []byte) error {
+	msg, err := decodeRPCResponse(resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		return err
+	}
+	if v, _ := msg["jsonrpc"].(string); v != "2.0" {
+		return fmt.Errorf("not a JSON-RPC 2.0 response")
+	}
+	_, hasResult := msg["result"]
+	_, hasError := msg["error"]
+	if !hasResult && !hasError {
+		return fmt.Errorf("JSON-RPC response has neither result nor error")
+	}
+	return nil
+}
+
+func checkMCPInitialize(resp *http.Response, body []byte) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	msg, err := decodeRPCResponse(resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		return err
+	}
+	if e, ok := msg["error"].(map[string]any); ok {
+		return fmt.Errorf("initialize error: %v", e["message"])
+	}
+	result, _ := msg["result"].(map[string]any)
+	if v, _ := result["protocolVersion"].(string); v == "" {
+		return fmt.Errorf("initialize result has no protocolVersion")
+	}
+	return nil
+}
+
+// decodeRPCResponse reads a JSON-RPC message from a plain JSON body or from the
+// first data event of a text/event-stream body.
+func decodeRPCResponse(contentType string, body []byte) (map[string]any, error) {
+	if strings.HasPrefix(strings.ToLower(contentType), "text/event-stream") {
+		sc := bufio.NewScanner(bytes.NewReader(body))
+		sc.Buffer(make([]byte, 0, 64*1024), 1<<20)
+		for sc.Scan() {
+			line := sc.Text()
+			if !strings.HasPrefix(line, "data:") {
+				continue
+			}
+			var msg map[string]any
+			if err := json.Unmarshal([]byte(strings.TrimSpace(strings.TrimPrefix(line, "data:"))), &msg); err != nil {
+				return nil, fmt.Errorf("sse data decode: %w", err)
+			}
+			return msg, nil
+		}
+		return nil, fmt.Errorf("event stream carried no data")
+	}
+	var msg map[string]any
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("json-rpc decode: %w", err)
+	}
+	return msg, nil
+}
@@ -0,0 +1,101 @@
+package indexer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+func TestProbeAgent_RecordsHandshakesAndTLSValidity(t *testing.T) {
+	var mcpSessionClosed atomic.Bool
+	// TLS server with a self-signed certificate: reachable, but the cert is not trusted
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/.well-known/agent-card.json":
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "probe-agent", "url": "https://" + r.Host + "/a2a"})
+		case r.URL.Path == "/a2a" && r.Method == http.MethodPost:
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":"praxis-probe","error":{"code":-32001,"message":"Task not found"}}`))
+		case r.URL.Path == "/mcp" && r.Method == http.MethodPost:
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Mcp-Session-Id", "sess-1")
+			_, _ = fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"id\":1,\"result\":{\"protocolVersion\":\"2025-06-18\",\"capabilities\":{}}}\n\n")
+		case r.URL.Path == "/mcp" && r.Method == http.MethodDelete:
+			mcpSessionClosed.Store(r.Header.Get("Mcp-Session-Id") == "sess-1")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	ix := &Indexer{probes: ProbeConfig{Timeout: 2 * time.Second}}
+	results := ix.probeAgent(context.Background(), store.ProbeTarget{
+		ChainID: "sepolia",
+		AgentID: 1,
+		Domain:  srv.URL,
+		HasA2A:  true,
+		A2AURL:  srv.URL + "/a2a",
+		MCPURL:  srv.URL + "/mcp",
+	})
+
+	if len(results) != 3 {
+		t.Fatalf("expected card, a2a and mcp probes, got %d", len(results))
+	}
+	for _, r := range results {
+		if !r.HandshakeOK {
+			t.Fatalf("%s probe failed: %+v", r.Kind, r)
+		}
+		if r.HTTPStatus != http.StatusOK {
+			t.Fatalf("%s probe status %d", r.Kind, r.HTTPStatus)
+		}
+		if r.TLSValid == nil || *r.TLSValid {
+			t.Fatalf("%s probe should flag the self-signed certificate: %+v", r.Kind, r.TLSValid)
+		}
+		if r.LatencyMs <= 0 {
+			t.Fatalf("%s probe latency not recorded", r.Kind)
+		}
+	}
+	if got := probeStatus(results); got != store.StatusUp {
+		t.Fatalf("expected status up, got %s", got)
+	}
+	if !mcpSessionClosed.Load() {
+		t.Fatal("expected the MCP session opened by the probe to be closed")
+	}
+}
+
+func TestProbeAgent_DownAndDegraded(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/.well-known/agent-card.json") {
+			_ = json.NewEncoder(w).Encode(map[string]any{"name": "half-up"})
+			return
+		}
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	ix := &Indexer{probes: ProbeConfig{Timeout: 2 * time.Second}}
+	results := ix.probeAgent(context.Background(), store.ProbeTarget{Domain: srv.URL, HasA2A: true, MCPURL: srv.URL + "/mcp"})
+	if len(results) != 2 {
+		t.Fatalf("expected 2 probes, got %d", len(results))
+	}
+	if results[0].TLSValid != nil {
+		t.Fatal("plain http must not report TLS validity")
+	}
+	if got := probeStatus(results); got != store.StatusDegraded {
+		t.Fatalf("expected degraded, got %s", got)
+	}
+
+	srv.Close()
+	results = ix.probeAgent(context.Background(), store.ProbeTarget{Domain: srv.URL, HasA2A: true})
+	if got := probeStatus(results); got != store.StatusDown || results[0].Error == "" {
+		t.Fatalf("expected down with error, got %s %+v", got, results[0])
+	}
+}
@@ -0,0 +1,152 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v5"
+	erc "github.com/praxis/praxis-explorer/internal/erc8004"
+	log "github.com/sirupsen/logrus"
+)
+
+// ErrUnknownAgent is returned when an agent is neither indexed nor resolvable on-chain.
+var ErrUnknownAgent = errors.New("unknown agent")
+
+// registryAddr is the identity registry address configured for chain.
+func (ix *Indexer) registryAddr(chain string) string {
+	ix.chainMu.RLock()
+	defer ix.chainMu.RUnlock()
+	return ix.idents[chain].Hex()
+}
+
+// chainClient returns the RPC client and identity registry of a configured chain,
+// dialing on first use.
+func (ix *Indexer) chainClient(chain string) (*ethclient.Client, common.Address, error) {
+	ix.chainMu.RLock()
+	client, idAddr := ix.clients[chain], ix.idents[chain]
+	ix.chainMu.RUnlock()
+	if client != nil && idAddr != (common.Address{}) {
+		return client, idAddr, nil
+	}
+
+	for _, n := range ix.nets {
+		if n.Name != chain {
+			continue
+		}
+		if strings.TrimSpace(n.RPC) == "" || strings.TrimSpace(n.Identity) == "" {
+			return nil, common.Address{}, fmt.Errorf("chain %s has no rpc or identity registry", chain)
+		}
+		ix.chainMu.Lock()
+		defer ix.chainMu.Unlock()
+		if ix.clients[chain] == nil {
+			c, err := ix.dialChain(context.Background(), n)
+			if err != nil {
+				return nil, common.Address{}, err
+			}
+			ix.clients[chain] = c
+		}
+		if ix.idents[chain] == (common.Address{}) {
+			ix.idents[chain] = common.HexToAddress(n.Identity)
+		}
+		return ix.clients[chain], ix.idents[chain], nil
+	}
+	return nil, common.Address{}, fmt.Errorf("chain %s is not configured", chain)
+}
+
+// onchainAgent reads the agent's current (v0) registry entry, if the chain is reachable.
+func (ix *Indexer) onchainAgent(ctx context.Context, chain string, agentID int64) (erc.AgentInfo, common.Address, error) {
+	client, idAddr, err := ix.chainClient(chain)
+	if err != nil {
+		return erc.AgentInfo{}, idAddr, err
+	}
+	ident, err := erc.NewIdentity(idAddr, client)
+	if err != nil {
+		return erc.AgentInfo{}, idAddr, err
+	}
+	began := time.Now()
+	ai, err := ident.GetAgent(ctx, &bind.CallOpts{Context: ctx}, big.NewInt(agentID))
+	ix.observeRPC(chain, "getAgent", began, err)
+	return ai, idAddr, err
+}
+
+// RefreshAgent re-indexes one agent through the same path chain events take: v1
+// agents have their registration file re-fetched from the stored tokenURI, others
+// have their card re-fetched from the on-chain domain (or the stored one when the
+// chain can't be reached).
+func (ix *Indexer) RefreshAgent(ctx context.Context, chain string, agentID int64) error {
+	id := strconv.FormatInt(agentID, 10)
+
+	reg, err := ix.store.GetRegistration(ctx, chain, id)
+	switch {
+	case err == nil && reg.TokenURI != "":
+		return ix.indexRegistration(ctx, chain, agentID, reg.Owner, reg.TokenURI)
+	case err != nil && !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+
+	domain, registry, addr := "", "", common.Address{}
+	if stored, err := ix.store.GetAgent(ctx, chain, id); err == nil {
+		domain, registry = stored.Domain, stored.RegistryAddr
+	}
+	if ai, idAddr, err := ix.onchainAgent(ctx, chain, agentID); err == nil && strings.TrimSpace(ai.AgentDomain) != "" {
+		domain, registry, addr = ai.AgentDomain, idAddr.Hex(), ai.AgentAddress
+	} else if err != nil {
+		log.WithError(err).WithFields(log.Fields{"chain": chain, "agentID": agentID}).Debug("on-chain lookup unavailable; using stored domain")
+	}
+	if domain == "" {
+		return ErrUnknownAgent
+	}
+	return ix.storeCard(ctx, chain, registry, agentID, domain, addr)
+}
+
+// RefreshFromDomain fetches the card served for domain and stores it as agentID.
+func (ix *Indexer) RefreshFromDomain(ctx context.Context, chain, registryAddr string, agentID int64, domain string) error {
+	if err := ix.storeCard(ctx, chain, registryAddr, agentID, domain, common.Address{}); err != nil {
+		return err
+	}
+	// best-effort: remove placeholder row with agent_id=0
+	_ = ix.store.DeleteAgent(ctx, chain, 0)
+	return nil
+}
+
+// IsAgentSigner reports whether addr may act for the agent: its current on-chain
+// AgentAddress or its v1 owner. Only when the registry cannot be read does the
+// address recorded on-chain at indexing time stand in for the AgentAddress.
+func (ix *Indexer) IsAgentSigner(ctx context.Context, chain string, agentID int64, addr common.Address) (bool, error) {
+	ai, _, err := ix.onchainAgent(ctx, chain, agentID)
+	return agentSigner(ctx, ix.store, chain, agentID, ai, err, addr)
+}
+
+// signerStore is the part of the store agentSigner consults.
+type signerStore interface {
+	IsRegistrationOwner(ctx context.Context, chainID, agentID, address string) (bool, error)
+	IsAgentOwner(ctx context.Context, chainID, agentID, address string) (bool, error)
+}
+
+// agentSigner decides IsAgentSigner from the registry entry ai, or the error
+// reading it. A revert is an answer (the v0 registry has no such agent), not an
+// outage.
+func agentSigner(ctx context.Context, st signerStore, chain string, agentID int64, ai erc.AgentInfo, readErr error, addr common.Address) (bool, error) {
+	id := strconv.FormatInt(agentID, 10)
+	if readErr != nil && !isRevert(readErr) {
+		log.WithError(readErr).WithFields(log.Fields{"chain": chain, "agentID": agentID}).Debug("on-chain lookup unavailable; using stored owner")
+		return st.IsAgentOwner(ctx, chain, id, addr.Hex())
+	}
+	if readErr == nil && ai.AgentAddress != (common.Address{}) && ai.AgentAddress == addr {
+		return true, nil
+	}
+	return st.IsRegistrationOwner(ctx, chain, id, addr.Hex())
+}
+
+// Networks returns the configured chains.
+func (ix *Indexer) Networks() []Chain {
+	return append([]Chain(nil), ix.nets...)
+}
@@ -0,0 +1,68 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	erc "github.com/praxis/praxis-explorer/internal/erc8004"
+)
+
+// fakeSigners answers like the store: owner is the v1 owner, stored the address
+// recorded for the agent (card-declared addresses never reach it).
+type fakeSigners struct {
+	owner, stored common.Address
+	asked         []string
+}
+
+func (f *fakeSigners) IsRegistrationOwner(_ context.Context, _, _, address string) (bool, error) {
+	f.asked = append(f.asked, "owner")
+	return strings.EqualFold(address, f.owner.Hex()), nil
+}
+
+func (f *fakeSigners) IsAgentOwner(_ context.Context, _, _, address string) (bool, error) {
+	f.asked = append(f.asked, "stored")
+	return strings.EqualFold(address, f.owner.Hex()) || strings.EqualFold(address, f.stored.Hex()), nil
+}
+
+func TestAgentSigner(t *testing.T) {
+	onchain := common.HexToAddress("0x1111111111111111111111111111111111111111")
+	owner := common.HexToAddress("0x2222222222222222222222222222222222222222")
+	stale := common.HexToAddress("0x3333333333333333333333333333333333333333")
+	other := common.HexToAddress("0x4444444444444444444444444444444444444444")
+	unreachable := errors.New("dial tcp: connection refused")
+	reverted := errors.New("execution reverted: agent not found")
+
+	cases := []struct {
+		name    string
+		ai      erc.AgentInfo
+		readErr error
+		signer  common.Address
+		want    bool
+		asked   string
+	}{
+		{"on-chain address", erc.AgentInfo{AgentAddress: onchain}, nil, onchain, true, ""},
+		{"v1 owner", erc.AgentInfo{AgentAddress: onchain}, nil, owner, true, "owner"},
+		// the chain answered: an address recorded earlier no longer counts
+		{"mismatch ignores stored address", erc.AgentInfo{AgentAddress: onchain}, nil, stale, false, "owner"},
+		{"mismatch", erc.AgentInfo{AgentAddress: onchain}, nil, other, false, "owner"},
+		{"reverted is an answer", erc.AgentInfo{}, reverted, stale, false, "owner"},
+		{"unreachable falls back to stored", erc.AgentInfo{}, unreachable, stale, true, "stored"},
+		{"unreachable, unknown signer", erc.AgentInfo{}, unreachable, other, false, "stored"},
+	}
+	for _, tc := range cases {
+		st := &fakeSigners{owner: owner, stored: stale}
+		got, err := agentSigner(context.Background(), st, "eip155:11155111", 7, tc.ai, tc.readErr, tc.signer)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: got %v, want %v", tc.name, got, tc.want)
+		}
+		if asked := strings.Join(st.asked, ","); asked != tc.asked {
+			t.Errorf("%s: consulted %q, want %q", tc.name, asked, tc.asked)
+		}
+	}
+}
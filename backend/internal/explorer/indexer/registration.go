@@ -0,0 +1,91 @@
+package indexer
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// parseRegistration turns a v1 registration JSON into its typed form, keeping every
+// declared endpoint (including unknown fields) and the raw document.
+func parseRegistration(tokenURI string, owner string, raw map[string]any) store.Registration {
+	reg := store.Registration{
+		TokenURI:       tokenURI,
+		Owner:          owner,
+		Endpoints:      []store.Endpoint{},
+		SupportedTrust: []string{},
+		Wallets:        []string{},
+		Raw:            raw,
+	}
+	reg.Name, _ = raw["name"].(string)
+	reg.Description, _ = raw["description"].(string)
+
+	if arr, ok := raw["endpoints"].([]any); ok {
+		for _, it := range arr {
+			m, ok := it.(map[string]any)
+			if !ok {
+				continue
+			}
+			ep := store.Endpoint{Source: store.EndpointSourceRegistration}
+			ep.Name, _ = m["name"].(string)
+			ep.Endpoint, _ = m["endpoint"].(string)
+			ep.Version, _ = m["version"].(string)
+			ep.Name = strings.TrimSpace(ep.Name)
+			ep.Endpoint = strings.TrimSpace(ep.Endpoint)
+			if ep.Name == "" || ep.Endpoint == "" {
+				continue
+			}
+			for k, v := range m {
+				switch k {
+				case "name", "endpoint", "version":
+				default:
+					if ep.Extra == nil {
+						ep.Extra = map[string]any{}
+					}
+					ep.Extra[k] = v
+				}
+			}
+			reg.Endpoints = append(reg.Endpoints, ep)
+			if strings.EqualFold(ep.Name, "agentWallet") {
+				reg.Wallets = append(reg.Wallets, ep.Endpoint)
+			}
+		}
+	}
+
+	if arr, ok := raw["supportedTrust"].([]any); ok {
+		for _, it := range arr {
+			if s, ok := it.(string); ok && strings.TrimSpace(s) != "" {
+				reg.SupportedTrust = append(reg.SupportedTrust, strings.ToLower(strings.TrimSpace(s)))
+			}
+		}
+	}
+	return reg
+}
+
+// registrationDomain picks the value stored in agents.domain for an agent known only
+// through its registration: the host of its first http(s) endpoint, else the tokenURI.
+func registrationDomain(reg store.Registration) string {
+	for _, name := range []string{"A2A", "MCP"} {
+		if h := endpointHost(reg.Endpoint(name)); h != "" {
+			return h
+		}
+	}
+	for _, ep := range reg.Endpoints {
+		if h := endpointHost(ep.Endpoint); h != "" {
+			return h
+		}
+	}
+	return reg.TokenURI
+}
+
+func endpointHost(endpoint string) string {
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		return ""
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
@@ -0,0 +1,60 @@
+package indexer
+
+import (
+	"testing"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+func TestParseRegistration_KeepsAllEndpointsTrustAndWallets(t *testing.T) {
+	raw := map[string]any{
+		"type":        "https://eips.ethereum.org/EIPS/eip-8004#registration-v1",
+		"name":        "mcp-only",
+		"description": "agent without an A2A card",
+		"endpoints": []any{
+			map[string]any{"name": "MCP", "endpoint": "https://mcp.example/rpc", "version": "2025-06-18", "capabilities": map[string]any{"tools": true}},
+			map[string]any{"name": "DID", "endpoint": "did:method:foobar", "version": "v1"},
+			map[string]any{"name": "agentWallet", "endpoint": "eip155:1:0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb"},
+			map[string]any{"name": "", "endpoint": "ignored"},
+			"not-an-object",
+		},
+		"supportedTrust": []any{"Reputation", "tee-attestation"},
+	}
+
+	reg := parseRegistration("ipfs://cid", "0x3333333333333333333333333333333333333333", raw)
+
+	if reg.Name != "mcp-only" || reg.Description == "" {
+		t.Fatalf("name/description not parsed: %+v", reg)
+	}
+	if len(reg.Endpoints) != 3 {
+		t.Fatalf("expected 3 endpoints, got %d: %+v", len(reg.Endpoints), reg.Endpoints)
+	}
+	mcp := reg.Endpoints[0]
+	if mcp.Source != store.EndpointSourceRegistration || mcp.Version != "2025-06-18" {
+		t.Fatalf("unexpected MCP endpoint: %+v", mcp)
+	}
+	if _, ok := mcp.Extra["capabilities"]; !ok {
+		t.Fatalf("extra fields dropped: %+v", mcp.Extra)
+	}
+	if reg.Endpoint("a2a") != "" || reg.Endpoint("mcp") != "https://mcp.example/rpc" {
+		t.Fatalf("endpoint lookup mismatch")
+	}
+	if len(reg.Wallets) != 1 || reg.Wallets[0] != "eip155:1:0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb" {
+		t.Fatalf("unexpected wallets: %v", reg.Wallets)
+	}
+	if len(reg.SupportedTrust) != 2 || reg.SupportedTrust[0] != "reputation" {
+		t.Fatalf("unexpected trust models: %v", reg.SupportedTrust)
+	}
+	if d := registrationDomain(reg); d != "mcp.example" {
+		t.Fatalf("expected domain from MCP host, got %q", d)
+	}
+}
+
+func TestRegistrationDomain_FallsBackToTokenURI(t *testing.T) {
+	reg := parseRegistration("ipfs://bafy", "", map[string]any{
+		"endpoints": []any{map[string]any{"name": "DID", "endpoint": "did:web:example"}},
+	})
+	if d := registrationDomain(reg); d != "ipfs://bafy" {
+		t.Fatalf("expected tokenURI fallback, got %q", d)
+	}
+}
@@ -0,0 +1,188 @@
+package indexer
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	erc "github.com/praxis/praxis-explorer/internal/erc8004"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// unresolvedTTL is how long a domain or account no registry knows is answered
+	// from memory, so public lookups of unknown ones don't each spend RPC calls.
+	unresolvedTTL = time.Minute
+	// unresolvedCacheSize bounds the domains and accounts remembered as unresolved.
+	unresolvedCacheSize = 4096
+)
+
+// ResolveAddress looks addr up in chain's identity registry. An agent found there
+// is indexed on the way (or, when its card cannot be fetched, returned as the
+// registry knows it); false means the registry has no agent at addr, which is
+// remembered for unresolvedTTL.
+func (ix *Indexer) ResolveAddress(ctx context.Context, chain string, addr common.Address) (store.AgentRow, bool, error) {
+	account := caip.Account(chain, addr)
+	if ix.unresolvedUntil(account).After(time.Now()) {
+		return store.AgentRow{}, false, nil
+	}
+	row, found, err := ix.resolveOnChain(ctx, chain, "resolveByAddress", func(ident *erc.Identity) (erc.AgentInfo, error) {
+		return ident.ResolveByAddress(ctx, &bind.CallOpts{Context: ctx}, addr)
+	})
+	if !found && err == nil {
+		ix.markUnresolved(account)
+	}
+	return row, found, err
+}
+
+// ResolveDomain asks the identity registry of every configured chain, at once,
+// for the agent registered under domain, and indexes those found like
+// ResolveAddress. A domain no registry knows is remembered for unresolvedTTL; an
+// error is only returned when nothing was found and some registry failed.
+func (ix *Indexer) ResolveDomain(ctx context.Context, domain string) ([]store.AgentRow, error) {
+	if ix.unresolvedUntil(domain).After(time.Now()) {
+		return []store.AgentRow{}, nil
+	}
+	rows := make([]*store.AgentRow, len(ix.nets))
+	errs := make([]error, len(ix.nets))
+	var wg sync.WaitGroup
+	for i, n := range ix.nets {
+		wg.Add(1)
+		go func(i int, chain string) {
+			defer wg.Done()
+			row, found, err := ix.resolveOnChain(ctx, chain, "resolveByDomain", func(ident *erc.Identity) (erc.AgentInfo, error) {
+				return ident.ResolveByDomain(ctx, &bind.CallOpts{Context: ctx}, domain)
+			})
+			if found {
+				rows[i] = &row
+			}
+			errs[i] = err
+		}(i, n.Name)
+	}
+	wg.Wait()
+
+	out := []store.AgentRow{}
+	for _, r := range rows {
+		if r != nil {
+			out = append(out, *r)
+		}
+	}
+	err := errors.Join(errs...)
+	switch {
+	case len(out) > 0:
+		if err != nil {
+			log.WithError(err).WithField("domain", domain).Warn("some registries could not resolve domain")
+		}
+		return out, nil
+	case err != nil:
+		return out, err
+	}
+	ix.markUnresolved(domain)
+	return out, nil
+}
+
+// resolveOnChain runs lookup, the registry call named method, against chain's
+// identity registry and indexes the agent it returns.
+func (ix *Indexer) resolveOnChain(ctx context.Context, chain, method string, lookup func(*erc.Identity) (erc.AgentInfo, error)) (store.AgentRow, bool, error) {
+	client, idAddr, err := ix.chainClient(chain)
+	if err != nil {
+		return store.AgentRow{}, false, err
+	}
+	ident, err := erc.NewIdentity(idAddr, client)
+	if err != nil {
+		return store.AgentRow{}, false, err
+	}
+	began := time.Now()
+	ai, err := lookup(ident)
+	// the registry reverts for domains and addresses it has no agent for
+	if isRevert(err) {
+		err = nil
+	}
+	ix.observeRPC(chain, method, began, err)
+	if err != nil || ai.AgentId == nil || ai.AgentId.Sign() == 0 {
+		return store.AgentRow{}, false, err
+	}
+	agentID := ai.AgentId.Int64()
+	if err := ix.storeCard(ctx, chain, idAddr.Hex(), agentID, ai.AgentDomain, ai.AgentAddress); err != nil {
+		log.WithError(err).WithFields(log.Fields{"chain": chain, "agentID": agentID}).Warn("resolved agent not indexed")
+		return store.AgentRow{
+			ChainID:      chain,
+			RegistryAddr: idAddr.Hex(),
+			AgentID:      agentID,
+			Domain:       ai.AgentDomain,
+			AddressCAIP:  caip.Account(chain, ai.AgentAddress),
+			CardJSON:     map[string]any{},
+			TrustModels:  []string{},
+			Skills:       []map[string]any{},
+			Capabilities: map[string]any{},
+		}, true, nil
+	}
+	row, err := ix.store.GetAgent(ctx, chain, strconv.FormatInt(agentID, 10))
+	return row, err == nil, err
+}
+
+// isRevert reports whether err is a contract call that reverted, rather than one
+// that could not be made.
+func isRevert(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "execution reverted")
+}
+
+// unresolvedUntil returns until when key, a domain or CAIP-10 account, is known
+// to resolve to no agent.
+func (ix *Indexer) unresolvedUntil(key string) time.Time {
+	ix.unresolvedMu.Lock()
+	defer ix.unresolvedMu.Unlock()
+	return ix.unresolved[key]
+}
+
+func (ix *Indexer) markUnresolved(key string) {
+	ix.unresolvedMu.Lock()
+	defer ix.unresolvedMu.Unlock()
+	if ix.unresolved == nil || len(ix.unresolved) >= unresolvedCacheSize {
+		ix.unresolved = map[string]time.Time{}
+	}
+	ix.unresolved[key] = time.Now().Add(unresolvedTTL)
+}
+
+// CardRegisters reports whether card, as served at an agent's domain, declares the
+// agent's registration: an entry of its registrations with the agent's id and, when
+// the agent has an address, the same account.
+func CardRegisters(card map[string]any, agent store.AgentRow) bool {
+	regs, _ := card["registrations"].([]any)
+	for _, r := range regs {
+		reg, ok := r.(map[string]any)
+		if !ok || !sameAgentID(reg["agentId"], agent.AgentID) {
+			continue
+		}
+		if agent.AddressCAIP == "" {
+			return true
+		}
+		addr, _ := reg["agentAddress"].(string)
+		if addr == "" {
+			addr, _ = reg["addressCaip10"].(string) // backward compat
+		}
+		if a, ok := caip.NormalizeAccount(addr, agent.ChainID); ok && strings.EqualFold(a, agent.AddressCAIP) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameAgentID compares a card's agentId, a JSON number or decimal string, to id.
+func sameAgentID(v any, id int64) bool {
+	switch x := v.(type) {
+	case float64:
+		return x == float64(id)
+	case string:
+		n, err := strconv.ParseInt(strings.TrimSpace(x), 10, 64)
+		return err == nil && n == id
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package indexer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+func TestCardRegisters(t *testing.T) {
+	agent := store.AgentRow{
+		ChainID:     "eip155:11155111",
+		AgentID:     7,
+		AddressCAIP: "eip155:11155111:0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0",
+	}
+	card := func(regs ...any) map[string]any { return map[string]any{"registrations": regs} }
+
+	cases := []struct {
+		name string
+		card map[string]any
+		want bool
+	}{
+		{"caip10", card(map[string]any{"agentId": float64(7), "agentAddress": "eip155:11155111:0x742d35cc6634c0532925a3b844bc9e7595f0beb0"}), true},
+		{"bare address, string id", card(map[string]any{"agentId": "7", "agentAddress": "0x742D35CC6634C0532925A3B844BC9E7595F0BEB0"}), true},
+		{"legacy field", card(map[string]any{"agentId": float64(7), "addressCaip10": "sepolia:0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"}), true},
+		{"second entry", card(map[string]any{"agentId": float64(1)}, map[string]any{"agentId": float64(7), "agentAddress": agent.AddressCAIP}), true},
+		{"other agent", card(map[string]any{"agentId": float64(8), "agentAddress": agent.AddressCAIP}), false},
+		{"other address", card(map[string]any{"agentId": float64(7), "agentAddress": "eip155:11155111:0x0000000000000000000000000000000000000001"}), false},
+		{"other chain", card(map[string]any{"agentId": float64(7), "agentAddress": "eip155:1:0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0"}), false},
+		{"no registrations", map[string]any{"name": "x"}, false},
+	}
+	for _, tc := range cases {
+		if got := CardRegisters(tc.card, agent); got != tc.want {
+			t.Errorf("%s: CardRegisters = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+
+	agent.AddressCAIP = ""
+	if !CardRegisters(card(map[string]any{"agentId": float64(7)}), agent) {
+		t.Errorf("agent without address: id alone should match")
+	}
+}
+
+func TestResolveAddressRemembersUnresolved(t *testing.T) {
+	ix := &Indexer{}
+	addr := common.HexToAddress("0x742d35Cc6634C0532925a3b844Bc9e7595f0bEb0")
+
+	// the chain is not configured, so a lookup that reaches it fails
+	if _, _, err := ix.ResolveAddress(context.Background(), "eip155:11155111", addr); err == nil {
+		t.Fatal("lookup on an unconfigured chain succeeded")
+	}
+	ix.markUnresolved(caip.Account("eip155:11155111", addr))
+	if _, found, err := ix.ResolveAddress(context.Background(), "eip155:11155111", addr); found || err != nil {
+		t.Fatalf("remembered account: found=%v err=%v", found, err)
+	}
+	// other chains are separate accounts
+	if _, _, err := ix.ResolveAddress(context.Background(), "eip155:1", addr); err == nil {
+		t.Fatal("account on another chain answered from memory")
+	}
+}
@@ -0,0 +1,136 @@
+package indexer
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/praxis/praxis-explorer/internal/explorer/logging"
+	"github.com/praxis/praxis-explorer/internal/explorer/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+// Watcher modes reported in ChainStatus.Mode.
+const (
+	ModeDisabled     = "disabled"     // no RPC or identity registry configured
+	ModeConnecting   = "connecting"   // dialed, watcher (re)starting
+	ModeSubscription = "subscription" // receiving logs over a subscription
+	ModePolling      = "polling"      // provider lacks subscriptions; polling eth_getLogs
+	ModeDown         = "down"         // dial or subscribe failed; not watching
+)
+
+// ChainStatus is the watcher state of one configured chain.
+type ChainStatus struct {
+	// Chain is the CAIP-2 chain id, Alias the network's name in the config file.
+	Chain string `json:"chain"`
+	Alias string `json:"alias,omitempty"`
+	Mode  string `json:"mode"`
+	// LastBlock is the block checkpoint: identity logs up to it were handled.
+	LastBlock   uint64     `json:"lastBlock"`
+	Head        uint64     `json:"head"`
+	HeadLag     uint64     `json:"headLag"`
+	LastError   string     `json:"lastError,omitempty"`
+	LastErrorAt *time.Time `json:"lastErrorAt,omitempty"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// SeedStatus is the state of the seed domain crawl.
+type SeedStatus struct {
+	Domains     int        `json:"domains"`
+	LastCrawlAt *time.Time `json:"lastCrawlAt,omitempty"`
+	LastFetched int        `json:"lastFetched"`
+	LastFailed  int        `json:"lastFailed"`
+}
+
+// Status is a point-in-time view of the indexer for /status.
+type Status struct {
+	Chains []ChainStatus `json:"chains"`
+	Seeds  SeedStatus    `json:"seeds"`
+	// ZeroIDQueue is how many agents still carry the placeholder agent id 0.
+	ZeroIDQueue int64 `json:"zeroIdQueue"`
+}
+
+// updateChain applies fn to the status of chain under the status lock.
+func (ix *Indexer) updateChain(chain string, fn func(*ChainStatus)) {
+	ix.statusMu.Lock()
+	defer ix.statusMu.Unlock()
+	if ix.chains == nil {
+		ix.chains = map[string]*ChainStatus{}
+	}
+	cs := ix.chains[chain]
+	if cs == nil {
+		cs = &ChainStatus{Chain: chain}
+		ix.chains[chain] = cs
+	}
+	fn(cs)
+	cs.UpdatedAt = time.Now().UTC()
+}
+
+func (ix *Indexer) setMode(chain, mode string) {
+	ix.updateChain(chain, func(cs *ChainStatus) { cs.Mode = mode })
+}
+
+func (ix *Indexer) chainError(chain string, err error) {
+	now := time.Now().UTC()
+	ix.updateChain(chain, func(cs *ChainStatus) {
+		cs.LastError, cs.LastErrorAt = logging.Redact(err.Error()), &now
+	})
+}
+
+// observeRPC records an RPC call in metrics and a failure as the chain's last error.
+func (ix *Indexer) observeRPC(chain, method string, began time.Time, err error) {
+	metrics.ObserveRPC(chain, method, began, err)
+	if err != nil {
+		ix.chainError(chain, err)
+	}
+}
+
+// saveBlock advances the block checkpoint of a registry.
+func (ix *Indexer) saveBlock(ctx context.Context, chain string, idAddr common.Address, block uint64) {
+	if err := ix.store.SaveBlockCheckpoint(ctx, chain, idAddr.Hex(), block); err != nil {
+		log.WithError(err).WithField("chain", chain).Warn("failed to save block checkpoint")
+		return
+	}
+	ix.setProgress(chain, 0, block)
+}
+
+// setProgress records the chain head (when non-zero) and block checkpoint, and the
+// lag between them.
+func (ix *Indexer) setProgress(chain string, head, last uint64) {
+	ix.updateChain(chain, func(cs *ChainStatus) {
+		if head > 0 {
+			cs.Head = head
+		}
+		if last > cs.LastBlock {
+			cs.LastBlock = last
+		}
+		cs.HeadLag = 0
+		if cs.Head > cs.LastBlock {
+			cs.HeadLag = cs.Head - cs.LastBlock
+		}
+		metrics.HeadLag.WithLabelValues(chain).Set(float64(cs.HeadLag))
+	})
+}
+
+// Status reports every configured chain's watcher, the seed crawl and the zero-id
+// upgrade queue.
+func (ix *Indexer) Status(ctx context.Context) (Status, error) {
+	ix.statusMu.Lock()
+	st := Status{Seeds: ix.seedStatus}
+	for _, n := range ix.nets {
+		cs := ChainStatus{Chain: n.Name, Mode: ModeDisabled}
+		if c := ix.chains[n.Name]; c != nil {
+			cs = *c
+		}
+		cs.Alias = n.Alias
+		st.Chains = append(st.Chains, cs)
+	}
+	ix.statusMu.Unlock()
+	st.Seeds.Domains = len(ix.seeds)
+	if ix.store == nil {
+		return st, nil
+	}
+	n, err := ix.store.CountZeroIDAgents(ctx)
+	st.ZeroIDQueue = n
+	return st, err
+}
@@ -0,0 +1,171 @@
+// Package logging configures the process-wide logrus logger from the `logging`
+// section of the config file: JSON or text output, a default level with
+// per-package overrides, and redaction of credentials in every entry. It also
+// carries correlation fields (request_id, chain, agent_id, block, tx_hash) in a
+// context, so code deep in a call chain logs them without threading them by hand.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v3"
+)
+
+// Output formats.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config is the `logging` section of the config file.
+type Config struct {
+	// Format is json or text.
+	Format string `yaml:"format"`
+	// Level applies to packages without an entry in Packages.
+	Level string `yaml:"level"`
+	// Packages maps a package name (indexer, store, api, fetcher, ...) to its level.
+	Packages map[string]string `yaml:"packages"`
+}
+
+// DefaultConfig logs text at info level.
+func DefaultConfig() Config {
+	return Config{Format: FormatText, Level: "info"}
+}
+
+// LoadFile reads the `logging` section of the YAML config at path; a missing
+// section leaves the defaults.
+func LoadFile(path string) (Config, error) {
+	cfg := DefaultConfig()
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	var doc struct {
+		Logging *Config `yaml:"logging"`
+	}
+	doc.Logging = &cfg
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return DefaultConfig(), err
+	}
+	return cfg, nil
+}
+
+// Setup applies cfg to the standard logrus logger, which every package logs
+// through. Caller reporting is switched on so entries can be attributed to the
+// package that logged them.
+func Setup(cfg Config) error {
+	def, err := log.ParseLevel(cfg.Level)
+	if err != nil {
+		return fmt.Errorf("logging level: %w", err)
+	}
+	f := &formatter{def: def, packages: map[string]log.Level{}}
+	most := def
+	for pkg, v := range cfg.Packages {
+		l, err := log.ParseLevel(v)
+		if err != nil {
+			return fmt.Errorf("logging level of %s: %w", pkg, err)
+		}
+		f.packages[pkg] = l
+		if l > most {
+			most = l
+		}
+	}
+	switch cfg.Format {
+	case FormatJSON:
+		f.next = &log.JSONFormatter{CallerPrettyfier: callerFile, FieldMap: log.FieldMap{log.FieldKeyFile: "caller"}}
+	case FormatText, "":
+		f.next = &log.TextFormatter{FullTimestamp: true, CallerPrettyfier: callerFile, FieldMap: log.FieldMap{log.FieldKeyFile: "caller"}}
+	default:
+		return fmt.Errorf("unknown logging format %q (want json or text)", cfg.Format)
+	}
+	log.SetReportCaller(true)
+	log.SetFormatter(f)
+	// the logger lets through what the most verbose package wants; the formatter
+	// drops the rest per package
+	log.SetLevel(most)
+	return nil
+}
+
+// formatter drops entries below their package's level and redacts the rest
+// before handing them to next.
+type formatter struct {
+	next     log.Formatter
+	def      log.Level
+	packages map[string]log.Level
+}
+
+func (f *formatter) Format(e *log.Entry) ([]byte, error) {
+	pkg := callerPackage(e)
+	lvl := f.def
+	if l, ok := f.packages[pkg]; ok {
+		lvl = l
+	}
+	if e.Level > lvl {
+		return nil, nil
+	}
+	c := *e
+	c.Message = Redact(e.Message)
+	c.Data = make(log.Fields, len(e.Data)+1)
+	for k, v := range e.Data {
+		c.Data[k] = redactValue(v)
+	}
+	if pkg != "" {
+		c.Data["pkg"] = pkg
+	}
+	return f.next.Format(&c)
+}
+
+// callerPackage is the last path element of the package that logged e, e.g.
+// "indexer" for github.com/praxis/praxis-explorer/internal/explorer/indexer.
+func callerPackage(e *log.Entry) string {
+	if !e.HasCaller() {
+		return ""
+	}
+	return funcPackage(e.Caller.Function)
+}
+
+// funcPackage takes the package from a fully qualified function name such as
+// "example.com/mod/pkg.(*T).Method".
+func funcPackage(fn string) string {
+	pkg, _, _ := strings.Cut(path.Base(fn), ".")
+	return pkg
+}
+
+// callerFile reports the caller as file:line and leaves out the function.
+func callerFile(fr *runtime.Frame) (function, file string) {
+	return "", fmt.Sprintf("%s:%d", path.Base(fr.File), fr.Line)
+}
+
+type ctxKey struct{}
+
+// With returns a context carrying fields, added to (and overriding) those ctx
+// already carries, for From to log.
+func With(ctx context.Context, fields log.Fields) context.Context {
+	prev, _ := ctx.Value(ctxKey{}).(log.Fields)
+	merged := make(log.Fields, len(prev)+len(fields))
+	for k, v := range prev {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, ctxKey{}, merged)
+}
+
+// From returns an entry with the correlation fields ctx carries and, inside a
+// sampled span, its trace_id and span_id.
+func From(ctx context.Context) *log.Entry {
+	fields, _ := ctx.Value(ctxKey{}).(log.Fields)
+	e := log.WithFields(fields)
+	if sc := trace.SpanContextFromContext(ctx); sc.IsSampled() {
+		e = e.WithFields(log.Fields{"trace_id": sc.TraceID().String(), "span_id": sc.SpanID().String()})
+	}
+	return e
+}
@@ -0,0 +1,104 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func TestRedact(t *testing.T) {
+	cases := map[string]string{
+		"postgres://postgres:s3cret@db:5432/praxis?sslmode=disable":        "postgres://postgres:REDACTED@db:5432/praxis?sslmode=disable",
+		"https://sepolia.infura.io/v3/f592d86d563c456e808f9eb554b35c96":    "https://sepolia.infura.io/v3/REDACTED",
+		"dial wss://eth-mainnet.g.alchemy.com/v2/Ab3_xYz-12345678901: eof": "dial wss://eth-mainnet.g.alchemy.com/v2/REDACTED: eof",
+		"https://rpc.example/?apikey=abc&chain=1":                          "https://rpc.example/?apikey=REDACTED&chain=1",
+		"https://tok3n@rpc.example/":                                       "https://REDACTED@rpc.example/",
+		"host=db user=postgres password=hunter2 dbname=praxis":             "host=db user=postgres password=REDACTED dbname=praxis",
+		// nothing secret: left byte for byte
+		"https://agent.example/.well-known/agent-card.json":                  "https://agent.example/.well-known/agent-card.json",
+		"ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi": "ipfs://bafybeigdyrzt5sfp7udm7hu76uh7y26nf3efuylqabf3oclgtqy55fbzdi",
+		"agent 0x2222222222222222222222222222222222222222 registered":        "agent 0x2222222222222222222222222222222222222222 registered",
+	}
+	for in, want := range cases {
+		if got := Redact(in); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSetupJSONLevelsAndRedaction(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		_ = Setup(DefaultConfig())
+		log.SetReportCaller(false)
+	}()
+	if err := Setup(Config{Format: FormatJSON, Level: "warn", Packages: map[string]string{"logging": "debug"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := With(context.Background(), log.Fields{"request_id": "r1", "chain": "sepolia"})
+	ctx = With(ctx, log.Fields{"agent_id": 7})
+	From(ctx).WithError(errors.New("dial https://x.example/v3/0123456789abcdef0123456789abcdef failed")).
+		WithField("db", "postgres://u:pw@db/x").Debug("fetching")
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("not one JSON entry: %q", buf.String())
+	}
+	for k, want := range map[string]any{
+		"request_id": "r1", "chain": "sepolia", "agent_id": float64(7), "pkg": "logging",
+		"level": "debug", "msg": "fetching", "db": "postgres://u:REDACTED@db/x",
+		"error": "dial https://x.example/v3/REDACTED failed",
+	} {
+		if entry[k] != want {
+			t.Errorf("%s = %v, want %v", k, entry[k], want)
+		}
+	}
+	if c, _ := entry["caller"].(string); !strings.HasPrefix(c, "logging_test.go:") {
+		t.Errorf("caller = %v", entry["caller"])
+	}
+
+	// a package without an override logs at the default level only
+	buf.Reset()
+	f := &formatter{next: &log.JSONFormatter{}, def: log.WarnLevel, packages: map[string]log.Level{}}
+	out, err := f.Format(&log.Entry{Logger: log.StandardLogger(), Level: log.InfoLevel, Message: "quiet", Data: log.Fields{}})
+	if err != nil || len(out) != 0 {
+		t.Errorf("info entry below warn formatted as %q", out)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "erc8004.yaml")
+	os.WriteFile(path, []byte("networks: {}\nlogging:\n  format: json\n  packages:\n    store: debug\n"), 0o644)
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Format != FormatJSON || cfg.Level != "info" || cfg.Packages["store"] != "debug" {
+		t.Errorf("cfg = %+v", cfg)
+	}
+	if err := Setup(Config{Format: "xml", Level: "info"}); err == nil {
+		t.Error("unknown format accepted")
+	}
+}
+
+func TestFuncPackage(t *testing.T) {
+	for fn, want := range map[string]string{
+		"github.com/praxis/praxis-explorer/internal/explorer/indexer.(*Indexer).storeCard": "indexer",
+		"github.com/praxis/praxis-explorer/internal/explorer/api.RegisterRoutes.func3":     "api",
+		"main.main": "main",
+	} {
+		if got := funcPackage(fn); got != want {
+			t.Errorf("funcPackage(%q) = %q, want %q", fn, got, want)
+		}
+	}
+}
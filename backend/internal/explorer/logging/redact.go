@@ -0,0 +1,96 @@
+package logging
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Mask replaces redacted credentials.
+const Mask = "REDACTED"
+
+var (
+	urlPattern = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.\-]*://[^\s"'<>]+`)
+	// key=value credentials outside URLs, as in keyword/value Postgres DSNs
+	assignPattern = regexp.MustCompile(`(?i)\b(password|passwd|pwd|secret|token|api[_-]?key|apikey)=([^\s&"']+)`)
+	// query parameters holding credentials
+	secretParam = regexp.MustCompile(`(?i)key|token|secret|password|passwd|auth|signature|sig$`)
+	// path segments of RPC URLs that are API keys: after a version segment
+	// (Infura /v3/<key>, Alchemy /v2/<key>) or long hex strings
+	versionSegment = regexp.MustCompile(`^v[0-9]+$`)
+	keySegment     = regexp.MustCompile(`^[A-Za-z0-9_\-]{16,}$`)
+	hexSegment     = regexp.MustCompile(`^[0-9a-fA-F]{32,}$`)
+)
+
+// Redact masks credentials in s: passwords (or bare user tokens) in URL user
+// info, credential query parameters, API keys in RPC URL paths, and key=value
+// credentials such as password=... in DSNs.
+func Redact(s string) string {
+	if !strings.Contains(s, "://") && !strings.Contains(s, "=") {
+		return s
+	}
+	s = urlPattern.ReplaceAllStringFunc(s, redactURL)
+	return assignPattern.ReplaceAllString(s, "${1}="+Mask)
+}
+
+func redactURL(match string) string {
+	// punctuation ending a sentence or an error prefix is not part of the URL
+	raw := strings.TrimRight(match, ".,;:)]}")
+	tail := match[len(raw):]
+	u, err := url.Parse(raw)
+	if err != nil {
+		return match
+	}
+	changed := false
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), Mask)
+		} else {
+			u.User = url.User(Mask)
+		}
+		changed = true
+	}
+	if u.RawQuery != "" {
+		q := u.Query()
+		for k := range q {
+			if secretParam.MatchString(k) {
+				q.Set(k, Mask)
+				changed = true
+			}
+		}
+		if changed {
+			u.RawQuery = q.Encode()
+		}
+	}
+	segs := strings.Split(u.Path, "/")
+	for i, seg := range segs {
+		if hexSegment.MatchString(seg) || (i > 0 && versionSegment.MatchString(segs[i-1]) && keySegment.MatchString(seg)) {
+			segs[i] = Mask
+			changed = true
+		}
+	}
+	if !changed {
+		return match
+	}
+	u.Path, u.RawPath = strings.Join(segs, "/"), ""
+	return u.String() + tail
+}
+
+// redactValue redacts strings, errors and string slices logged as fields.
+func redactValue(v any) any {
+	switch x := v.(type) {
+	case string:
+		return Redact(x)
+	case error:
+		if s := x.Error(); Redact(s) != s {
+			return Redact(s)
+		}
+	case []string:
+		out := make([]string, len(x))
+		for i, s := range x {
+			out[i] = Redact(s)
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,511 @@
+// Package mcp is a minimal Model Context Protocol client used to introspect the MCP
+// endpoints agents declare: initialize, then tools/list, resources/list and
+// prompts/list. It speaks streamable HTTP and falls back to the legacy HTTP+SSE
+// transport for older servers.
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// ProtocolVersion is the MCP revision we announce in initialize.
+const ProtocolVersion = "2025-06-18"
+
+// maxPages bounds cursor pagination for servers that never stop returning nextCursor.
+const maxPages = 20
+
+var errLegacyTransport = errors.New("server does not accept streamable HTTP")
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string { return fmt.Sprintf("mcp error %d: %s", e.Code, e.Message) }
+
+type rpcMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// Client is a single MCP session against one endpoint. It is not safe for
+// concurrent use.
+type Client struct {
+	http      *http.Client
+	url       string
+	sessionID string
+	version   string
+	nextID    int
+	legacy    *sseSession
+}
+
+// NewClient prepares a client for the endpoint; nothing is sent until Initialize.
+func NewClient(httpClient *http.Client, endpoint string) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{http: httpClient, url: endpoint}
+}
+
+// Introspect runs the full handshake and lists everything the server advertises.
+// Lists the server doesn't declare a capability for are left empty.
+func Introspect(ctx context.Context, httpClient *http.Client, endpoint string) (store.MCPCatalog, error) {
+	c := NewClient(httpClient, endpoint)
+	defer c.Close()
+
+	cat, err := c.Initialize(ctx)
+	if err != nil {
+		return cat, err
+	}
+	if _, ok := cat.Capabilities["tools"]; ok {
+		if cat.Tools, err = c.ListTools(ctx); err != nil {
+			return cat, fmt.Errorf("tools/list: %w", err)
+		}
+	}
+	if _, ok := cat.Capabilities["resources"]; ok {
+		if cat.Resources, err = c.ListResources(ctx); err != nil {
+			return cat, fmt.Errorf("resources/list: %w", err)
+		}
+	}
+	if _, ok := cat.Capabilities["prompts"]; ok {
+		if cat.Prompts, err = c.ListPrompts(ctx); err != nil {
+			return cat, fmt.Errorf("prompts/list: %w", err)
+		}
+	}
+	return cat, nil
+}
+
+// Initialize performs the initialize request and initialized notification.
+func (c *Client) Initialize(ctx context.Context) (store.MCPCatalog, error) {
+	cat := store.MCPCatalog{Endpoint: c.url}
+	params := map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "praxis-explorer", "version": "1.0.0"},
+	}
+
+	raw, err := c.request(ctx, "initialize", params)
+	if errors.Is(err, errLegacyTransport) {
+		if c.legacy, err = openSSESession(ctx, c.http, c.url); err != nil {
+			return cat, fmt.Errorf("legacy sse transport: %w", err)
+		}
+		raw, err = c.request(ctx, "initialize", params)
+	}
+	if err != nil {
+		return cat, err
+	}
+
+	var res struct {
+		ProtocolVersion string         `json:"protocolVersion"`
+		Capabilities    map[string]any `json:"capabilities"`
+		ServerInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		return cat, fmt.Errorf("initialize result: %w", err)
+	}
+	if res.ProtocolVersion == "" {
+		return cat, fmt.Errorf("initialize result has no protocolVersion")
+	}
+	c.version = res.ProtocolVersion
+	cat.ProtocolVersion = res.ProtocolVersion
+	cat.ServerName = res.ServerInfo.Name
+	cat.ServerVersion = res.ServerInfo.Version
+	cat.Capabilities = res.Capabilities
+	if cat.Capabilities == nil {
+		cat.Capabilities = map[string]any{}
+	}
+
+	if err := c.notify(ctx, "notifications/initialized"); err != nil {
+		return cat, fmt.Errorf("initialized notification: %w", err)
+	}
+	return cat, nil
+}
+
+// ListTools pages through tools/list.
+func (c *Client) ListTools(ctx context.Context) ([]store.MCPTool, error) {
+	out := []store.MCPTool{}
+	err := c.paginate(ctx, "tools/list", func(raw json.RawMessage) (string, error) {
+		var page struct {
+			Tools []struct {
+				Name        string         `json:"name"`
+				Title       string         `json:"title"`
+				Description string         `json:"description"`
+				InputSchema map[string]any `json:"inputSchema"`
+			} `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return "", err
+		}
+		for _, t := range page.Tools {
+			out = append(out, store.MCPTool{Name: t.Name, Title: t.Title, Description: t.Description, InputSchema: t.InputSchema})
+		}
+		return page.NextCursor, nil
+	})
+	return out, err
+}
+
+// ListResources pages through resources/list.
+func (c *Client) ListResources(ctx context.Context) ([]store.MCPResource, error) {
+	out := []store.MCPResource{}
+	err := c.paginate(ctx, "resources/list", func(raw json.RawMessage) (string, error) {
+		var page struct {
+			Resources  []store.MCPResource `json:"resources"`
+			NextCursor string              `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return "", err
+		}
+		out = append(out, page.Resources...)
+		return page.NextCursor, nil
+	})
+	return out, err
+}
+
+// ListPrompts pages through prompts/list.
+func (c *Client) ListPrompts(ctx context.Context) ([]store.MCPPrompt, error) {
+	out := []store.MCPPrompt{}
+	err := c.paginate(ctx, "prompts/list", func(raw json.RawMessage) (string, error) {
+		var page struct {
+			Prompts    []store.MCPPrompt `json:"prompts"`
+			NextCursor string            `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return "", err
+		}
+		out = append(out, page.Prompts...)
+		return page.NextCursor, nil
+	})
+	return out, err
+}
+
+func (c *Client) paginate(ctx context.Context, method string, page func(json.RawMessage) (string, error)) error {
+	cursor := ""
+	for i := 0; i < maxPages; i++ {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		raw, err := c.request(ctx, method, params)
+		if err != nil {
+			return err
+		}
+		next, err := page(raw)
+		if err != nil {
+			return err
+		}
+		if next == "" || next == cursor {
+			return nil
+		}
+		cursor = next
+	}
+	return nil
+}
+
+// Close ends the session: DELETE for streamable HTTP, closing the stream for SSE.
+func (c *Client) Close() {
+	if c.legacy != nil {
+		c.legacy.close()
+		return
+	}
+	if c.sessionID == "" {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.url, nil)
+	if err != nil {
+		return
+	}
+	c.setHeaders(req)
+	if resp, err := c.http.Do(req); err == nil {
+		resp.Body.Close()
+	}
+}
+
+func (c *Client) request(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	c.nextID++
+	id := c.nextID
+	body, err := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params})
+	if err != nil {
+		return nil, err
+	}
+
+	var msg rpcMessage
+	if c.legacy != nil {
+		msg, err = c.legacy.roundTrip(ctx, c.http, id, body)
+	} else {
+		msg, err = c.post(ctx, id, body, method == "initialize")
+	}
+	if err != nil {
+		return nil, err
+	}
+	if msg.Error != nil {
+		return nil, msg.Error
+	}
+	return msg.Result, nil
+}
+
+func (c *Client) notify(ctx context.Context, method string) error {
+	body, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "method": method})
+	if c.legacy != nil {
+		return c.legacy.send(ctx, c.http, body)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	c.setHeaders(req)
+	resp, err := c.http.Do(req) // #nosec G107 -- endpoint declared by the agent
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (c *Client) setHeaders(req *http.Request) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	if c.sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", c.sessionID)
+	}
+	if c.version != "" {
+		req.Header.Set("MCP-Protocol-Version", c.version)
+	}
+}
+
+// post sends one streamable HTTP request. The answer is either a JSON body or an
+// SSE stream on which we wait for the response carrying our id.
+func (c *Client) post(ctx context.Context, id int, body []byte, initialize bool) (rpcMessage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return rpcMessage{}, err
+	}
+	c.setHeaders(req)
+	resp, err := c.http.Do(req) // #nosec G107 -- endpoint declared by the agent
+	if err != nil {
+		return rpcMessage{}, err
+	}
+	defer resp.Body.Close()
+
+	if initialize {
+		switch resp.StatusCode {
+		case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusBadRequest:
+			// servers on the 2024-11-05 transport only accept GET for the stream
+			return rpcMessage{}, errLegacyTransport
+		}
+		if sid := resp.Header.Get("Mcp-Session-Id"); sid != "" {
+			c.sessionID = sid
+		}
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return rpcMessage{}, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	if strings.HasPrefix(strings.ToLower(resp.Header.Get("Content-Type")), "text/event-stream") {
+		var found *rpcMessage
+		err := readEvents(resp.Body, func(_ string, data string) bool {
+			var m rpcMessage
+			if json.Unmarshal([]byte(data), &m) == nil && matchesID(m.ID, id) {
+				found = &m
+				return false
+			}
+			return true
+		})
+		if found != nil {
+			return *found, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("stream ended without a response")
+		}
+		return rpcMessage{}, err
+	}
+
+	var m rpcMessage
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 4<<20)).Decode(&m); err != nil {
+		return rpcMessage{}, fmt.Errorf("decode response: %w", err)
+	}
+	return m, nil
+}
+
+func matchesID(raw json.RawMessage, id int) bool {
+	return strings.Trim(strings.TrimSpace(string(raw)), `"`) == fmt.Sprint(id)
+}
+
+// readEvents parses an SSE stream and calls fn per event until it returns false.
+func readEvents(r io.Reader, fn func(event, data string) bool) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 4<<20)
+	event, data := "", []string{}
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				if !fn(event, strings.Join(data, "\n")) {
+					return nil
+				}
+			}
+			event, data = "", data[:0]
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if len(data) > 0 {
+		fn(event, strings.Join(data, "\n"))
+	}
+	return sc.Err()
+}
+
+// sseSession is the legacy HTTP+SSE transport: a long-lived GET stream announces an
+// "endpoint" to POST messages to, and responses come back as "message" events.
+type sseSession struct {
+	endpoint string
+	cancel   context.CancelFunc
+	mu       sync.Mutex
+	waiting  map[string]chan rpcMessage
+	done     chan struct{}
+}
+
+func openSSESession(ctx context.Context, httpClient *http.Client, base string) (*sseSession, error) {
+	streamCtx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodGet, base, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := httpClient.Do(req) // #nosec G107
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+
+	s := &sseSession{cancel: cancel, waiting: map[string]chan rpcMessage{}, done: make(chan struct{})}
+	endpointCh := make(chan string, 1)
+	go func() {
+		defer close(s.done)
+		defer resp.Body.Close()
+		_ = readEvents(resp.Body, func(event, data string) bool {
+			switch event {
+			case "endpoint":
+				select {
+				case endpointCh <- data:
+				default:
+				}
+			case "", "message":
+				var m rpcMessage
+				if json.Unmarshal([]byte(data), &m) == nil && len(m.ID) > 0 {
+					key := strings.Trim(string(m.ID), `"`)
+					s.mu.Lock()
+					ch := s.waiting[key]
+					delete(s.waiting, key)
+					s.mu.Unlock()
+					if ch != nil {
+						ch <- m
+					}
+				}
+			}
+			return true
+		})
+	}()
+
+	select {
+	case ep := <-endpointCh:
+		u, err := url.Parse(base)
+		if err != nil {
+			s.close()
+			return nil, err
+		}
+		ref, err := url.Parse(strings.TrimSpace(ep))
+		if err != nil {
+			s.close()
+			return nil, err
+		}
+		s.endpoint = u.ResolveReference(ref).String()
+		return s, nil
+	case <-s.done:
+		cancel()
+		return nil, fmt.Errorf("stream closed before announcing an endpoint")
+	case <-ctx.Done():
+		s.close()
+		return nil, ctx.Err()
+	}
+}
+
+func (s *sseSession) roundTrip(ctx context.Context, httpClient *http.Client, id int, body []byte) (rpcMessage, error) {
+	ch := make(chan rpcMessage, 1)
+	key := fmt.Sprint(id)
+	s.mu.Lock()
+	s.waiting[key] = ch
+	s.mu.Unlock()
+
+	if err := s.send(ctx, httpClient, body); err != nil {
+		s.mu.Lock()
+		delete(s.waiting, key)
+		s.mu.Unlock()
+		return rpcMessage{}, err
+	}
+	select {
+	case m := <-ch:
+		return m, nil
+	case <-s.done:
+		return rpcMessage{}, fmt.Errorf("sse stream closed")
+	case <-ctx.Done():
+		return rpcMessage{}, ctx.Err()
+	}
+}
+
+func (s *sseSession) send(ctx context.Context, httpClient *http.Client, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := httpClient.Do(req) // #nosec G107
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (s *sseSession) close() {
+	s.cancel()
+	<-s.done
+}
@@ -0,0 +1,139 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type rpcRequest struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params map[string]any  `json:"params"`
+}
+
+func result(id json.RawMessage, v any) []byte {
+	b, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": id, "result": v})
+	return b
+}
+
+// answer implements the server side shared by both transports.
+func answer(req rpcRequest) any {
+	switch req.Method {
+	case "initialize":
+		return map[string]any{
+			"protocolVersion": "2025-06-18",
+			"capabilities":    map[string]any{"tools": map[string]any{}, "prompts": map[string]any{}},
+			"serverInfo":      map[string]any{"name": "stand-in", "version": "0.1.0"},
+		}
+	case "tools/list":
+		if req.Params["cursor"] == "page-2" {
+			return map[string]any{"tools": []any{map[string]any{"name": "get_weather", "description": "Forecast", "inputSchema": map[string]any{"type": "object"}}}}
+		}
+		return map[string]any{"tools": []any{map[string]any{"name": "search_docs"}}, "nextCursor": "page-2"}
+	case "prompts/list":
+		return map[string]any{"prompts": []any{map[string]any{"name": "summarize", "arguments": []any{map[string]any{"name": "text"}}}}}
+	}
+	return nil
+}
+
+func TestIntrospect_StreamableHTTP(t *testing.T) {
+	var sessionClosed, sawResources atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			sessionClosed.Store(r.Header.Get("Mcp-Session-Id") == "sess-42")
+			return
+		}
+		var req rpcRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.Method != "initialize" && r.Header.Get("Mcp-Session-Id") != "sess-42" {
+			http.Error(w, "missing session", http.StatusBadRequest)
+			return
+		}
+		switch req.Method {
+		case "initialize":
+			w.Header().Set("Mcp-Session-Id", "sess-42")
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(result(req.ID, answer(req)))
+		case "notifications/initialized":
+			w.WriteHeader(http.StatusAccepted)
+		case "resources/list":
+			sawResources.Store(true)
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			// answer over SSE, preceded by an unrelated notification
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = fmt.Fprint(w, "event: message\ndata: {\"jsonrpc\":\"2.0\",\"method\":\"notifications/progress\"}\n\n")
+			_, _ = fmt.Fprintf(w, "event: message\ndata: %s\n\n", result(req.ID, answer(req)))
+		}
+	}))
+	defer srv.Close()
+
+	cat, err := Introspect(context.Background(), nil, srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cat.ServerName != "stand-in" || cat.ProtocolVersion != "2025-06-18" {
+		t.Fatalf("unexpected server info: %+v", cat)
+	}
+	if len(cat.Tools) != 2 || cat.Tools[1].Name != "get_weather" || cat.Tools[1].InputSchema["type"] != "object" {
+		t.Fatalf("expected both pages of tools, got %+v", cat.Tools)
+	}
+	if len(cat.Prompts) != 1 || cat.Prompts[0].Name != "summarize" {
+		t.Fatalf("unexpected prompts: %+v", cat.Prompts)
+	}
+	if sawResources.Load() {
+		t.Fatal("resources/list must not be called without the resources capability")
+	}
+	if !sessionClosed.Load() {
+		t.Fatal("expected the session to be closed with DELETE")
+	}
+}
+
+func TestIntrospect_LegacySSEFallback(t *testing.T) {
+	events := make(chan []byte, 8)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/sse", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = fmt.Fprint(w, "event: endpoint\ndata: /messages?session=1\n\n")
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case b := <-events:
+				_, _ = fmt.Fprintf(w, "event: message\ndata: %s\n\n", b)
+				w.(http.Flusher).Flush()
+			}
+		}
+	})
+	mux.HandleFunc("/messages", func(w http.ResponseWriter, r *http.Request) {
+		var req rpcRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.WriteHeader(http.StatusAccepted)
+		if len(req.ID) > 0 {
+			events <- result(req.ID, answer(req))
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cat, err := Introspect(ctx, nil, srv.URL+"/sse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cat.Tools) != 2 || cat.ServerVersion != "0.1.0" {
+		t.Fatalf("unexpected catalog over legacy transport: %+v", cat)
+	}
+}
@@ -0,0 +1,109 @@
+// Package metrics holds the Prometheus collectors the explorer exports on /metrics.
+// They are registered with the default registry, next to the Go runtime and process
+// collectors.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "praxis"
+
+var (
+	// IdentityEvents counts identity registry logs handled, by chain and event
+	// (registered, updated, registered_v1 or unknown).
+	IdentityEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "indexer", Name: "identity_events_total",
+		Help: "Identity registry logs handled, by chain and event.",
+	}, []string{"chain", "event"})
+
+	// CardFetches counts agent card fetches, by chain and outcome: ok or the class
+	// of the error that stopped it.
+	CardFetches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "indexer", Name: "card_fetches_total",
+		Help: "Agent card fetches, by chain and outcome (ok or error class).",
+	}, []string{"chain", "outcome"})
+
+	// URIFetches counts registration and metadata fetches through the URI
+	// resolver, by scheme and outcome (ok or error).
+	URIFetches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "fetcher", Name: "fetches_total",
+		Help: "URI resolver fetches, by scheme and outcome.",
+	}, []string{"scheme", "outcome"})
+
+	// RPCDuration is the latency of chain RPC calls, by chain and method.
+	RPCDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "rpc", Name: "duration_seconds",
+		Help:    "Chain RPC call latency, by chain and method.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"chain", "method"})
+
+	// RPCErrors counts failed chain RPC calls, by chain and method.
+	RPCErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "rpc", Name: "errors_total",
+		Help: "Failed chain RPC calls, by chain and method.",
+	}, []string{"chain", "method"})
+
+	// HeadLag is how many blocks the chain head is ahead of the indexer checkpoint.
+	HeadLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "indexer", Name: "head_lag_blocks",
+		Help: "Blocks between the chain head and the indexer checkpoint, by chain.",
+	}, []string{"chain"})
+
+	// StoreDuration is the latency of database statements, by the store method
+	// that issued them.
+	StoreDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "store", Name: "query_duration_seconds",
+		Help:    "Database statement latency, by store method.",
+		Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+	}, []string{"method"})
+
+	// HTTPRequests counts API requests, by method, route template and status.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "http", Name: "requests_total",
+		Help: "API requests, by method, route template and status.",
+	}, []string{"method", "route", "status"})
+
+	// HTTPDuration is API request latency, by method and route template.
+	HTTPDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "http", Name: "request_duration_seconds",
+		Help:    "API request latency, by method and route template.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// Handler serves the default registry in the Prometheus exposition format.
+func Handler() http.Handler { return promhttp.Handler() }
+
+// ObserveRPC records one chain RPC call that began at start and returned err.
+func ObserveRPC(chain, method string, start time.Time, err error) {
+	RPCDuration.WithLabelValues(chain, method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		RPCErrors.WithLabelValues(chain, method).Inc()
+	}
+}
+
+// NetClass names the class of a network error: timeout, dns, canceled or network.
+func NetClass(err error) string {
+	var dns *net.DNSError
+	var ne net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.As(err, &dns):
+		return "dns"
+	case errors.As(err, &ne) && ne.Timeout():
+		return "timeout"
+	}
+	return "network"
+}
@@ -0,0 +1,223 @@
+// Package openapi holds the checked-in OpenAPI 3.1 description of the REST API and
+// validates requests (and, in test mode, responses) against it.
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed openapi.yaml
+var specYAML []byte
+
+// resourceURL is the base the spec is registered under for $ref resolution.
+const resourceURL = "https://praxis-explorer/openapi.json"
+
+var methods = []string{"get", "put", "post", "delete", "patch", "head", "options"}
+
+// Spec is the parsed, schema-compiled OpenAPI document.
+type Spec struct {
+	json []byte
+	ops  map[string]*operation // "GET /agents/:chainId/:agentId" (gin path syntax)
+}
+
+type parameter struct {
+	name     string
+	in       string
+	required bool
+	typ      string // JSON type used to coerce the raw string value
+	itemType string
+	explode  bool // arrays: one item per repeated parameter rather than comma-separated
+	schema   *jsonschema.Schema
+}
+
+type operation struct {
+	id           string
+	params       []parameter
+	body         *jsonschema.Schema
+	bodyRequired bool
+	responses    map[string]*jsonschema.Schema // status code or "default" -> JSON body schema
+	statuses     map[string]bool               // every documented status, with or without a body
+	streaming    bool                          // x-streaming: long-lived response, never buffered
+}
+
+// Load parses the embedded spec and compiles every parameter, body and response schema.
+func Load() (*Spec, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(specYAML, &doc); err != nil {
+		return nil, fmt.Errorf("openapi: parse: %w", err)
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: %w", err)
+	}
+	jdoc, err := jsonschema.UnmarshalJSON(bytes.NewReader(raw))
+	if err != nil {
+		return nil, err
+	}
+	c := jsonschema.NewCompiler()
+	c.DefaultDraft(jsonschema.Draft2020)
+	if err := c.AddResource(resourceURL, jdoc); err != nil {
+		return nil, err
+	}
+	compile := func(ptr string) (*jsonschema.Schema, error) {
+		return c.Compile(resourceURL + "#" + ptr)
+	}
+
+	s := &Spec{json: raw, ops: map[string]*operation{}}
+	paths, _ := doc["paths"].(map[string]any)
+	for path, item := range paths {
+		pitem, _ := item.(map[string]any)
+		pathPtr := "/paths/" + escape(path)
+		for _, m := range methods {
+			op, ok := pitem[m].(map[string]any)
+			if !ok {
+				continue
+			}
+			opPtr := pathPtr + "/" + m
+			o := &operation{responses: map[string]*jsonschema.Schema{}, statuses: map[string]bool{}}
+			o.id, _ = op["operationId"].(string)
+			o.streaming, _ = op["x-streaming"].(bool)
+
+			// path-level parameters first, overridden by operation-level ones
+			byName := map[string]int{}
+			for _, src := range []struct {
+				list any
+				ptr  string
+			}{{pitem["parameters"], pathPtr + "/parameters"}, {op["parameters"], opPtr + "/parameters"}} {
+				list, _ := src.list.([]any)
+				for i, p := range list {
+					pm, ptr := resolve(doc, p.(map[string]any), fmt.Sprintf("%s/%d", src.ptr, i))
+					sch, err := compile(ptr + "/schema")
+					if err != nil {
+						return nil, fmt.Errorf("openapi: %s %s: %w", m, path, err)
+					}
+					param := parameter{schema: sch}
+					param.name, _ = pm["name"].(string)
+					param.in, _ = pm["in"].(string)
+					param.required, _ = pm["required"].(bool)
+					// form style explodes unless told otherwise
+					param.explode = true
+					if v, ok := pm["explode"].(bool); ok {
+						param.explode = v
+					}
+					if sm, ok := pm["schema"].(map[string]any); ok {
+						sm, _ = resolve(doc, sm, "")
+						param.typ, _ = sm["type"].(string)
+						if items, ok := sm["items"].(map[string]any); ok {
+							param.itemType, _ = items["type"].(string)
+						}
+					}
+					key := param.in + ":" + param.name
+					if idx, ok := byName[key]; ok {
+						o.params[idx] = param
+					} else {
+						byName[key] = len(o.params)
+						o.params = append(o.params, param)
+					}
+				}
+			}
+
+			if rb, ok := op["requestBody"].(map[string]any); ok {
+				o.bodyRequired, _ = rb["required"].(bool)
+				if sch, err := compile(opPtr + "/requestBody/content/application~1json/schema"); err == nil {
+					o.body = sch
+				} else {
+					return nil, fmt.Errorf("openapi: %s %s body: %w", m, path, err)
+				}
+			}
+
+			responses, _ := op["responses"].(map[string]any)
+			for code, r := range responses {
+				o.statuses[code] = true
+				rm, ptr := resolve(doc, r.(map[string]any), opPtr+"/responses/"+escape(code))
+				content, _ := rm["content"].(map[string]any)
+				var media string
+				for _, mt := range []string{"application/json", "application/problem+json"} {
+					if _, ok := content[mt]; ok {
+						media = mt
+						break
+					}
+				}
+				if media == "" {
+					continue
+				}
+				sch, err := compile(ptr + "/content/" + escape(media) + "/schema")
+				if err != nil {
+					return nil, fmt.Errorf("openapi: %s %s response %s: %w", m, path, code, err)
+				}
+				o.responses[code] = sch
+			}
+
+			s.ops[strings.ToUpper(m)+" "+ginPath(path)] = o
+		}
+	}
+	return s, nil
+}
+
+// MustLoad is Load for package initialization; the spec is embedded, so failure is a bug.
+func MustLoad() *Spec {
+	s, err := Load()
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// JSON returns the spec as a JSON document.
+func (s *Spec) JSON() []byte { return s.json }
+
+// Routes lists the documented operations as "METHOD /gin/:path", sorted.
+func (s *Spec) Routes() []string {
+	out := make([]string, 0, len(s.ops))
+	for k := range s.ops {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Handler serves the spec.
+func (s *Spec) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", s.json)
+	}
+}
+
+// resolve follows a local $ref (as used for shared parameters and responses) and
+// returns the target object with its JSON pointer.
+func resolve(doc map[string]any, obj map[string]any, ptr string) (map[string]any, string) {
+	ref, ok := obj["$ref"].(string)
+	if !ok || !strings.HasPrefix(ref, "#/") {
+		return obj, ptr
+	}
+	var cur any = doc
+	for _, tok := range strings.Split(ref[2:], "/") {
+		tok = strings.ReplaceAll(strings.ReplaceAll(tok, "~1", "/"), "~0", "~")
+		m, _ := cur.(map[string]any)
+		cur = m[tok]
+	}
+	target, _ := cur.(map[string]any)
+	return target, ref[1:]
+}
+
+func escape(tok string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(tok, "~", "~0"), "/", "~1")
+}
+
+var templateParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// ginPath converts /agents/{chainId} to gin's /agents/:chainId.
+func ginPath(p string) string {
+	return templateParam.ReplaceAllString(p, ":$1")
+}
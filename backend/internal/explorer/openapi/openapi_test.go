@@ -0,0 +1,124 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+)
+
+func newEngine(t *testing.T, opts Options, agents gin.HandlerFunc) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	r := gin.New()
+	r.Use(spec.Middleware(opts))
+	r.GET("/agents", agents)
+	r.POST("/admin/keys", func(c *gin.Context) {
+		c.JSON(http.StatusCreated, gin.H{"id": 1, "key": "pxk_x", "prefix": "pxk_x", "role": "operator"})
+	})
+	return r
+}
+
+func do(r http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) problem.Details {
+	t.Helper()
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, problem.ContentType) {
+		t.Fatalf("content type %q, want %s", ct, problem.ContentType)
+	}
+	var p problem.Details
+	if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+		t.Fatalf("decode problem: %v", err)
+	}
+	return p
+}
+
+func TestLoadDocumentsRoutes(t *testing.T) {
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	routes := strings.Join(spec.Routes(), "\n")
+	for _, want := range []string{"GET /agents", "GET /agents/:chainId/:agentId", "POST /admin/keys"} {
+		if !strings.Contains(routes, want) {
+			t.Errorf("missing %s in %v", want, spec.Routes())
+		}
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(spec.JSON(), &doc); err != nil || doc["openapi"] != "3.1.0" {
+		t.Fatalf("JSON() = %v, %v", doc["openapi"], err)
+	}
+}
+
+func TestInvalidQueryParameter(t *testing.T) {
+	called := false
+	r := newEngine(t, Options{}, func(c *gin.Context) { called = true })
+
+	for _, target := range []string{"/agents?limit=abc", "/agents?limit=0", "/agents?status=sideways", "/agents?facets=network,color", "/agents?status=up&status=sideways", "/agents?match=some", "/agents?sort=newest", "/agents?sort=score&order=up"} {
+		w := do(r, http.MethodGet, target, "")
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: status %d, want 400", target, w.Code)
+		}
+		p := decodeProblem(t, w)
+		if p.Status != http.StatusBadRequest || len(p.Errors) == 0 || p.Instance != "/agents" {
+			t.Errorf("%s: problem %+v", target, p)
+		}
+	}
+	if called {
+		t.Error("handler ran for an invalid request")
+	}
+	if w := do(r, http.MethodGet, "/agents?limit=10&facets=network,tag&status=up&status=-down&tag=a,b&match=any&sort=score&order=asc", ""); w.Code != http.StatusOK || !called {
+		t.Errorf("valid request: status %d, called %v: %s", w.Code, called, w.Body)
+	}
+}
+
+func TestRequestBody(t *testing.T) {
+	r := newEngine(t, Options{}, func(c *gin.Context) {})
+	if w := do(r, http.MethodPost, "/admin/keys", `{"role":"operator"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("missing name: status %d", w.Code)
+	}
+	if w := do(r, http.MethodPost, "/admin/keys", `{not json`); w.Code != http.StatusBadRequest {
+		t.Errorf("bad json: status %d", w.Code)
+	}
+	if w := do(r, http.MethodPost, "/admin/keys", `{"name":"ci","role":"operator"}`); w.Code != http.StatusCreated {
+		t.Errorf("valid body: status %d %s", w.Code, w.Body)
+	}
+}
+
+func TestResponseValidation(t *testing.T) {
+	good := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"items": []any{}, "nextCursor": ""}) }
+	bad := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"items": nil}) }
+
+	if w := do(newEngine(t, Options{ValidateResponses: true}, good), http.MethodGet, "/agents", ""); w.Code != http.StatusOK {
+		t.Fatalf("conforming response: status %d %s", w.Code, w.Body)
+	}
+
+	w := do(newEngine(t, Options{ValidateResponses: true}, bad), http.MethodGet, "/agents", "")
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status %d, want 500", w.Code)
+	}
+	if p := decodeProblem(t, w); len(p.Errors) == 0 {
+		t.Errorf("no validation errors in %+v", p)
+	}
+
+	// without response validation the handler's output goes through untouched
+	if w := do(newEngine(t, Options{}, bad), http.MethodGet, "/agents", ""); w.Code != http.StatusOK {
+		t.Errorf("status %d, want 200", w.Code)
+	}
+}
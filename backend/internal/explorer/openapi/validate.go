@@ -0,0 +1,242 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	log "github.com/sirupsen/logrus"
+)
+
+const maxBody = 1 << 20
+
+// Options controls the validation middleware.
+type Options struct {
+	// ValidateResponses buffers every documented response and replaces it with a 500
+	// problem when it doesn't match the spec. Meant for tests and gin.TestMode.
+	ValidateResponses bool
+}
+
+// Middleware rejects requests whose parameters or JSON body don't match the spec with
+// a 400 problem. Routes missing from the spec pass through; the drift test in the api
+// package keeps that set empty.
+func (s *Spec) Middleware(opts Options) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := s.ops[c.Request.Method+" "+c.FullPath()]
+		if op == nil {
+			c.Next()
+			return
+		}
+		if errs := op.validateRequest(c); len(errs) > 0 {
+			problem.Abort(c, http.StatusBadRequest, "request does not match the API specification", errs...)
+			return
+		}
+		if !opts.ValidateResponses || op.streaming {
+			c.Next()
+			return
+		}
+
+		rec := &recorder{ResponseWriter: c.Writer, status: http.StatusOK}
+		c.Writer = rec
+		c.Next()
+		c.Writer = rec.ResponseWriter
+
+		if errs := op.validateResponse(rec.status, rec.body.Bytes()); len(errs) > 0 {
+			log.WithFields(log.Fields{"operation": op.id, "status": rec.status, "errors": errs}).Error("[openapi] response does not match the API specification")
+			problem.Write(c, http.StatusInternalServerError, fmt.Sprintf("%s response (status %d) does not match the API specification", op.id, rec.status), errs...)
+			return
+		}
+		rec.flush()
+	}
+}
+
+func (o *operation) validateRequest(c *gin.Context) []string {
+	var errs []string
+	query := c.Request.URL.Query()
+	for _, p := range o.params {
+		var raw []string
+		switch p.in {
+		case "path":
+			if v := c.Param(p.name); v != "" {
+				raw = []string{v}
+			}
+		case "query":
+			raw = query[p.name]
+		case "header":
+			if v := c.GetHeader(p.name); v != "" {
+				raw = []string{v}
+			}
+		default:
+			continue
+		}
+		where := fmt.Sprintf("%s parameter %q", p.in, p.name)
+		if len(raw) == 0 {
+			if p.required {
+				errs = append(errs, where+" is required")
+			}
+			continue
+		}
+		v, err := coerce(raw, p.typ, p.itemType, p.explode)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s %v", where, err))
+			continue
+		}
+		if err := p.schema.Validate(v); err != nil {
+			errs = append(errs, describe(err, where)...)
+		}
+	}
+
+	if o.body == nil {
+		return errs
+	}
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		body, err = io.ReadAll(io.LimitReader(c.Request.Body, maxBody))
+		if err != nil {
+			return append(errs, "request body could not be read")
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if o.bodyRequired {
+			errs = append(errs, "request body is required")
+		}
+		return errs
+	}
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return append(errs, "request body is not valid JSON")
+	}
+	if err := o.body.Validate(v); err != nil {
+		errs = append(errs, describe(err, "request body")...)
+	}
+	return errs
+}
+
+func (o *operation) validateResponse(status int, body []byte) []string {
+	sch, ok := o.responses[strconv.Itoa(status)]
+	if !ok {
+		sch, ok = o.responses["default"]
+	}
+	if !ok {
+		if o.statuses[strconv.Itoa(status)] {
+			return nil // documented without a JSON body (e.g. 204)
+		}
+		return []string{fmt.Sprintf("status %d is not documented", status)}
+	}
+	v, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return []string{"response body is not valid JSON"}
+	}
+	if err := sch.Validate(v); err != nil {
+		return describe(err, "response body")
+	}
+	return nil
+}
+
+// coerce turns raw parameter strings into the JSON value the schema expects.
+func coerce(raw []string, typ, itemType string, explode bool) (any, error) {
+	if typ == "array" {
+		out := make([]any, 0, len(raw))
+		for _, r := range raw {
+			parts := []string{r}
+			if !explode {
+				parts = strings.Split(r, ",")
+			}
+			for _, part := range parts {
+				v, err := coerceOne(part, itemType)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, v)
+			}
+		}
+		return out, nil
+	}
+	return coerceOne(raw[0], typ)
+}
+
+func coerceOne(s, typ string) (any, error) {
+	switch typ {
+	case "integer":
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return nil, fmt.Errorf("must be an integer, got %q", s)
+		}
+		return json.Number(s), nil
+	case "number":
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return nil, fmt.Errorf("must be a number, got %q", s)
+		}
+		return json.Number(s), nil
+	case "boolean":
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return nil, fmt.Errorf("must be a boolean, got %q", s)
+		}
+		return b, nil
+	default:
+		return s, nil
+	}
+}
+
+// describe flattens a schema validation error into one line per failed keyword.
+func describe(err error, where string) []string {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return []string{where + ": " + err.Error()}
+	}
+	var out []string
+	seen := map[string]bool{}
+	for _, u := range ve.BasicOutput().Errors {
+		if u.Error == nil || len(u.Errors) > 0 {
+			continue
+		}
+		msg := u.Error.String()
+		if strings.HasPrefix(msg, "validation failed") || strings.HasPrefix(msg, "jsonschema validation failed") {
+			continue
+		}
+		line := where + u.InstanceLocation + ": " + msg
+		if !seen[line] {
+			seen[line] = true
+			out = append(out, line)
+		}
+	}
+	if len(out) == 0 {
+		out = append(out, where+": "+strings.ReplaceAll(ve.Error(), "\n", "; "))
+	}
+	return out
+}
+
+// recorder buffers a response so it can be validated before it is sent.
+type recorder struct {
+	gin.ResponseWriter
+	status  int
+	written bool
+	body    bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(code int) {
+	r.status = code
+	r.written = true
+}
+func (r *recorder) WriteHeaderNow()                   { r.written = true }
+func (r *recorder) Write(b []byte) (int, error)       { r.written = true; return r.body.Write(b) }
+func (r *recorder) WriteString(s string) (int, error) { r.written = true; return r.body.WriteString(s) }
+func (r *recorder) Status() int                       { return r.status }
+func (r *recorder) Size() int                         { return r.body.Len() }
+func (r *recorder) Written() bool                     { return r.written }
+func (r *recorder) Flush()                            {}
+
+func (r *recorder) flush() {
+	r.ResponseWriter.WriteHeader(r.status)
+	_, _ = r.ResponseWriter.Write(r.body.Bytes())
+}
@@ -0,0 +1,54 @@
+// Package problem writes RFC 7807 problem+json error responses, the single error
+// format of the REST API.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContentType is the media type of problem responses.
+const ContentType = "application/problem+json"
+
+// Details is an RFC 7807 problem object. Errors lists individual validation failures.
+type Details struct {
+	Type     string   `json:"type"`
+	Title    string   `json:"title"`
+	Status   int      `json:"status"`
+	Detail   string   `json:"detail,omitempty"`
+	Instance string   `json:"instance,omitempty"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// New builds a problem for status; the title is the standard status text.
+func New(status int, detail string, errs ...string) Details {
+	return Details{Type: "about:blank", Title: http.StatusText(status), Status: status, Detail: detail, Errors: errs}
+}
+
+// Write sends a problem response for the current request.
+func Write(c *gin.Context, status int, detail string, errs ...string) {
+	p := New(status, detail, errs...)
+	p.Instance = c.Request.URL.Path
+	c.Header("Content-Type", ContentType)
+	c.Render(status, problemRender{p})
+}
+
+// Abort writes a problem response and stops the handler chain.
+func Abort(c *gin.Context, status int, detail string, errs ...string) {
+	Write(c, status, detail, errs...)
+	c.Abort()
+}
+
+// problemRender is JSON rendering that keeps the problem+json content type.
+type problemRender struct{ p Details }
+
+func (r problemRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return json.NewEncoder(w).Encode(r.p)
+}
+
+func (r problemRender) WriteContentType(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", ContentType)
+}
@@ -8,8 +8,12 @@ import (
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	api "github.com/praxis/praxis-explorer/internal/explorer/api"
+	"github.com/praxis/praxis-explorer/internal/explorer/auth"
 	"github.com/praxis/praxis-explorer/internal/explorer/indexer"
+	"github.com/praxis/praxis-explorer/internal/explorer/logging"
 	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	"github.com/praxis/praxis-explorer/internal/explorer/tracing"
+	"github.com/praxis/praxis-explorer/internal/explorer/webhook"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -17,6 +21,8 @@ type Server struct {
 	store   *store.Postgres
 	indexer *indexer.Indexer
 	http    *gin.Engine
+	// flushes buffered spans
+	traceShutdown func(context.Context) error
 }
 
 func NewServerFromEnv() (*Server, error) {
@@ -26,11 +32,28 @@ func NewServerFromEnv() (*Server, error) {
 		cfgPath = "configs/erc8004.yaml"
 	}
 
+	// configure logging before anything else logs; credentials in DATABASE_URL
+	// and RPC URLs are redacted from here on
+	lc, err := logging.LoadFile(cfgPath)
+	if err != nil {
+		log.WithError(err).Warn("failed to read logging config; using defaults")
+	}
+	if err := logging.Setup(lc); err != nil {
+		log.WithError(err).Warn("invalid logging config; using defaults")
+		_ = logging.Setup(logging.DefaultConfig())
+	}
+
 	log.WithFields(log.Fields{
 		"DATABASE_URL":   dbURL,
 		"ERC8004_CONFIG": cfgPath,
 	}).Info("initializing server with env vars")
 
+	traceShutdown, err := tracing.Setup(context.Background(), tracing.ConfigFromEnv())
+	if err != nil {
+		log.WithError(err).Error("failed to set up tracing")
+		return nil, err
+	}
+
 	psql, err := store.NewPostgres(dbURL)
 	if err != nil {
 		log.WithError(err).Error("failed to connect to Postgres")
@@ -42,19 +65,42 @@ func NewServerFromEnv() (*Server, error) {
 		log.WithError(err).Error("failed to create indexer")
 		return nil, err
 	}
+	// addresses stored before they were normalized (bare, lowercase) as CAIP-10
+	if n, err := psql.NormalizeAgentAddresses(context.Background()); err != nil {
+		log.WithError(err).Warn("failed to normalize agent addresses")
+	} else if n > 0 {
+		log.WithField("agents", n).Info("normalized agent addresses to CAIP-10")
+	}
 
-	r := gin.Default()
+	// gin.Default would add gin's own text access log; api logs requests through logging
+	r := gin.New()
+	r.Use(gin.Recovery())
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "X-API-Key", "X-Request-ID"},
+		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
 		AllowCredentials: false,
 		MaxAge:           12 * time.Hour,
 	}))
 
-	s := &Server{store: psql, indexer: ix, http: r}
-	api.RegisterRoutes(r, s.store)
+	// Bootstrap operator key so a fresh deployment can mint further keys via /admin/keys
+	if key := os.Getenv("EXPLORER_ADMIN_API_KEY"); key != "" {
+		if err := psql.EnsureAPIKey(context.Background(), "bootstrap", auth.HashToken(key), auth.KeyPrefix(key), auth.RoleOperator); err != nil {
+			log.WithError(err).Error("failed to register bootstrap admin key")
+			return nil, err
+		}
+	} else {
+		log.Warn("EXPLORER_ADMIN_API_KEY not set; /admin only accepts keys already in the database")
+	}
+	authCfg := auth.ConfigFromEnv()
+	if authCfg.SIWEDomain == "" {
+		log.Warn("EXPLORER_SIWE_DOMAIN not set; SIWE sign-in is disabled")
+	}
+	authn := auth.New(psql, authCfg)
+
+	s := &Server{store: psql, indexer: ix, http: r, traceShutdown: traceShutdown}
+	api.RegisterRoutes(r, s.store, authn, s.indexer)
 
 	log.Info("server initialized successfully")
 	return s, nil
@@ -62,3 +108,34 @@ func NewServerFromEnv() (*Server, error) {
 
 func (s *Server) RunIndexer()               { go s.indexer.Start(context.Background()) }
 func (s *Server) RunHTTP(addr string) error { return s.http.Run(addr) }
+
+// Close flushes spans still buffered for the trace exporter.
+func (s *Server) Close(ctx context.Context) error { return s.traceShutdown(ctx) }
+
+// RunEmbeddings embeds agents stored before similarity search existed, or by an
+// older embed.Model; new and changed cards are embedded as they are stored.
+func (s *Server) RunEmbeddings() {
+	go func() {
+		ctx := context.Background()
+		total := 0
+		for {
+			n, err := s.store.EmbedMissing(ctx, 500)
+			if err != nil {
+				log.WithError(err).Warn("[embed] backfill failed")
+				return
+			}
+			if n == 0 {
+				break
+			}
+			total += n
+		}
+		if total > 0 {
+			log.WithField("agents", total).Info("[embed] backfilled agent embeddings")
+		}
+	}()
+}
+
+// RunWebhooks delivers indexer events to webhook subscriptions.
+func (s *Server) RunWebhooks() {
+	go webhook.New(s.store, webhook.ConfigFromEnv()).Run(context.Background())
+}
@@ -0,0 +1,327 @@
+// Package snapshot writes and restores signed archives of the agent index, so a new
+// explorer can start from another's data instead of replaying every registry over
+// rate-limited RPCs and re-fetching every card.
+//
+// An archive is a tar of, in this order:
+//
+//	manifest.json       format, version, creation time, signer, and per table its
+//	                    file, columns, row count and SHA-256
+//	manifest.sig        hex ed25519 signature of manifest.json
+//	<table>.ndjson.gz   one JSON row per line, for each table in manifest order
+//
+// The signature covers the manifest and the manifest pins every file's hash, so a
+// restore checks all of it before committing anything.
+package snapshot
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const (
+	// Format identifies snapshot manifests.
+	Format = "praxis-explorer-snapshot"
+	// Version is the archive layout written by Create; Restore reads only this one.
+	Version = 1
+
+	manifestFile  = "manifest.json"
+	signatureFile = "manifest.sig"
+	// restoreBatch is how many rows are inserted per statement.
+	restoreBatch = 500
+	// maxManifest bounds the manifest and signature read before verification.
+	maxManifest = 1 << 20
+)
+
+// Manifest describes an archive.
+type Manifest struct {
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Signer is the hex public key the archive was signed with; Restore trusts the
+	// key it is given, never this one.
+	Signer string  `json:"signer"`
+	Tables []Table `json:"tables"`
+}
+
+// Table is one table's file in an archive.
+type Table struct {
+	Name    string   `json:"name"`
+	File    string   `json:"file"`
+	Columns []string `json:"columns"`
+	Rows    int64    `json:"rows"`
+	// SHA256 is the hex digest of the compressed file.
+	SHA256 string `json:"sha256"`
+}
+
+// Store is the database side of snapshots; store.Postgres implements it.
+type Store interface {
+	DumpSnapshot(ctx context.Context, table func(name string, columns []string) error, row func(data []byte) error) error
+	RestoreSnapshot(ctx context.Context, fn func(insert func(table string, columns []string, rows []json.RawMessage) error) error) error
+}
+
+// GenerateKey returns a new signing key pair, hex-encoded: the public key and the
+// private key's 32-byte seed.
+func GenerateKey() (public, private string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+	return hex.EncodeToString(pub), hex.EncodeToString(priv.Seed()), nil
+}
+
+// ParsePrivateKey decodes a hex private key seed as printed by GenerateKey.
+func ParsePrivateKey(s string) (ed25519.PrivateKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != ed25519.SeedSize {
+		return nil, errors.New("snapshot: private key must be 64 hex characters")
+	}
+	return ed25519.NewKeyFromSeed(b), nil
+}
+
+// ParsePublicKey decodes a hex public key as printed by GenerateKey.
+func ParsePublicKey(s string) (ed25519.PublicKey, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil || len(b) != ed25519.PublicKeySize {
+		return nil, errors.New("snapshot: public key must be 64 hex characters")
+	}
+	return ed25519.PublicKey(b), nil
+}
+
+// spool is a table file being written to a temporary directory.
+type spool struct {
+	table Table
+	path  string
+	f     *os.File
+	gz    *gzip.Writer
+	sum   hash.Hash
+}
+
+func (s *spool) close() error {
+	if err := s.gz.Close(); err != nil {
+		s.f.Close()
+		return err
+	}
+	s.table.SHA256 = hex.EncodeToString(s.sum.Sum(nil))
+	return s.f.Close()
+}
+
+// Create dumps st into a signed archive written to w. Table files are spooled to
+// a temporary directory first, since the manifest that leads the archive needs
+// their hashes.
+func Create(ctx context.Context, st Store, w io.Writer, key ed25519.PrivateKey, now time.Time) (*Manifest, error) {
+	dir, err := os.MkdirTemp("", "praxis-snapshot-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var spools []*spool
+	var cur *spool
+	err = st.DumpSnapshot(ctx,
+		func(name string, columns []string) error {
+			if cur != nil {
+				if err := cur.close(); err != nil {
+					return err
+				}
+			}
+			file := name + ".ndjson.gz"
+			f, err := os.Create(filepath.Join(dir, file))
+			if err != nil {
+				return err
+			}
+			sum := sha256.New()
+			cur = &spool{table: Table{Name: name, File: file, Columns: columns}, path: f.Name(), f: f, sum: sum}
+			cur.gz = gzip.NewWriter(io.MultiWriter(f, sum))
+			spools = append(spools, cur)
+			return nil
+		},
+		func(data []byte) error {
+			if cur == nil {
+				return errors.New("snapshot: row before its table")
+			}
+			cur.table.Rows++
+			if _, err := cur.gz.Write(data); err != nil {
+				return err
+			}
+			_, err := cur.gz.Write([]byte{'\n'})
+			return err
+		})
+	if err == nil && cur != nil {
+		err = cur.close()
+	}
+	if err != nil {
+		for _, s := range spools {
+			s.f.Close()
+		}
+		return nil, err
+	}
+
+	m := &Manifest{Format: Format, Version: Version, CreatedAt: now.UTC(), Signer: hex.EncodeToString(key.Public().(ed25519.PublicKey))}
+	for _, s := range spools {
+		m.Tables = append(m.Tables, s.table)
+	}
+	manifest, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	sig := []byte(hex.EncodeToString(ed25519.Sign(key, manifest)))
+
+	tw := tar.NewWriter(w)
+	put := func(name string, size int64, body io.Reader) error {
+		if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: size, ModTime: m.CreatedAt, Format: tar.FormatPAX}); err != nil {
+			return err
+		}
+		_, err := io.Copy(tw, body)
+		return err
+	}
+	if err := put(manifestFile, int64(len(manifest)), bytes.NewReader(manifest)); err != nil {
+		return nil, err
+	}
+	if err := put(signatureFile, int64(len(sig)), bytes.NewReader(sig)); err != nil {
+		return nil, err
+	}
+	for _, s := range spools {
+		f, err := os.Open(s.path)
+		if err != nil {
+			return nil, err
+		}
+		fi, err := f.Stat()
+		if err == nil {
+			err = put(s.table.File, fi.Size(), f)
+		}
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m, tw.Close()
+}
+
+// Restore loads an archive into st in one transaction. With a trusted key the
+// manifest signature must verify against it; a nil key skips that check, but file
+// hashes and row counts are always checked, and any mismatch rolls everything back.
+func Restore(ctx context.Context, st Store, r io.Reader, trusted ed25519.PublicKey) (*Manifest, error) {
+	tr := tar.NewReader(r)
+	manifest, err := readSmall(tr, manifestFile)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := readSmall(tr, signatureFile)
+	if err != nil {
+		return nil, err
+	}
+	if trusted != nil {
+		raw, err := hex.DecodeString(string(bytes.TrimSpace(sig)))
+		if err != nil || !ed25519.Verify(trusted, manifest, raw) {
+			return nil, errors.New("snapshot: signature does not verify against the trusted key")
+		}
+	}
+	var m Manifest
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return nil, fmt.Errorf("snapshot: manifest: %w", err)
+	}
+	if m.Format != Format || m.Version != Version {
+		return nil, fmt.Errorf("snapshot: unsupported archive %q version %d", m.Format, m.Version)
+	}
+
+	err = st.RestoreSnapshot(ctx, func(insert func(string, []string, []json.RawMessage) error) error {
+		for _, t := range m.Tables {
+			if err := restoreTable(tr, t, insert); err != nil {
+				return fmt.Errorf("snapshot: %s: %w", t.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func readSmall(tr *tar.Reader, name string) ([]byte, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, fmt.Errorf("snapshot: reading %s: %w", name, err)
+	}
+	if hdr.Name != name {
+		return nil, fmt.Errorf("snapshot: expected %s, found %s", name, hdr.Name)
+	}
+	b, err := io.ReadAll(io.LimitReader(tr, maxManifest+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) > maxManifest {
+		return nil, fmt.Errorf("snapshot: %s is larger than %d bytes", name, maxManifest)
+	}
+	return b, nil
+}
+
+func restoreTable(tr *tar.Reader, t Table, insert func(string, []string, []json.RawMessage) error) error {
+	hdr, err := tr.Next()
+	if err != nil {
+		return err
+	}
+	if hdr.Name != t.File {
+		return fmt.Errorf("expected %s, found %s", t.File, hdr.Name)
+	}
+	sum := sha256.New()
+	body := io.TeeReader(tr, sum)
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		return err
+	}
+	br := bufio.NewReader(gz)
+	var rows int64
+	batch := make([]json.RawMessage, 0, restoreBatch)
+	for {
+		line, err := br.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			if !json.Valid(line) {
+				return fmt.Errorf("row %d is not JSON", rows+1)
+			}
+			batch = append(batch, json.RawMessage(line))
+			rows++
+			if len(batch) == restoreBatch {
+				if err := insert(t.Name, t.Columns, batch); err != nil {
+					return err
+				}
+				batch = make([]json.RawMessage, 0, restoreBatch)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := insert(t.Name, t.Columns, batch); err != nil {
+		return err
+	}
+	// hash whatever gzip left unread, then compare
+	if _, err := io.Copy(io.Discard, body); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(sum.Sum(nil)); got != t.SHA256 {
+		return fmt.Errorf("file hash %s does not match the manifest", got)
+	}
+	if rows != t.Rows {
+		return fmt.Errorf("%d rows, manifest says %d", rows, t.Rows)
+	}
+	return nil
+}
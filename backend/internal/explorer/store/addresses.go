@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+)
+
+// cardAddress is the address_caip10 a card or registration upsert leaves: its own,
+// unless the indexer has already set the on-chain AgentAddress.
+const cardAddress = `CASE WHEN agents.address_onchain THEN agents.address_caip10 ELSE EXCLUDED.address_caip10 END`
+
+// ownerAccount is the CAIP-10 id of a v1 registration owner, or "".
+func ownerAccount(owner, chainID string) string {
+	a, _ := caip.NormalizeAccount(owner, chainID)
+	return a
+}
+
+// SetAgentAddress records the agent's on-chain AgentAddress as its CAIP-10 address;
+// later card upserts keep it.
+func (s *Postgres) SetAgentAddress(ctx context.Context, chainID string, agentID int64, caip10 string) error {
+	_, err := s.db.Exec(ctx, `UPDATE agents SET address_caip10 = $3, address_onchain = true WHERE chain_id = $1 AND agent_id = $2`,
+		chainID, agentID, caip10)
+	return err
+}
+
+// ListAgentsByAddress returns the agents whose address is caip10, a checksummed
+// CAIP-10 id, through idx_agents_address.
+func (s *Postgres) ListAgentsByAddress(ctx context.Context, caip10 string, limit int) ([]AgentRow, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := s.db.Query(ctx, `SELECT `+agentColumns+` FROM `+agentFrom+`
+        WHERE agents.address_caip10 = $1 ORDER BY agents.chain_id, agents.agent_id LIMIT $2`, caip10, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []AgentRow{}
+	for rows.Next() {
+		r, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// NormalizeAgentAddresses rewrites addresses stored before they were normalized
+// (bare hex, lowercase, unchecksummed) as checksummed CAIP-10, and clears those that
+// are no account id. It returns how many rows changed.
+func (s *Postgres) NormalizeAgentAddresses(ctx context.Context) (int, error) {
+	rows, err := s.db.Query(ctx, `SELECT chain_id, agent_id, address_caip10 FROM agents WHERE address_caip10 <> ''`)
+	if err != nil {
+		return 0, err
+	}
+	type change struct {
+		chainID string
+		agentID int64
+		address string
+	}
+	var changes []change
+	for rows.Next() {
+		var c change
+		var stored string
+		if err := rows.Scan(&c.chainID, &c.agentID, &stored); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if c.address, _ = caip.NormalizeAccount(stored, c.chainID); c.address != stored {
+			changes = append(changes, c)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil || len(changes) == 0 {
+		return 0, err
+	}
+	batch := &pgx.Batch{}
+	for _, c := range changes {
+		batch.Queue(`UPDATE agents SET address_caip10 = $3 WHERE chain_id = $1 AND agent_id = $2`, c.chainID, c.agentID, c.address)
+	}
+	if err := s.db.SendBatch(ctx, batch).Close(); err != nil {
+		return 0, err
+	}
+	return len(changes), nil
+}
@@ -0,0 +1,192 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// CreateAPIKey stores a new API key by hash and returns its id.
+func (s *Postgres) CreateAPIKey(ctx context.Context, name, keyHash, prefix, role, ownerAddress string) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(ctx, `
+        INSERT INTO admin_api_keys (name, key_hash, prefix, role, owner_address)
+        VALUES ($1,$2,$3,$4,$5) RETURNING id
+    `, name, keyHash, prefix, role, strings.ToLower(ownerAddress)).Scan(&id)
+	return id, err
+}
+
+// EnsureAPIKey inserts a key unless one with the same hash already exists; used for
+// the bootstrap key configured through the environment.
+func (s *Postgres) EnsureAPIKey(ctx context.Context, name, keyHash, prefix, role string) error {
+	_, err := s.db.Exec(ctx, `
+        INSERT INTO admin_api_keys (name, key_hash, prefix, role)
+        VALUES ($1,$2,$3,$4) ON CONFLICT (key_hash) DO NOTHING
+    `, name, keyHash, prefix, role)
+	return err
+}
+
+// LookupAPIKey returns the active key with this hash and marks it used.
+func (s *Postgres) LookupAPIKey(ctx context.Context, keyHash string) (APIKey, error) {
+	var k APIKey
+	err := s.db.QueryRow(ctx, `
+        UPDATE admin_api_keys SET last_used_at = now()
+        WHERE key_hash = $1 AND revoked_at IS NULL
+        RETURNING id, name, prefix, role, owner_address, created_at, last_used_at, revoked_at
+    `, keyHash).Scan(&k.ID, &k.Name, &k.Prefix, &k.Role, &k.OwnerAddress, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt)
+	return k, err
+}
+
+// ListAPIKeys returns every key, newest first.
+func (s *Postgres) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT id, name, prefix, role, owner_address, created_at, last_used_at, revoked_at
+        FROM admin_api_keys ORDER BY id DESC
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []APIKey{}
+	for rows.Next() {
+		var k APIKey
+		if err := rows.Scan(&k.ID, &k.Name, &k.Prefix, &k.Role, &k.OwnerAddress, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, k)
+	}
+	return out, rows.Err()
+}
+
+// RevokeAPIKey revokes a key; it reports false if no active key has that id.
+func (s *Postgres) RevokeAPIKey(ctx context.Context, id int64) (bool, error) {
+	tag, err := s.db.Exec(ctx, `UPDATE admin_api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, id)
+	return tag.RowsAffected() > 0, err
+}
+
+// CreateSIWENonce stores a sign-in nonce until expiresAt, pruning expired ones.
+func (s *Postgres) CreateSIWENonce(ctx context.Context, nonce string, expiresAt time.Time) error {
+	if _, err := s.db.Exec(ctx, `DELETE FROM siwe_nonces WHERE expires_at < now()`); err != nil {
+		return err
+	}
+	_, err := s.db.Exec(ctx, `INSERT INTO siwe_nonces (nonce, expires_at) VALUES ($1,$2)`, nonce, expiresAt)
+	return err
+}
+
+// ConsumeSIWENonce deletes the nonce and reports whether it existed and was unexpired.
+func (s *Postgres) ConsumeSIWENonce(ctx context.Context, nonce string) (bool, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM siwe_nonces WHERE nonce = $1 AND expires_at >= now()`, nonce)
+	return tag.RowsAffected() > 0, err
+}
+
+// CreateSession stores a session under the hash of its bearer token.
+func (s *Postgres) CreateSession(ctx context.Context, tokenHash string, sess AdminSession) error {
+	_, err := s.db.Exec(ctx, `
+        INSERT INTO admin_sessions (token_hash, address, role, chain_id, expires_at)
+        VALUES ($1,$2,$3,$4,$5)
+    `, tokenHash, strings.ToLower(sess.Address), sess.Role, sess.ChainID, sess.ExpiresAt)
+	return err
+}
+
+// LookupSession returns the unexpired session for a token hash.
+func (s *Postgres) LookupSession(ctx context.Context, tokenHash string) (AdminSession, error) {
+	var sess AdminSession
+	err := s.db.QueryRow(ctx, `
+        SELECT address, role, chain_id, expires_at FROM admin_sessions
+        WHERE token_hash = $1 AND expires_at > now()
+    `, tokenHash).Scan(&sess.Address, &sess.Role, &sess.ChainID, &sess.ExpiresAt)
+	return sess, err
+}
+
+// DeleteSession ends a session (sign-out).
+func (s *Postgres) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM admin_sessions WHERE token_hash = $1`, tokenHash)
+	return err
+}
+
+// IsAgentOwner reports whether address is the agent's v1 owner or its AgentAddress
+// as read on-chain (stored either bare or as CAIP-10). Addresses only declared by
+// the agent's card do not count.
+func (s *Postgres) IsAgentOwner(ctx context.Context, chainID, agentID, address string) (bool, error) {
+	var ok bool
+	err := s.db.QueryRow(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM agents a
+            LEFT JOIN agent_registrations r ON r.chain_id = a.chain_id AND r.agent_id = a.agent_id
+            WHERE a.chain_id = $1 AND a.agent_id::text = $2
+              AND (lower(r.owner_address) = $3
+                   OR (a.address_onchain AND (lower(a.address_caip10) = $3
+                                              OR lower(a.address_caip10) LIKE '%:' || $3)))
+        )
+    `, chainID, agentID, strings.ToLower(address)).Scan(&ok)
+	return ok, err
+}
+
+// IsRegistrationOwner reports whether address is the owner of the agent's v1
+// registration.
+func (s *Postgres) IsRegistrationOwner(ctx context.Context, chainID, agentID, address string) (bool, error) {
+	var ok bool
+	err := s.db.QueryRow(ctx, `
+        SELECT EXISTS (
+            SELECT 1 FROM agent_registrations
+            WHERE chain_id = $1 AND agent_id::text = $2 AND lower(owner_address) = $3
+        )
+    `, chainID, agentID, strings.ToLower(address)).Scan(&ok)
+	return ok, err
+}
+
+// RecordAudit appends an entry to the admin audit log.
+func (s *Postgres) RecordAudit(ctx context.Context, e AuditEntry) error {
+	var req []byte
+	if e.Request != nil {
+		req, _ = json.Marshal(e.Request)
+	}
+	_, err := s.db.Exec(ctx, `
+        INSERT INTO admin_audit_log (actor_kind, actor_id, role, action, method, path, chain_id, agent_id, request, status, remote_ip)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+    `, e.ActorKind, e.ActorID, e.Role, e.Action, e.Method, e.Path, e.ChainID, e.AgentID, req, e.Status, e.RemoteIP)
+	return err
+}
+
+// ListAudit returns the most recent audit entries, newest first.
+func (s *Postgres) ListAudit(ctx context.Context, limit int) ([]AuditEntry, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := s.db.Query(ctx, `
+        SELECT id, at, actor_kind, actor_id, role, action, method, path, chain_id, agent_id, request, status, remote_ip
+        FROM admin_audit_log ORDER BY id DESC LIMIT $1
+    `, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		var req []byte
+		if err := rows.Scan(&e.ID, &e.At, &e.ActorKind, &e.ActorID, &e.Role, &e.Action, &e.Method, &e.Path, &e.ChainID, &e.AgentID, &req, &e.Status, &e.RemoteIP); err != nil {
+			return nil, err
+		}
+		if len(req) > 0 {
+			_ = json.Unmarshal(req, &e.Request)
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// UseRefreshNonce records a refresh nonce for an agent and reports false if it was
+// already used. Nonces older than keep are pruned; requests that old are rejected
+// by their timestamp anyway.
+func (s *Postgres) UseRefreshNonce(ctx context.Context, chainID string, agentID int64, nonce string, keep time.Duration) (bool, error) {
+	if _, err := s.db.Exec(ctx, `DELETE FROM agent_refresh_nonces WHERE used_at < now() - make_interval(secs => $1)`, keep.Seconds()); err != nil {
+		return false, err
+	}
+	tag, err := s.db.Exec(ctx, `
+        INSERT INTO agent_refresh_nonces (chain_id, agent_id, nonce) VALUES ($1,$2,$3)
+        ON CONFLICT DO NOTHING
+    `, chainID, agentID, nonce)
+	return tag.RowsAffected() > 0, err
+}
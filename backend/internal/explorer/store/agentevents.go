@@ -0,0 +1,117 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Identity registry events recorded in agent_events, by their Solidity names.
+const (
+	ChainEventRegistered      = "Registered" // v1: agentId, tokenURI, owner
+	ChainEventAgentRegistered = "AgentRegistered"
+	ChainEventAgentUpdated    = "AgentUpdated"
+)
+
+// AgentEvent is one identity registry log about an agent with its chain context.
+type AgentEvent struct {
+	ChainID     string         `json:"chainId"`
+	AgentID     int64          `json:"agentId"`
+	BlockNumber uint64         `json:"blockNumber"`
+	BlockTime   *time.Time     `json:"blockTime,omitempty"`
+	TxHash      string         `json:"txHash"`
+	LogIndex    uint           `json:"logIndex"`
+	Type        string         `json:"type"`
+	Args        map[string]any `json:"args"`
+	From        string         `json:"from,omitempty"`
+}
+
+// eventBlockColumns and eventBlockValues fill in an agent row inserted after its
+// identity events were recorded (the card could not be fetched when the log was
+// handled), as RecordAgentEvent would have. They expect the chain as $1 and the
+// agent id as $3 and only apply to inserts: ON CONFLICT updates leave the columns
+// to RecordAgentEvent.
+const (
+	eventBlockColumns = `registered_block, registered_at, updated_at_block`
+	eventBlockValues  = `(SELECT min(block_number) FROM agent_events
+            WHERE chain_id=$1 AND agent_id=$3 AND event_type IN ('` + ChainEventRegistered + `','` + ChainEventAgentRegistered + `')),
+        COALESCE((SELECT block_time FROM agent_events
+            WHERE chain_id=$1 AND agent_id=$3 AND event_type IN ('` + ChainEventRegistered + `','` + ChainEventAgentRegistered + `')
+            ORDER BY block_number, log_index LIMIT 1), now()),
+        (SELECT max(block_number) FROM agent_events
+            WHERE chain_id=$1 AND agent_id=$3 AND event_type = '` + ChainEventAgentUpdated + `')`
+)
+
+// RecordAgentEvent stores e, once per log, and moves the agent's registration
+// (earliest registering event) and last update (latest updating event) to it.
+// Replayed logs are ignored.
+func (s *Postgres) RecordAgentEvent(ctx context.Context, e AgentEvent) error {
+	args, err := json.Marshal(e.Args)
+	if err != nil {
+		return err
+	}
+	var from *string
+	if e.From != "" {
+		from = &e.From
+	}
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO agent_events (chain_id, agent_id, block_number, block_time, tx_hash, log_index, event_type, args, from_address)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+        ON CONFLICT (chain_id, tx_hash, log_index) DO NOTHING
+    `, e.ChainID, e.AgentID, int64(e.BlockNumber), e.BlockTime, e.TxHash, int(e.LogIndex), e.Type, args, from)
+	if err != nil {
+		return err
+	}
+	switch e.Type {
+	case ChainEventRegistered, ChainEventAgentRegistered:
+		_, err = tx.Exec(ctx, `
+            UPDATE agents SET registered_block = $3, registered_at = COALESCE($4, registered_at)
+            WHERE chain_id=$1 AND agent_id=$2 AND (registered_block IS NULL OR registered_block > $3)
+        `, e.ChainID, e.AgentID, int64(e.BlockNumber), e.BlockTime)
+	case ChainEventAgentUpdated:
+		_, err = tx.Exec(ctx, `
+            UPDATE agents SET updated_at_block = $3
+            WHERE chain_id=$1 AND agent_id=$2 AND (updated_at_block IS NULL OR updated_at_block < $3)
+        `, e.ChainID, e.AgentID, int64(e.BlockNumber))
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ListAgentEvents returns an agent's timeline, oldest first.
+func (s *Postgres) ListAgentEvents(ctx context.Context, chainID, agentID string, limit int) ([]AgentEvent, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := s.db.Query(ctx, `
+        SELECT chain_id, agent_id, block_number, block_time, tx_hash, log_index, event_type, args, COALESCE(from_address, '')
+        FROM agent_events WHERE chain_id=$1 AND agent_id=$2
+        ORDER BY block_number, log_index LIMIT $3
+    `, chainID, agentID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []AgentEvent{}
+	for rows.Next() {
+		var e AgentEvent
+		var block int64
+		var logIndex int
+		var args []byte
+		if err := rows.Scan(&e.ChainID, &e.AgentID, &block, &e.BlockTime, &e.TxHash, &logIndex, &e.Type, &args, &e.From); err != nil {
+			return nil, err
+		}
+		e.BlockNumber, e.LogIndex = uint64(block), uint(logIndex)
+		_ = json.Unmarshal(args, &e.Args)
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// testStore connects to EXPLORER_TEST_DATABASE_URL and applies the migrations
+// (they are idempotent); without it the test is skipped.
+func testStore(t *testing.T) *Postgres {
+	t.Helper()
+	url := os.Getenv("EXPLORER_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("EXPLORER_TEST_DATABASE_URL not set")
+	}
+	st, err := NewPostgres(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(st.db.Close)
+	files, _ := filepath.Glob("../../../migrations/*.sql")
+	sort.Strings(files)
+	for _, f := range files {
+		sql, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := st.db.Exec(context.Background(), string(sql)); err != nil {
+			t.Fatalf("%s: %v", filepath.Base(f), err)
+		}
+	}
+	return st
+}
+
+func TestAgentInsertedAfterEventsTakesTheirBlocks(t *testing.T) {
+	st := testStore(t)
+	ctx := context.Background()
+	const chain = "eip155:31337"
+	clean := func() {
+		_, _ = st.db.Exec(ctx, `DELETE FROM agent_events WHERE chain_id = $1`, chain)
+		_, _ = st.db.Exec(ctx, `DELETE FROM agents WHERE chain_id = $1`, chain)
+	}
+	clean()
+	t.Cleanup(clean)
+
+	registered := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []AgentEvent{
+		{ChainID: chain, AgentID: 5, BlockNumber: 100, BlockTime: &registered, TxHash: "0xa1", Type: ChainEventAgentRegistered},
+		{ChainID: chain, AgentID: 5, BlockNumber: 150, TxHash: "0xa2", Type: ChainEventAgentUpdated},
+		{ChainID: chain, AgentID: 5, BlockNumber: 180, TxHash: "0xa3", Type: ChainEventAgentUpdated},
+		{ChainID: chain, AgentID: 6, BlockNumber: 120, BlockTime: &registered, TxHash: "0xb1", LogIndex: 1, Type: ChainEventRegistered},
+	}
+	// no agent rows yet: the card fetch failed when the logs were handled
+	for _, e := range events {
+		if err := st.RecordAgentEvent(ctx, e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := st.UpsertAgentFromCard(ctx, chain, "0x01", 5, "five.example", map[string]any{"name": "five"}); err != nil {
+		t.Fatal(err)
+	}
+	reg := Registration{TokenURI: "ipfs://six", Owner: "0x0000000000000000000000000000000000000006", Raw: map[string]any{"name": "six"}}
+	if err := st.UpsertRegistration(ctx, chain, "0x01", 6, "six.example", reg, true); err != nil {
+		t.Fatal(err)
+	}
+
+	five, err := st.GetAgent(ctx, chain, "5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if five.RegisteredBlock == nil || *five.RegisteredBlock != 100 || five.UpdatedAtBlock == nil || *five.UpdatedAtBlock != 180 || !five.RegisteredAt.Equal(registered) {
+		t.Fatalf("card upsert: registered %v at %v, updated %v", five.RegisteredBlock, five.RegisteredAt, five.UpdatedAtBlock)
+	}
+	six, err := st.GetAgent(ctx, chain, "6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if six.RegisteredBlock == nil || *six.RegisteredBlock != 120 || six.UpdatedAtBlock != nil || !six.RegisteredAt.Equal(registered) {
+		t.Fatalf("registration upsert: registered %v at %v, updated %v", six.RegisteredBlock, six.RegisteredAt, six.UpdatedAtBlock)
+	}
+}
@@ -0,0 +1,227 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AgentKey identifies an agent across chains.
+type AgentKey struct {
+	ChainID string
+	AgentID int64
+}
+
+// ChainStats summarizes the agents indexed on one chain.
+type ChainStats struct {
+	ChainID    string     `json:"chainId"`
+	Agents     int        `json:"agents"`
+	LastSeenAt *time.Time `json:"lastSeenAt,omitempty"`
+}
+
+// keyArrays splits keys into parallel arrays for unnest($1::text[], $2::bigint[]).
+func keyArrays(keys []AgentKey) ([]string, []int64) {
+	chains := make([]string, len(keys))
+	ids := make([]int64, len(keys))
+	for i, k := range keys {
+		chains[i], ids[i] = k.ChainID, k.AgentID
+	}
+	return chains, ids
+}
+
+// keyFilter restricts alias.chain_id/alias.agent_id to the keys passed as $1 and $2.
+func keyFilter(alias string) string {
+	return `(` + alias + `.chain_id, ` + alias + `.agent_id) IN (SELECT * FROM unnest($1::text[], $2::bigint[]))`
+}
+
+// GetAgentsByKeys loads many agents in one query; missing agents are absent from the map.
+func (s *Postgres) GetAgentsByKeys(ctx context.Context, keys []AgentKey) (map[AgentKey]AgentRow, error) {
+	out := make(map[AgentKey]AgentRow, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+	chains, ids := keyArrays(keys)
+	rows, err := s.db.Query(ctx, `SELECT `+agentColumns+` FROM `+agentFrom+` WHERE `+keyFilter("agents"), chains, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		r, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out[AgentKey{r.ChainID, r.AgentID}] = r
+	}
+	return out, rows.Err()
+}
+
+// GetRegistrationsByKeys is GetRegistration for many agents, in four queries total.
+// Agents without a v1 registration are absent from the map.
+func (s *Postgres) GetRegistrationsByKeys(ctx context.Context, keys []AgentKey) (map[AgentKey]Registration, error) {
+	out := make(map[AgentKey]Registration, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+	chains, ids := keyArrays(keys)
+
+	rows, err := s.db.Query(ctx, `
+        SELECT r.chain_id, r.agent_id, r.token_uri, r.owner_address, r.registration_json, r.content_verified
+        FROM agent_registrations r WHERE `+keyFilter("r"), chains, ids)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var k AgentKey
+		var reg Registration
+		var raw []byte
+		if err := rows.Scan(&k.ChainID, &k.AgentID, &reg.TokenURI, &reg.Owner, &raw, &reg.Verified); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		_ = json.Unmarshal(raw, &reg.Raw)
+		reg.Name, _ = reg.Raw["name"].(string)
+		reg.Description, _ = reg.Raw["description"].(string)
+		reg.Endpoints, reg.SupportedTrust, reg.Wallets = []Endpoint{}, []string{}, []string{}
+		out[k] = reg
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	eps, err := s.ListEndpointsByKeys(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	for k, list := range eps {
+		reg, ok := out[k]
+		if !ok {
+			continue
+		}
+		for _, ep := range list {
+			if ep.Source == EndpointSourceRegistration {
+				reg.Endpoints = append(reg.Endpoints, ep)
+			}
+		}
+		out[k] = reg
+	}
+
+	for _, q := range []struct {
+		sql string
+		set func(*Registration, string)
+	}{
+		{`SELECT t.chain_id, t.agent_id, t.trust_model FROM agent_supported_trust t WHERE ` + keyFilter("t") + ` ORDER BY t.trust_model`,
+			func(r *Registration, v string) { r.SupportedTrust = append(r.SupportedTrust, v) }},
+		{`SELECT w.chain_id, w.agent_id, w.address FROM agent_wallets w WHERE ` + keyFilter("w") + ` ORDER BY w.address`,
+			func(r *Registration, v string) { r.Wallets = append(r.Wallets, v) }},
+	} {
+		rows, err := s.db.Query(ctx, q.sql, chains, ids)
+		if err != nil {
+			return nil, err
+		}
+		for rows.Next() {
+			var k AgentKey
+			var v string
+			if err := rows.Scan(&k.ChainID, &k.AgentID, &v); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			if reg, ok := out[k]; ok {
+				q.set(&reg, v)
+				out[k] = reg
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// ListEndpointsByKeys is ListEndpoints for many agents.
+func (s *Postgres) ListEndpointsByKeys(ctx context.Context, keys []AgentKey) (map[AgentKey][]Endpoint, error) {
+	out := make(map[AgentKey][]Endpoint, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+	chains, ids := keyArrays(keys)
+	rows, err := s.db.Query(ctx, `
+        SELECT e.chain_id, e.agent_id, e.source, e.name, e.endpoint, e.version, e.extra
+        FROM agent_endpoints e WHERE `+keyFilter("e")+`
+        ORDER BY e.source DESC, e.name, e.endpoint
+    `, chains, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k AgentKey
+		var ep Endpoint
+		var extraBytes []byte
+		if err := rows.Scan(&k.ChainID, &k.AgentID, &ep.Source, &ep.Name, &ep.Endpoint, &ep.Version, &extraBytes); err != nil {
+			return nil, err
+		}
+		if len(extraBytes) > 0 {
+			_ = json.Unmarshal(extraBytes, &ep.Extra)
+		}
+		if len(ep.Extra) == 0 {
+			ep.Extra = nil
+		}
+		out[k] = append(out[k], ep)
+	}
+	return out, rows.Err()
+}
+
+// ListProbesByKeys returns up to perAgent most recent probe results for each agent.
+func (s *Postgres) ListProbesByKeys(ctx context.Context, keys []AgentKey, perAgent int) (map[AgentKey][]ProbeResult, error) {
+	out := make(map[AgentKey][]ProbeResult, len(keys))
+	if len(keys) == 0 {
+		return out, nil
+	}
+	if perAgent <= 0 || perAgent > 500 {
+		perAgent = 50
+	}
+	chains, ids := keyArrays(keys)
+	rows, err := s.db.Query(ctx, `
+        SELECT chain_id, agent_id, kind, url, probed_at, COALESCE(http_status, 0), COALESCE(latency_ms, 0), tls_valid, handshake_ok, error
+        FROM (
+            SELECT p.*, row_number() OVER (PARTITION BY p.chain_id, p.agent_id ORDER BY p.probed_at DESC) AS rn
+            FROM agent_probes p WHERE `+keyFilter("p")+`
+        ) ranked
+        WHERE rn <= $3
+        ORDER BY chain_id, agent_id, probed_at DESC
+    `, chains, ids, perAgent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var k AgentKey
+		var r ProbeResult
+		if err := rows.Scan(&k.ChainID, &k.AgentID, &r.Kind, &r.URL, &r.ProbedAt, &r.HTTPStatus, &r.LatencyMs, &r.TLSValid, &r.HandshakeOK, &r.Error); err != nil {
+			return nil, err
+		}
+		out[k] = append(out[k], r)
+	}
+	return out, rows.Err()
+}
+
+// ListChainStats returns agent counts per indexed chain.
+func (s *Postgres) ListChainStats(ctx context.Context) ([]ChainStats, error) {
+	rows, err := s.db.Query(ctx, `SELECT chain_id, count(*), max(last_seen_at) FROM agents GROUP BY chain_id ORDER BY chain_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []ChainStats{}
+	for rows.Next() {
+		var c ChainStats
+		if err := rows.Scan(&c.ChainID, &c.Agents, &c.LastSeenAt); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,12 @@
+package store
+
+import "context"
+
+// RenameChain moves every agent, event, nonce and checkpoint of chain from to chain
+// to, through the rename_chain function of migration 016. Agents already stored
+// under to are kept over their copies under from. It returns how many agents moved.
+func (s *Postgres) RenameChain(ctx context.Context, from, to string) (int64, error) {
+	var moved int64
+	err := s.db.QueryRow(ctx, `SELECT rename_chain($1, $2)`, from, to).Scan(&moved)
+	return moved, err
+}
@@ -0,0 +1,54 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Checkpoint is how far the indexer got with one identity registry.
+type Checkpoint struct {
+	ChainID          string    `json:"chainId"`
+	RegistryAddr     string    `json:"registryAddr"`
+	LastBlock        uint64    `json:"lastBlock"`
+	AgentsBackfilled int64     `json:"agentsBackfilled"`
+	UpdatedAt        time.Time `json:"updatedAt"`
+}
+
+// GetCheckpoint returns the checkpoint of a registry, the zero Checkpoint (nothing
+// indexed yet) when there is none. Registry addresses are stored lowercased.
+func (s *Postgres) GetCheckpoint(ctx context.Context, chainID, registryAddr string) (Checkpoint, error) {
+	c := Checkpoint{ChainID: chainID, RegistryAddr: registryAddr}
+	var last int64
+	err := s.db.QueryRow(ctx, `SELECT last_block, agents_backfilled, updated_at FROM indexer_checkpoints
+        WHERE chain_id=$1 AND registry_addr=lower($2)`, chainID, registryAddr).Scan(&last, &c.AgentsBackfilled, &c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return c, nil
+	}
+	c.LastBlock = uint64(last)
+	return c, err
+}
+
+// SaveBlockCheckpoint records that identity logs up to block were handled. It never
+// moves a checkpoint backwards.
+func (s *Postgres) SaveBlockCheckpoint(ctx context.Context, chainID, registryAddr string, block uint64) error {
+	_, err := s.db.Exec(ctx, `
+        INSERT INTO indexer_checkpoints (chain_id, registry_addr, last_block) VALUES ($1, lower($2), $3)
+        ON CONFLICT (chain_id, registry_addr)
+        DO UPDATE SET last_block = GREATEST(indexer_checkpoints.last_block, EXCLUDED.last_block), updated_at = now()
+    `, chainID, registryAddr, int64(block))
+	return err
+}
+
+// SaveBackfillCheckpoint records that the backfill visited agents up to agentID. It
+// never moves a checkpoint backwards.
+func (s *Postgres) SaveBackfillCheckpoint(ctx context.Context, chainID, registryAddr string, agentID int64) error {
+	_, err := s.db.Exec(ctx, `
+        INSERT INTO indexer_checkpoints (chain_id, registry_addr, agents_backfilled) VALUES ($1, lower($2), $3)
+        ON CONFLICT (chain_id, registry_addr)
+        DO UPDATE SET agents_backfilled = GREATEST(indexer_checkpoints.agents_backfilled, EXCLUDED.agents_backfilled), updated_at = now()
+    `, chainID, registryAddr, agentID)
+	return err
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidCursor is returned for pagination cursors we did not issue, or issued
+// for a different sort.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// agentCursor is the keyset position after which the next page of agents starts:
+// the sort it belongs to, the row's sort value and its (chain_id, agent_id) tie-break.
+type agentCursor struct {
+	Sort    Sort
+	Value   any // time.Time, float64, int64 or string, by the sort's kind
+	ChainID string
+	AgentID int64
+}
+
+// encodeCursor renders c as an opaque URL-safe token. The value goes last since a
+// name may contain the separator.
+func encodeCursor(c agentCursor) string {
+	var v string
+	switch x := c.Value.(type) {
+	case time.Time:
+		v = x.UTC().Format(time.RFC3339Nano)
+	case float64:
+		v = strconv.FormatFloat(x, 'g', -1, 64)
+	case int64:
+		v = strconv.FormatInt(x, 10)
+	case string:
+		v = x
+	}
+	raw := c.Sort.String() + "|" + strconv.FormatInt(c.AgentID, 10) + "|" + c.ChainID + "|" + v
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(s string) (agentCursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return agentCursor{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(b), "|", 4)
+	if len(parts) != 4 {
+		return agentCursor{}, ErrInvalidCursor
+	}
+	key, order, _ := strings.Cut(parts[0], ":")
+	sort, err := ParseSort(key, order)
+	if err != nil || order == "" {
+		return agentCursor{}, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return agentCursor{}, ErrInvalidCursor
+	}
+	c := agentCursor{Sort: sort, AgentID: id, ChainID: parts[2]}
+	v := parts[3]
+	switch sort.kind() {
+	case kindTime:
+		c.Value, err = time.Parse(time.RFC3339Nano, v)
+	case kindFloat:
+		c.Value, err = strconv.ParseFloat(v, 64)
+	case kindInt:
+		c.Value, err = strconv.ParseInt(v, 10, 64)
+	default:
+		c.Value = v
+	}
+	if err != nil {
+		return agentCursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// CursorFor is the cursor that resumes a search right after r. Rows returned by
+// SearchAgents carry the position in the order they were sorted by; other rows are
+// placed in the default recent order.
+func CursorFor(r AgentRow) string {
+	if r.cursor != "" {
+		return r.cursor
+	}
+	return encodeCursor(agentCursor{Sort: Sort{Key: SortRecent}, Value: r.LastSeenAt, ChainID: r.ChainID, AgentID: r.AgentID})
+}
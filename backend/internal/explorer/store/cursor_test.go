@@ -0,0 +1,54 @@
+package store
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2025, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	cases := []agentCursor{
+		{Sort: Sort{Key: SortRecent}, Value: at},
+		{Sort: Sort{Key: SortRegistered, Asc: true}, Value: at},
+		{Sort: Sort{Key: SortRelevance}, Value: 0.8125},
+		{Sort: Sort{Key: SortScore}, Value: float64(-1)},
+		{Sort: Sort{Key: SortFeedbacks}, Value: int64(42)},
+		{Sort: Sort{Key: SortValidations, Asc: true}, Value: int64(0)},
+		{Sort: Sort{Key: SortAgentID, Asc: true}, Value: int64(7)},
+		{Sort: Sort{Key: SortName, Asc: true}, Value: "alpha | beta"},
+		{Sort: Sort{Key: SortName}, Value: ""},
+	}
+	for _, c := range cases {
+		c.ChainID, c.AgentID = "eip155:11155111", 7
+		got, err := decodeCursor(encodeCursor(c))
+		if err != nil {
+			t.Errorf("%s: %v", c.Sort, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("%s: decoded %+v, want %+v", c.Sort, got, c)
+		}
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	enc := func(s string) string { return base64.RawURLEncoding.EncodeToString([]byte(s)) }
+	for _, s := range []string{
+		"not base64!",
+		enc("recent:desc|7"),
+		enc("recent|7|eip155:1|2025-03-01T12:00:00Z"), // no order
+		enc("popularity:desc|7|eip155:1|3"),
+		enc("recent:desc|x|eip155:1|2025-03-01T12:00:00Z"),
+		enc("recent:desc|7|eip155:1|yesterday"),
+		enc("score:desc|7|eip155:1|high"),
+		enc("feedbacks:desc|7|eip155:1|1.5"),
+		enc("2025-03-01T12:00:00Z|7|eip155:1"),
+	} {
+		if _, err := decodeCursor(s); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%q: err = %v, want ErrInvalidCursor", s, err)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/praxis/praxis-explorer/internal/explorer/embed"
+)
+
+// SimilarAgent is an agent with its cosine similarity to the one asked about.
+type SimilarAgent struct {
+	AgentRow
+	Similarity float64 `json:"similarity"`
+}
+
+// similaritySQL is the cosine between an agent's stored vector and the normalized
+// vector in parameter idx, 0 for agents not embedded yet.
+func similaritySQL(idx int) string {
+	return fmt.Sprintf(`COALESCE((SELECT sum(v.a * v.b) FROM agent_embeddings e, unnest(e.vec, $%d::real[]) AS v(a, b)
+            WHERE e.chain_id = agents.chain_id AND e.agent_id = agents.agent_id), 0)::float8`, idx)
+}
+
+// upsertEmbedding stores the vector of an agent's card, in the transaction that
+// stores the card.
+func upsertEmbedding(ctx context.Context, tx pgx.Tx, chainID string, agentID int64, card map[string]any) error {
+	_, err := tx.Exec(ctx, `
+        INSERT INTO agent_embeddings (chain_id, agent_id, model, vec, updated_at)
+        VALUES ($1,$2,$3,$4, now())
+        ON CONFLICT (chain_id, agent_id)
+        DO UPDATE SET model=EXCLUDED.model, vec=EXCLUDED.vec, updated_at=now()
+    `, chainID, agentID, embed.Model, embed.Card(card))
+	return err
+}
+
+// EmbedMissing embeds up to n agents that have no vector, or one from an older
+// embed.Model, and returns how many it embedded.
+func (s *Postgres) EmbedMissing(ctx context.Context, n int) (int, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT a.chain_id, a.agent_id, a.card_json
+        FROM agents a LEFT JOIN agent_embeddings e ON e.chain_id = a.chain_id AND e.agent_id = a.agent_id
+        WHERE e.model IS DISTINCT FROM $1
+        LIMIT $2`, embed.Model, n)
+	if err != nil {
+		return 0, err
+	}
+	type pending struct {
+		chainID string
+		agentID int64
+		card    map[string]any
+	}
+	var todo []pending
+	for rows.Next() {
+		var p pending
+		var b []byte
+		if err := rows.Scan(&p.chainID, &p.agentID, &b); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		_ = json.Unmarshal(b, &p.card)
+		todo = append(todo, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+	for _, p := range todo {
+		if err := upsertEmbedding(ctx, tx, p.chainID, p.agentID, p.card); err != nil {
+			return 0, err
+		}
+	}
+	return len(todo), tx.Commit(ctx)
+}
+
+// hasPgvector reports whether migration 012 found pgvector and added the indexed
+// embedding column; it is checked once.
+func (s *Postgres) hasPgvector(ctx context.Context) bool {
+	s.vectorOnce.Do(func() {
+		err := s.db.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM information_schema.columns
+            WHERE table_name = 'agent_embeddings' AND column_name = 'embedding')`).Scan(&s.vector)
+		if err != nil {
+			s.vector = false
+		}
+	})
+	return s.vector
+}
+
+// SimilarAgents returns up to limit other agents whose cards are most similar to
+// to's, most similar first, leaving out those below embed.MinSimilarity.
+func (s *Postgres) SimilarAgents(ctx context.Context, to AgentRow, limit int) ([]SimilarAgent, error) {
+	if limit <= 0 || limit > 50 {
+		limit = 10
+	}
+	vec := embed.Card(to.CardJSON)
+	var sql string
+	var args []any
+	if s.hasPgvector(ctx) {
+		// order by distance alone so the HNSW index serves the query
+		parts := make([]string, len(vec))
+		for i, x := range vec {
+			parts[i] = strconv.FormatFloat(float64(x), 'g', -1, 32)
+		}
+		sql = `SELECT ` + agentColumns + `, (1 - (e.embedding <=> $1::vector))::float8
+            FROM ` + agentFrom + ` JOIN agent_embeddings e ON e.chain_id = agents.chain_id AND e.agent_id = agents.agent_id
+            WHERE NOT (agents.chain_id = $2 AND agents.agent_id = $3) AND e.embedding IS NOT NULL
+            ORDER BY e.embedding <=> $1::vector LIMIT $4`
+		args = []any{"[" + strings.Join(parts, ",") + "]", to.ChainID, to.AgentID, limit}
+	} else {
+		sql = `SELECT ` + agentColumns + `, sim.v
+            FROM ` + agentFrom + ` CROSS JOIN LATERAL (SELECT ` + similaritySQL(1) + ` AS v) sim
+            WHERE NOT (agents.chain_id = $2 AND agents.agent_id = $3) AND sim.v >= $5
+            ORDER BY sim.v DESC, agents.chain_id, agents.agent_id LIMIT $4`
+		args = []any{vec, to.ChainID, to.AgentID, limit, embed.MinSimilarity}
+	}
+
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []SimilarAgent{}
+	for rows.Next() {
+		var sim float64
+		r, err := scanAgent(rows, &sim)
+		if err != nil {
+			return nil, err
+		}
+		if sim >= embed.MinSimilarity {
+			out = append(out, SimilarAgent{AgentRow: r, Similarity: sim})
+		}
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
+)
+
+// Event types written to indexer_events.
+const (
+	EventAgentRegistered = "agent.registered"
+	EventAgentUpdated    = "agent.updated"
+	EventCardChanged     = "card.changed"
+	EventAgentDeleted    = "agent.deleted"
+	EventStatusChanged   = "probe.status_changed"
+)
+
+// EventTypes lists every event type, in lifecycle order.
+var EventTypes = []string{EventAgentRegistered, EventAgentUpdated, EventCardChanged, EventAgentDeleted, EventStatusChanged}
+
+// Event is one row of the indexer event log.
+type Event struct {
+	ID      int64          `json:"id"`
+	Type    string         `json:"type"`
+	ChainID string         `json:"chainId"`
+	AgentID int64          `json:"agentId"`
+	At      time.Time      `json:"at"`
+	Agent   EventAgent     `json:"agent"`
+	Data    map[string]any `json:"data,omitempty"`
+}
+
+// EventAgent is the agent as it was when the event happened, enough to filter on.
+type EventAgent struct {
+	Domain      string           `json:"domain"`
+	Name        string           `json:"name,omitempty"`
+	TrustModels []string         `json:"trustModels"`
+	Skills      []map[string]any `json:"skills"`
+}
+
+// EventFields are the filter fields an event's agent snapshot can answer.
+var EventFields = []string{filter.Network, filter.TrustModel, filter.Skill, filter.Tag}
+
+// Matches applies the SearchParams filters that make sense for a single event:
+// Q (domain, name or skill name substring), Network (chain), Skill (id or name
+// substring), Tag and TrustModel, all case-insensitive, and Filters. Terms on fields
+// outside EventFields never match.
+func (e Event) Matches(p SearchParams) bool {
+	if q := strings.ToLower(strings.TrimSpace(p.Q)); q != "" {
+		found := strings.Contains(strings.ToLower(e.Agent.Domain), q) || strings.Contains(strings.ToLower(e.Agent.Name), q)
+		for _, s := range e.Agent.Skills {
+			name, _ := s["name"].(string)
+			found = found || strings.Contains(strings.ToLower(name), q)
+		}
+		if !found {
+			return false
+		}
+	}
+	for _, t := range []filter.Term{{Field: filter.Network, Value: p.Network}, {Field: filter.TrustModel, Value: p.TrustModel}, {Field: filter.Skill, Value: p.Skill}, {Field: filter.Tag, Value: p.Tag}} {
+		if t.Value = strings.TrimSpace(t.Value); t.Value != "" && !e.matchTerm(t) {
+			return false
+		}
+	}
+	for _, x := range p.Filters {
+		if !filter.Eval(x, e.matchTerm) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchTerm evaluates a term against the snapshot, like AgentRow.matchTerm.
+func (e Event) matchTerm(t filter.Term) bool {
+	switch t.Field {
+	case filter.Network:
+		return caip.Normalize(t.Value) == e.ChainID
+	case filter.TrustModel:
+		return hasTrustModel(e.Agent.TrustModels, t.Value)
+	case filter.Skill:
+		return hasSkill(e.Agent.Skills, t.Value)
+	case filter.Tag:
+		return hasTag(e.Agent.Skills, t.Value)
+	}
+	return false
+}
+
+// recordEvent appends an event inside tx, snapshotting the agent row as it is in tx.
+func recordEvent(ctx context.Context, tx pgx.Tx, typ, chainID string, agentID int64, data map[string]any) error {
+	if data == nil {
+		data = map[string]any{}
+	}
+	_, err := tx.Exec(ctx, `
+        INSERT INTO indexer_events (type, chain_id, agent_id, agent, data)
+        SELECT $1, $2, $3,
+               jsonb_build_object('domain', a.domain, 'name', COALESCE(a.card_json->>'name', ''),
+                                  'trustModels', to_jsonb(COALESCE(a.trust_models, '{}')), 'skills', COALESCE(a.skills, '[]'::jsonb)),
+               $4
+        FROM agents a WHERE a.chain_id = $2 AND a.agent_id = $3
+    `, typ, chainID, agentID, data)
+	return err
+}
+
+// ListEventsAfter returns up to limit events with id > afterID, oldest first.
+func (s *Postgres) ListEventsAfter(ctx context.Context, afterID int64, limit int) ([]Event, error) {
+	if limit <= 0 || limit > 1000 {
+		limit = 500
+	}
+	rows, err := s.db.Query(ctx, `
+        SELECT id, type, chain_id, agent_id, at, agent, data
+        FROM indexer_events WHERE id > $1 ORDER BY id LIMIT $2
+    `, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Event{}
+	for rows.Next() {
+		var e Event
+		var agent, data []byte
+		if err := rows.Scan(&e.ID, &e.Type, &e.ChainID, &e.AgentID, &e.At, &agent, &data); err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal(agent, &e.Agent)
+		_ = json.Unmarshal(data, &e.Data)
+		if len(e.Data) == 0 {
+			e.Data = nil
+		}
+		out = append(out, e)
+	}
+	return out, rows.Err()
+}
+
+// LatestEventID is the id of the newest event, 0 when the log is empty.
+func (s *Postgres) LatestEventID(ctx context.Context) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(ctx, `SELECT COALESCE(max(id), 0) FROM indexer_events`).Scan(&id)
+	return id, err
+}
+
+// PruneEvents deletes events older than the retention period.
+func (s *Postgres) PruneEvents(ctx context.Context, olderThan time.Duration) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM indexer_events WHERE at < now() - make_interval(secs => $1)`, olderThan.Seconds())
+	return err
+}
@@ -0,0 +1,75 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// exportBatch is how many rows each FETCH pulls from the export cursor.
+const exportBatch = 500
+
+// ExportRow is an agent as exported in bulk: the search row plus a hash of its card
+// and whether its registration file matched the bytes at its URI.
+type ExportRow struct {
+	AgentRow
+	// CardHash is the hex SHA-256 of the stored card JSON, to spot changes between exports.
+	CardHash string `json:"cardHash"`
+	// RegistrationVerified is nil for agents without a registration file.
+	RegistrationVerified *bool `json:"registrationVerified"`
+}
+
+// ExportAgents calls fn for every agent matching p's filters, ordered by chain and
+// agent id; Limit, Cursor and Sort are ignored. Rows come from a server-side cursor
+// in a read-only snapshot, a batch at a time, so memory stays flat however many
+// agents match. An error from fn stops the export and is returned.
+func (s *Postgres) ExportAgents(ctx context.Context, p SearchParams, fn func(ExportRow) error) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	args := []any{}
+	where := searchWhere(p, &args, "")
+	sql := `DECLARE agent_export NO SCROLL CURSOR FOR
+        SELECT ` + agentColumns + `,
+            encode(sha256(convert_to(agents.card_json::text, 'UTF8')), 'hex'), r.content_verified
+        FROM ` + agentFrom + ` LEFT JOIN agent_registrations r ON r.chain_id = agents.chain_id AND r.agent_id = agents.agent_id`
+	if len(where) > 0 {
+		sql += " WHERE " + strings.Join(where, " AND ")
+	}
+	sql += " ORDER BY agents.chain_id, agents.agent_id"
+	if _, err := tx.Exec(ctx, sql, args...); err != nil {
+		return err
+	}
+
+	for {
+		rows, err := tx.Query(ctx, fmt.Sprintf(`FETCH FORWARD %d FROM agent_export`, exportBatch))
+		if err != nil {
+			return err
+		}
+		n := 0
+		for rows.Next() {
+			var e ExportRow
+			e.AgentRow, err = scanAgent(rows, &e.CardHash, &e.RegistrationVerified)
+			if err == nil {
+				err = fn(e)
+			}
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			n++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if n < exportBatch {
+			return nil
+		}
+	}
+}
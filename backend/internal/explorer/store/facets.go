@@ -0,0 +1,89 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
+)
+
+// Facets supported by SearchFacets, named after the filter fields they count.
+const (
+	FacetNetwork    = filter.Network
+	FacetTrustModel = filter.TrustModel
+	FacetTag        = filter.Tag
+	FacetCapability = filter.Capability
+)
+
+// FacetNames lists every facet, in the order they are documented.
+var FacetNames = []string{FacetNetwork, FacetTrustModel, FacetTag, FacetCapability}
+
+// FacetBucket is one value of a facet and the number of matching agents having it.
+type FacetBucket struct {
+	Value string `json:"value"`
+	Count int64  `json:"count"`
+}
+
+// facetValues is the lateral source yielding each agent's distinct values of a facet
+// as f(v), so counts are of agents rather than of occurrences.
+var facetValues = map[string]string{
+	FacetNetwork:    `(SELECT agents.chain_id) AS f(v)`,
+	FacetTrustModel: `(SELECT DISTINCT x FROM unnest(COALESCE(agents.trust_models, '{}')) x) AS f(v)`,
+	FacetTag: `(SELECT DISTINCT lower(t) FROM jsonb_array_elements(COALESCE(agents.skills, '[]'::jsonb)) s,
+                   jsonb_array_elements_text(COALESCE(s->'tags', '[]'::jsonb)) t) AS f(v)`,
+	FacetCapability: `(SELECT k FROM jsonb_object_keys(CASE WHEN jsonb_typeof(agents.card_json->'capabilities') = 'object'
+                   THEN agents.card_json->'capabilities' ELSE '{}'::jsonb END) k) AS f(v)`,
+}
+
+// SearchFacets counts the agents matching p per value of each named facet, at most
+// limit buckets per facet, largest first. Each facet ignores its own filter so a
+// client can offer the other values of a multi-select; the cursor is ignored since
+// counts describe the whole result set.
+func (s *Postgres) SearchFacets(ctx context.Context, p SearchParams, facets []string, limit int) (map[string][]FacetBucket, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 10
+	}
+	batch := &pgx.Batch{}
+	for _, name := range facets {
+		src, ok := facetValues[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown facet %q", name)
+		}
+		args := []any{}
+		where := searchWhere(p, &args, name)
+		sql := `SELECT f.v, count(*) FROM ` + agentFrom + ` CROSS JOIN LATERAL ` + src
+		if len(where) > 0 {
+			sql += " WHERE " + strings.Join(where, " AND ")
+		}
+		args = append(args, limit)
+		sql += fmt.Sprintf(" GROUP BY f.v ORDER BY count(*) DESC, f.v LIMIT $%d", len(args))
+		batch.Queue(sql, args...)
+	}
+
+	out := map[string][]FacetBucket{}
+	br := s.db.SendBatch(ctx, batch)
+	defer br.Close()
+	for _, name := range facets {
+		rows, err := br.Query()
+		if err != nil {
+			return nil, err
+		}
+		buckets := []FacetBucket{}
+		for rows.Next() {
+			var b FacetBucket
+			if err := rows.Scan(&b.Value, &b.Count); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			buckets = append(buckets, b)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		out[name] = buckets
+	}
+	return out, nil
+}
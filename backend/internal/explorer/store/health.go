@@ -0,0 +1,35 @@
+package store
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// SchemaVersion is the migration this build expects the database to have applied.
+const SchemaVersion = 18
+
+// Ping checks that the database answers.
+func (s *Postgres) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
+// AppliedSchemaVersion returns the newest migration recorded in schema_migrations,
+// or 0 when migrations predating that table are all the database has.
+func (s *Postgres) AppliedSchemaVersion(ctx context.Context) (int, error) {
+	var v int
+	err := s.db.QueryRow(ctx, `SELECT COALESCE(max(version), 0) FROM schema_migrations`).Scan(&v)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "42P01" { // undefined_table
+		return 0, nil
+	}
+	return v, err
+}
+
+// CountZeroIDAgents counts agents still indexed under the placeholder agent_id=0.
+func (s *Postgres) CountZeroIDAgents(ctx context.Context) (int64, error) {
+	var n int64
+	err := s.db.QueryRow(ctx, `SELECT count(*) FROM agents WHERE agent_id=0`).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"strings"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
+)
+
+// Matches evaluates a filter expression against the row in memory, with the same
+// semantics termSQL gives it in Postgres. Tool terms need the MCP catalog, which
+// rows don't carry, and never match.
+func (r AgentRow) Matches(x filter.Expr) bool {
+	return filter.Eval(x, r.matchTerm)
+}
+
+func (r AgentRow) matchTerm(t filter.Term) bool {
+	v := strings.ToLower(t.Value)
+	switch t.Field {
+	case filter.Network:
+		return r.ChainID == caip.Normalize(t.Value)
+	case filter.TrustModel:
+		return hasTrustModel(r.TrustModels, t.Value)
+	case filter.Skill:
+		return hasSkill(r.Skills, t.Value)
+	case filter.Tag:
+		return hasTag(r.Skills, t.Value)
+	case filter.Capability:
+		caps, _ := r.CardJSON["capabilities"].(map[string]any)
+		_, ok := caps[t.Value]
+		return ok
+	case filter.Endpoint:
+		for _, e := range r.Endpoints {
+			if strings.ToLower(e.Name) == v {
+				return true
+			}
+		}
+	case filter.Status:
+		return r.Status == v
+	}
+	return false
+}
+
+// hasTrustModel: case-insensitive equality, as $1 = ANY(trust_models) on lowercased input.
+func hasTrustModel(models []string, value string) bool {
+	v := strings.ToLower(value)
+	for _, m := range models {
+		if strings.ToLower(m) == v {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSkill: case-insensitive substring of a skill id or name, as ILIKE '%v%'.
+func hasSkill(skills []map[string]any, value string) bool {
+	v := strings.ToLower(value)
+	for _, s := range skills {
+		id, _ := s["id"].(string)
+		name, _ := s["name"].(string)
+		if strings.Contains(strings.ToLower(id), v) || strings.Contains(strings.ToLower(name), v) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag: case-insensitive equality with a tag of any skill.
+func hasTag(skills []map[string]any, value string) bool {
+	v := strings.ToLower(value)
+	for _, s := range skills {
+		tags, _ := s["tags"].([]any)
+		for _, t := range tags {
+			if x, ok := t.(string); ok && strings.ToLower(x) == v {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,105 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
+)
+
+func mustParse(t *testing.T, s string) filter.Expr {
+	t.Helper()
+	x, err := filter.Parse(s)
+	if err != nil {
+		t.Fatalf("%q: %v", s, err)
+	}
+	return x
+}
+
+func TestAgentRowMatches(t *testing.T) {
+	row := AgentRow{
+		ChainID:     "eip155:11155111",
+		TrustModels: []string{"reputation", "tee"},
+		Skills: []map[string]any{
+			{"id": "web-search", "name": "Web Search", "tags": []any{"Research", "web"}},
+		},
+		CardJSON:  map[string]any{"capabilities": map[string]any{"streaming": true}},
+		Endpoints: []Endpoint{{Name: "A2A", Endpoint: "https://a.example"}},
+		Status:    "up",
+	}
+	cases := []struct {
+		filter string
+		want   bool
+	}{
+		{"network:sepolia", true},
+		{"network:eip155:11155111", true},
+		{"network:base", false},
+		{"trust:TEE", true},
+		{"trust:te", false},
+		{"skill:search", true},
+		{"skill:web-se", true},
+		{"skill:translate", false},
+		{"tag:research", true},
+		{"tag:resea", false},
+		{"cap:streaming", true},
+		{"cap:pushNotifications", false},
+		{"endpoint:a2a", true},
+		{"endpoint:mcp", false},
+		{"status:UP", true},
+		{"-status:down", true},
+		{"tool:search", false},
+		{"-tool:search", true},
+		{"tag:web AND (trust:tee OR trust:crypto-economic)", true},
+		{"tag:web -network:sepolia", false},
+	}
+	for _, tc := range cases {
+		if got := row.Matches(mustParse(t, tc.filter)); got != tc.want {
+			t.Errorf("%q: Matches = %v, want %v", tc.filter, got, tc.want)
+		}
+	}
+
+	// without a probe status the row is not down, like NOT COALESCE(..., false)
+	row.Status = ""
+	if !row.Matches(mustParse(t, "-status:down")) || row.Matches(mustParse(t, "status:down")) {
+		t.Error("unprobed agent should match -status:down only")
+	}
+}
+
+func TestEventMatches(t *testing.T) {
+	e := Event{
+		ChainID: "eip155:11155111",
+		Agent: EventAgent{
+			Domain:      "weather.example",
+			Name:        "Forecaster",
+			TrustModels: []string{"reputation"},
+			Skills:      []map[string]any{{"id": "forecast", "name": "Daily Forecast", "tags": []any{"weather"}}},
+		},
+	}
+	cases := []struct {
+		name string
+		p    SearchParams
+		want bool
+	}{
+		{"empty", SearchParams{}, true},
+		{"q domain", SearchParams{Q: "WEATHER"}, true},
+		{"q name", SearchParams{Q: "caster"}, true},
+		{"q skill name", SearchParams{Q: "daily"}, true},
+		{"q no match", SearchParams{Q: "finance"}, false},
+		{"network alias", SearchParams{Network: "sepolia"}, true},
+		{"other network", SearchParams{Network: "eip155:1"}, false},
+		{"trust model", SearchParams{TrustModel: "Reputation"}, true},
+		{"skill", SearchParams{Skill: "fore"}, true},
+		{"tag", SearchParams{Tag: "WEATHER"}, true},
+		{"tag mismatch", SearchParams{Tag: "finance"}, false},
+		{"fields ANDed", SearchParams{Tag: "weather", TrustModel: "tee"}, false},
+		{"filter", SearchParams{Filters: []filter.Expr{mustParse(t, "tag:weather OR tag:finance")}}, true},
+		{"negated filter", SearchParams{Filters: []filter.Expr{mustParse(t, "-network:sepolia")}}, false},
+		// the snapshot carries no probe status or endpoints
+		{"status", SearchParams{Filters: []filter.Expr{mustParse(t, "status:up")}}, false},
+		{"endpoint", SearchParams{Filters: []filter.Expr{mustParse(t, "endpoint:a2a")}}, false},
+	}
+	for _, tc := range cases {
+		if got := e.Matches(tc.p); got != tc.want {
+			t.Errorf("%s: Matches = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
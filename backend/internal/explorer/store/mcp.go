@@ -0,0 +1,157 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+)
+
+// SaveMCPCatalog replaces the MCP catalog of an agent with a fresh introspection.
+func (s *Postgres) SaveMCPCatalog(ctx context.Context, chainID string, agentID int64, cat MCPCatalog) error {
+	caps, _ := json.Marshal(cat.Capabilities)
+	if cat.Capabilities == nil {
+		caps = []byte("{}")
+	}
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO agent_mcp_servers (chain_id, agent_id, endpoint, protocol_version, server_name, server_version, capabilities, introspected_at, error)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,now(),'')
+        ON CONFLICT (chain_id, agent_id)
+        DO UPDATE SET endpoint=EXCLUDED.endpoint, protocol_version=EXCLUDED.protocol_version, server_name=EXCLUDED.server_name,
+                      server_version=EXCLUDED.server_version, capabilities=EXCLUDED.capabilities, introspected_at=now(), error=''
+    `, chainID, agentID, cat.Endpoint, cat.ProtocolVersion, cat.ServerName, cat.ServerVersion, caps)
+	if err != nil {
+		return err
+	}
+
+	for _, table := range []string{"agent_mcp_tools", "agent_mcp_resources", "agent_mcp_prompts"} {
+		if _, err := tx.Exec(ctx, `DELETE FROM `+table+` WHERE chain_id=$1 AND agent_id=$2`, chainID, agentID); err != nil {
+			return err
+		}
+	}
+	for _, t := range cat.Tools {
+		if strings.TrimSpace(t.Name) == "" {
+			continue
+		}
+		schema, _ := json.Marshal(t.InputSchema)
+		if t.InputSchema == nil {
+			schema = []byte("{}")
+		}
+		_, err := tx.Exec(ctx, `
+            INSERT INTO agent_mcp_tools (chain_id, agent_id, name, title, description, input_schema)
+            VALUES ($1,$2,$3,$4,$5,$6) ON CONFLICT DO NOTHING
+        `, chainID, agentID, t.Name, t.Title, t.Description, schema)
+		if err != nil {
+			return err
+		}
+	}
+	for _, r := range cat.Resources {
+		if strings.TrimSpace(r.URI) == "" {
+			continue
+		}
+		_, err := tx.Exec(ctx, `
+            INSERT INTO agent_mcp_resources (chain_id, agent_id, uri, name, description, mime_type)
+            VALUES ($1,$2,$3,$4,$5,$6) ON CONFLICT DO NOTHING
+        `, chainID, agentID, r.URI, r.Name, r.Description, r.MimeType)
+		if err != nil {
+			return err
+		}
+	}
+	for _, p := range cat.Prompts {
+		if strings.TrimSpace(p.Name) == "" {
+			continue
+		}
+		args, _ := json.Marshal(p.Arguments)
+		if p.Arguments == nil {
+			args = []byte("[]")
+		}
+		_, err := tx.Exec(ctx, `
+            INSERT INTO agent_mcp_prompts (chain_id, agent_id, name, description, arguments)
+            VALUES ($1,$2,$3,$4,$5) ON CONFLICT DO NOTHING
+        `, chainID, agentID, p.Name, p.Description, args)
+		if err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// RecordMCPError notes a failed introspection without discarding the last good catalog.
+func (s *Postgres) RecordMCPError(ctx context.Context, chainID string, agentID int64, endpoint string, msg string) error {
+	_, err := s.db.Exec(ctx, `
+        INSERT INTO agent_mcp_servers (chain_id, agent_id, endpoint, introspected_at, error)
+        VALUES ($1,$2,$3,now(),$4)
+        ON CONFLICT (chain_id, agent_id)
+        DO UPDATE SET endpoint=EXCLUDED.endpoint, introspected_at=now(), error=EXCLUDED.error
+    `, chainID, agentID, endpoint, msg)
+	return err
+}
+
+// GetMCPCatalog returns the stored MCP catalog of an agent (pgx.ErrNoRows if never introspected).
+func (s *Postgres) GetMCPCatalog(ctx context.Context, chainID, agentID string) (MCPCatalog, error) {
+	var cat MCPCatalog
+	var caps []byte
+	err := s.db.QueryRow(ctx, `
+        SELECT endpoint, protocol_version, server_name, server_version, capabilities, introspected_at, error
+        FROM agent_mcp_servers WHERE chain_id=$1 AND agent_id=$2
+    `, chainID, agentID).Scan(&cat.Endpoint, &cat.ProtocolVersion, &cat.ServerName, &cat.ServerVersion, &caps, &cat.IntrospectedAt, &cat.Error)
+	if err != nil {
+		return cat, err
+	}
+	_ = json.Unmarshal(caps, &cat.Capabilities)
+
+	cat.Tools = []MCPTool{}
+	rows, err := s.db.Query(ctx, `SELECT name, title, description, input_schema FROM agent_mcp_tools WHERE chain_id=$1 AND agent_id=$2 ORDER BY name`, chainID, agentID)
+	if err != nil {
+		return cat, err
+	}
+	for rows.Next() {
+		var t MCPTool
+		var schema []byte
+		if err := rows.Scan(&t.Name, &t.Title, &t.Description, &schema); err != nil {
+			rows.Close()
+			return cat, err
+		}
+		_ = json.Unmarshal(schema, &t.InputSchema)
+		cat.Tools = append(cat.Tools, t)
+	}
+	rows.Close()
+
+	cat.Resources = []MCPResource{}
+	rows, err = s.db.Query(ctx, `SELECT uri, name, description, mime_type FROM agent_mcp_resources WHERE chain_id=$1 AND agent_id=$2 ORDER BY uri`, chainID, agentID)
+	if err != nil {
+		return cat, err
+	}
+	for rows.Next() {
+		var r MCPResource
+		if err := rows.Scan(&r.URI, &r.Name, &r.Description, &r.MimeType); err != nil {
+			rows.Close()
+			return cat, err
+		}
+		cat.Resources = append(cat.Resources, r)
+	}
+	rows.Close()
+
+	cat.Prompts = []MCPPrompt{}
+	rows, err = s.db.Query(ctx, `SELECT name, description, arguments FROM agent_mcp_prompts WHERE chain_id=$1 AND agent_id=$2 ORDER BY name`, chainID, agentID)
+	if err != nil {
+		return cat, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var p MCPPrompt
+		var args []byte
+		if err := rows.Scan(&p.Name, &p.Description, &args); err != nil {
+			return cat, err
+		}
+		_ = json.Unmarshal(args, &p.Arguments)
+		cat.Prompts = append(cat.Prompts, p)
+	}
+	return cat, rows.Err()
+}
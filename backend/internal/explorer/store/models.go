@@ -1,3 +1,120 @@
 package store
 
-// placeholder for future typed models and DTOs
+import (
+	"strings"
+	"time"
+)
+
+// Endpoint is a single entry of a registration's "endpoints" list, or the A2A
+// url advertised by an agent card. Extra holds any keys beyond name/endpoint/version
+// (e.g. MCP "capabilities") so nothing declared by the agent is lost.
+type Endpoint struct {
+	Source   string         `json:"source"`
+	Name     string         `json:"name"`
+	Endpoint string         `json:"endpoint"`
+	Version  string         `json:"version,omitempty"`
+	Extra    map[string]any `json:"extra,omitempty"`
+}
+
+// Endpoint sources
+const (
+	EndpointSourceRegistration = "registration"
+	EndpointSourceCard         = "card"
+)
+
+// Registration is a parsed ERC-8004 v1 registration file (the tokenURI target).
+type Registration struct {
+	TokenURI       string         `json:"tokenUri"`
+	Owner          string         `json:"owner,omitempty"`
+	Name           string         `json:"name,omitempty"`
+	Description    string         `json:"description,omitempty"`
+	Endpoints      []Endpoint     `json:"endpoints"`
+	SupportedTrust []string       `json:"supportedTrust"`
+	Wallets        []string       `json:"wallets"`
+	Verified       bool           `json:"verified"`
+	Raw            map[string]any `json:"raw"`
+}
+
+// Endpoint returns the first endpoint with the given name (case-insensitive).
+func (r Registration) Endpoint(name string) string {
+	for _, ep := range r.Endpoints {
+		if strings.EqualFold(strings.TrimSpace(ep.Name), name) {
+			return ep.Endpoint
+		}
+	}
+	return ""
+}
+
+// MCPCatalog is what an agent's MCP server advertised during introspection.
+type MCPCatalog struct {
+	Endpoint        string         `json:"endpoint"`
+	ProtocolVersion string         `json:"protocolVersion,omitempty"`
+	ServerName      string         `json:"serverName,omitempty"`
+	ServerVersion   string         `json:"serverVersion,omitempty"`
+	Capabilities    map[string]any `json:"capabilities,omitempty"`
+	Tools           []MCPTool      `json:"tools"`
+	Resources       []MCPResource  `json:"resources"`
+	Prompts         []MCPPrompt    `json:"prompts"`
+	IntrospectedAt  time.Time      `json:"introspectedAt"`
+	Error           string         `json:"error,omitempty"`
+}
+
+// MCPTool is one entry of tools/list.
+type MCPTool struct {
+	Name        string         `json:"name"`
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	InputSchema map[string]any `json:"inputSchema,omitempty"`
+}
+
+// MCPResource is one entry of resources/list.
+type MCPResource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// MCPPrompt is one entry of prompts/list.
+type MCPPrompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []map[string]any `json:"arguments,omitempty"`
+}
+
+// APIKey is an admin API key. Only the sha256 of the key is ever stored.
+type APIKey struct {
+	ID           int64      `json:"id"`
+	Name         string     `json:"name"`
+	Prefix       string     `json:"prefix"`
+	Role         string     `json:"role"`
+	OwnerAddress string     `json:"ownerAddress,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+	LastUsedAt   *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+}
+
+// AdminSession is a SIWE-authenticated session.
+type AdminSession struct {
+	Address   string    `json:"address"`
+	Role      string    `json:"role"`
+	ChainID   int64     `json:"chainId"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// AuditEntry is one row of admin_audit_log.
+type AuditEntry struct {
+	ID        int64          `json:"id"`
+	At        time.Time      `json:"at"`
+	ActorKind string         `json:"actorKind"`
+	ActorID   string         `json:"actorId"`
+	Role      string         `json:"role"`
+	Action    string         `json:"action"`
+	Method    string         `json:"method"`
+	Path      string         `json:"path"`
+	ChainID   string         `json:"chainId,omitempty"`
+	AgentID   string         `json:"agentId,omitempty"`
+	Request   map[string]any `json:"request,omitempty"`
+	Status    int            `json:"status"`
+	RemoteIP  string         `json:"remoteIp,omitempty"`
+}
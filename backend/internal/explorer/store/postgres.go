@@ -3,17 +3,33 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/praxis/praxis-explorer/internal/explorer/caip"
+	"github.com/praxis/praxis-explorer/internal/explorer/embed"
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
 )
 
-type Postgres struct{ db *pgxpool.Pool }
+type Postgres struct {
+	db *pgxpool.Pool
+
+	vectorOnce sync.Once
+	vector     bool // pgvector column present; see hasPgvector
+}
 
 func NewPostgres(url string) (*Postgres, error) {
-	pool, err := pgxpool.New(context.Background(), url)
+	cfg, err := pgxpool.ParseConfig(url)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ConnConfig.Tracer = queryTracer{}
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
 	if err != nil {
 		return nil, err
 	}
@@ -34,6 +50,19 @@ type AgentRow struct {
 	ValidationsCnt int              `json:"validationsCnt"`
 	FeedbacksCnt   int              `json:"feedbacksCnt"`
 	LastSeenAt     time.Time        `json:"lastSeenAt"`
+	RegisteredAt   time.Time        `json:"registeredAt"`
+	Status         string           `json:"status,omitempty"`
+	UptimePct      *float64         `json:"uptimePct,omitempty"`
+	LatencyP50Ms   *float64         `json:"latencyP50Ms,omitempty"`
+	LatencyP95Ms   *float64         `json:"latencyP95Ms,omitempty"`
+	LastProbedAt   *time.Time       `json:"lastProbedAt,omitempty"`
+	Endpoints      []Endpoint       `json:"endpoints,omitempty"`
+
+	// blocks of the registering and latest updating identity events, when indexed
+	RegisteredBlock *int64 `json:"registeredBlock,omitempty"`
+	UpdatedAtBlock  *int64 `json:"updatedAtBlock,omitempty"`
+
+	cursor string // set by SearchAgents; see CursorFor
 }
 
 type SearchParams struct {
@@ -43,8 +72,18 @@ type SearchParams struct {
 	Skill      string
 	Tag        string
 	TrustModel string
+	Endpoint   string
+	Status     string
+	Tool       string
 	Limit      int
 	Cursor     string
+	// Sort orders the results; the zero value is relevance, descending.
+	Sort Sort
+	// Semantic keeps agents whose card is similar to this text (see package embed);
+	// relevance then ranks by that similarity.
+	Semantic string
+	// Filters are ANDed with the fields above; see package filter.
+	Filters []filter.Expr
 }
 
 func (s *Postgres) UpsertAgentFromCard(ctx context.Context, chainID string, registryAddr string, agentID int64, domain string, card map[string]any) error {
@@ -60,6 +99,10 @@ func (s *Postgres) UpsertAgentFromCard(ctx context.Context, chainID string, regi
 			} // backward compat
 		}
 	}
+	// bare or CAIP-10, any case: stored as checksummed CAIP-10, or not at all
+	if address != "" {
+		address, _ = caip.NormalizeAccount(address, chainID)
+	}
 	trustModels := []string{}
 	if arr, ok := card["trustModels"].([]any); ok {
 		for _, x := range arr {
@@ -81,14 +124,154 @@ func (s *Postgres) UpsertAgentFromCard(ctx context.Context, chainID string, regi
 		caps = m
 	}
 
+	// the card's url is its A2A endpoint; record it so endpoint filters cover card-only agents too
+	var endpoints []Endpoint
+	if u, ok := card["url"].(string); ok && strings.TrimSpace(u) != "" {
+		ep := Endpoint{Source: EndpointSourceCard, Name: "A2A", Endpoint: strings.TrimSpace(u)}
+		if v, ok := card["protocolVersion"].(string); ok {
+			ep.Version = v
+		}
+		endpoints = append(endpoints, ep)
+	}
+
 	b, _ := json.Marshal(card)
-	_, err := s.db.Exec(ctx, `
-        INSERT INTO agents (chain_id, registry_addr, agent_id, domain, address_caip10, card_json, trust_models, skills, capabilities, last_seen_at)
-        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9, now())
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// compare with the stored row so only real changes are logged as events
+	var existed, sameCard bool
+	var oldDomain string
+	err = tx.QueryRow(ctx, `SELECT card_json = $3::jsonb, domain FROM agents WHERE chain_id=$1 AND agent_id=$2 FOR UPDATE`, chainID, agentID, b).Scan(&sameCard, &oldDomain)
+	switch {
+	case err == nil:
+		existed = true
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO agents (chain_id, registry_addr, agent_id, domain, address_caip10, card_json, trust_models, skills, capabilities, last_seen_at, `+eventBlockColumns+`)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9, now(), `+eventBlockValues+`)
         ON CONFLICT (chain_id, agent_id)
-        DO UPDATE SET registry_addr=EXCLUDED.registry_addr, domain=EXCLUDED.domain, address_caip10=EXCLUDED.address_caip10, card_json=EXCLUDED.card_json, trust_models=EXCLUDED.trust_models, skills=EXCLUDED.skills, capabilities=EXCLUDED.capabilities, last_seen_at=now()
+        DO UPDATE SET registry_addr=EXCLUDED.registry_addr, domain=EXCLUDED.domain, address_caip10=`+cardAddress+`, card_json=EXCLUDED.card_json, trust_models=EXCLUDED.trust_models, skills=EXCLUDED.skills, capabilities=EXCLUDED.capabilities, last_seen_at=now()
     `, chainID, registryAddr, agentID, domain, address, b, trustModels, skills, caps)
-	return err
+	if err != nil {
+		return err
+	}
+	if err := replaceEndpoints(ctx, tx, chainID, agentID, EndpointSourceCard, endpoints); err != nil {
+		return err
+	}
+	if err := upsertEmbedding(ctx, tx, chainID, agentID, card); err != nil {
+		return err
+	}
+	switch {
+	case !existed:
+		err = recordEvent(ctx, tx, EventAgentRegistered, chainID, agentID, map[string]any{"source": EndpointSourceCard})
+	case !sameCard:
+		err = recordEvent(ctx, tx, EventCardChanged, chainID, agentID, nil)
+	case oldDomain != domain:
+		err = recordEvent(ctx, tx, EventAgentUpdated, chainID, agentID, map[string]any{"changed": []string{"domain"}})
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// searchWhere builds the WHERE conditions for every filter in p except the facet
+// named by except, appending their arguments to args. Facets pass their own name so
+// their counts ignore their own selection.
+func searchWhere(p SearchParams, args *[]any, except string) []string {
+	where := []string{}
+	add := func(v any) int {
+		*args = append(*args, v)
+		return len(*args)
+	}
+	// q: search by domain or name or skill name
+	if q := strings.TrimSpace(p.Q); q != "" {
+		idx := add("%" + q + "%")
+		where = append(where, fmt.Sprintf("(domain ILIKE $%d OR card_json->>'name' ILIKE $%d OR EXISTS (SELECT 1 FROM jsonb_array_elements(skills) s WHERE s->>'name' ILIKE $%d))", idx, idx, idx))
+	}
+	if sem := strings.TrimSpace(p.Semantic); sem != "" {
+		where = append(where, fmt.Sprintf("%s >= $%d", similaritySQL(add(embed.Text(sem))), add(embed.MinSimilarity)))
+	}
+	for _, f := range []struct{ field, value string }{
+		{filter.TrustModel, p.TrustModel},
+		{filter.Skill, p.Skill},
+		{filter.Tag, p.Tag},
+		{filter.Capability, p.Capability},
+		{filter.Endpoint, p.Endpoint},
+		{filter.Tool, p.Tool},
+		{filter.Network, p.Network},
+		{filter.Status, p.Status},
+	} {
+		if v := strings.TrimSpace(f.value); v != "" && f.field != except {
+			where = append(where, termSQL(filter.Term{Field: f.field, Value: v}, add))
+		}
+	}
+	for _, x := range p.Filters {
+		if fields := filter.FieldsOf(x); len(fields) == 1 && fields[0] == except {
+			continue
+		}
+		where = append(where, filterSQL(x, add))
+	}
+	return where
+}
+
+// termSQL is the condition for one filter term; SearchParams fields and filter
+// expressions both go through it so they mean the same thing.
+func termSQL(t filter.Term, add func(any) int) string {
+	switch t.Field {
+	case filter.TrustModel: // case-insensitive
+		return fmt.Sprintf("$%d = ANY(trust_models)", add(strings.ToLower(t.Value)))
+	case filter.Skill: // by id or name
+		idx := add("%" + t.Value + "%")
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements(skills) s WHERE s->>'id' ILIKE $%d OR s->>'name' ILIKE $%d)", idx, idx)
+	case filter.Tag: // inside skills[].tags
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements(skills) s, jsonb_array_elements_text(COALESCE(s->'tags','[]'::jsonb)) t WHERE lower(t)= $%d)", add(strings.ToLower(t.Value)))
+	case filter.Capability: // presence of key OR key set to true
+		idx := add(t.Value)
+		return fmt.Sprintf("((card_json->'capabilities' ? $%d) OR ((card_json->'capabilities'->>$%d)::boolean IS TRUE))", idx, idx)
+	case filter.Endpoint: // agent declares an endpoint of this type (A2A, MCP, DID, ...)
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM agent_endpoints e WHERE e.chain_id = agents.chain_id AND e.agent_id = agents.agent_id AND lower(e.name) = $%d)", add(strings.ToLower(t.Value)))
+	case filter.Tool: // agent's MCP server advertises a tool whose name matches
+		idx := add("%" + t.Value + "%")
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM agent_mcp_tools t WHERE t.chain_id = agents.chain_id AND t.agent_id = agents.agent_id AND (t.name ILIKE $%d OR t.title ILIKE $%d))", idx, idx)
+	case filter.Network: // chain_id equals; network names resolve to their CAIP-2 id
+		return fmt.Sprintf("agents.chain_id = $%d", add(caip.Normalize(t.Value)))
+	case filter.Status: // current probe status from agent_health (up | degraded | down)
+		return fmt.Sprintf("h.status = $%d", add(strings.ToLower(t.Value)))
+	}
+	// the parser only produces known fields
+	return "false"
+}
+
+// filterSQL compiles a filter expression. Values are always bound as arguments;
+// NOT treats an unknown (NULL) condition as false, so agents without a probe status
+// match -status:down.
+func filterSQL(x filter.Expr, add func(any) int) string {
+	switch x := x.(type) {
+	case filter.Term:
+		return termSQL(x, add)
+	case filter.Not:
+		return "NOT COALESCE(" + filterSQL(x.X, add) + ", false)"
+	case filter.And:
+		parts := make([]string, len(x))
+		for i, y := range x {
+			parts[i] = filterSQL(y, add)
+		}
+		return "(" + strings.Join(parts, " AND ") + ")"
+	case filter.Or:
+		parts := make([]string, len(x))
+		for i, y := range x {
+			parts[i] = filterSQL(y, add)
+		}
+		return "(" + strings.Join(parts, " OR ") + ")"
+	}
+	return "false"
 }
 
 func (s *Postgres) SearchAgents(ctx context.Context, p SearchParams) ([]AgentRow, string, error) {
@@ -96,93 +279,114 @@ func (s *Postgres) SearchAgents(ctx context.Context, p SearchParams) ([]AgentRow
 	if p.Limit > 0 && p.Limit <= 200 {
 		limit = p.Limit
 	}
-	where := []string{}
 	args := []any{}
+	where := searchWhere(p, &args, "")
+	add := func(v any) int {
+		args = append(args, v)
+		return len(args)
+	}
+	sort := p.Sort.resolve(p)
+	col := sort.column(p, add)
+	dir, cmp := "DESC", "<"
+	if sort.Asc {
+		dir, cmp = "ASC", ">"
+	}
 
-	// q: search by domain or name or skill name
-	if q := strings.TrimSpace(p.Q); q != "" {
-		args = append(args, "%"+q+"%")
-		idx := len(args)
-		where = append(where, fmt.Sprintf("(domain ILIKE $%d OR card_json->>'name' ILIKE $%d OR EXISTS (SELECT 1 FROM jsonb_array_elements(skills) s WHERE s->>'name' ILIKE $%d))", idx, idx, idx))
+	// cursor: keyset position of the last row of the previous page, in the same order
+	if cur := strings.TrimSpace(p.Cursor); cur != "" {
+		c, err := decodeCursor(cur)
+		if err != nil {
+			return nil, "", err
+		}
+		if c.Sort != sort {
+			return nil, "", ErrInvalidCursor
+		}
+		where = append(where, fmt.Sprintf("(%s, agents.chain_id, agents.agent_id) %s ($%d, $%d, $%d)", col.expr, cmp, add(c.Value), add(c.ChainID), add(c.AgentID)))
 	}
-	// trustModel: case-insensitive
-	if tm := strings.TrimSpace(p.TrustModel); tm != "" {
-		args = append(args, strings.ToLower(tm))
-		idx := len(args)
-		where = append(where, fmt.Sprintf("$%d = ANY(trust_models)", idx))
-	}
-	// skill: by id or name
-	if sk := strings.TrimSpace(p.Skill); sk != "" {
-		args = append(args, "%"+sk+"%")
-		idx := len(args)
-		where = append(where, fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements(skills) s WHERE s->>'id' ILIKE $%d OR s->>'name' ILIKE $%d)", idx, idx))
-	}
-	// tag: inside skills[].tags
-	if tg := strings.TrimSpace(p.Tag); tg != "" {
-		args = append(args, strings.ToLower(tg))
-		idx := len(args)
-		where = append(where, fmt.Sprintf("EXISTS (SELECT 1 FROM jsonb_array_elements(skills) s, jsonb_array_elements_text(COALESCE(s->'tags','[]'::jsonb)) t WHERE lower(t)= $%d)", idx))
-	}
-	// capability: presence in card_json.capabilities keys or true value
-	if cap := strings.TrimSpace(p.Capability); cap != "" {
-		// presence of key OR key set to true
-		args = append(args, cap)
-		idx := len(args)
-		where = append(where, fmt.Sprintf("((card_json->'capabilities' ? $%d) OR ((card_json->'capabilities'->>$%d)::boolean IS TRUE))", idx, idx))
-	}
-	// network: chain_id equals (optional)
-	if net := strings.TrimSpace(p.Network); net != "" {
-		args = append(args, net)
-		idx := len(args)
-		where = append(where, fmt.Sprintf("chain_id = $%d", idx))
-	}
-
-	sql := `
-        SELECT chain_id, agent_id, registry_addr, domain, address_caip10, card_json, trust_models, skills, capabilities, score_avg, validations_cnt, feedbacks_cnt, last_seen_at
-        FROM agents`
+
+	sql := `SELECT ` + agentColumns + `, ` + col.expr + ` FROM ` + agentFrom
 	if len(where) > 0 {
 		sql += " WHERE " + strings.Join(where, " AND ")
 	}
-	sql += " ORDER BY last_seen_at DESC LIMIT $" + fmt.Sprint(len(args)+1)
-	args = append(args, limit)
+	// one extra row tells us whether there is a next page
+	sql += fmt.Sprintf(" ORDER BY %[1]s %[2]s, agents.chain_id %[2]s, agents.agent_id %[2]s LIMIT $%[3]d", col.expr, dir, add(limit+1))
 
 	rows, err := s.db.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, "", err
 	}
 	defer rows.Close()
-	var out []AgentRow
+	out := []AgentRow{}
 	for rows.Next() {
-		var r AgentRow
-		var cardBytes []byte
-		var skillsBytes []byte
-		var capsBytes []byte
-		err := rows.Scan(&r.ChainID, &r.AgentID, &r.RegistryAddr, &r.Domain, &r.AddressCAIP, &cardBytes, &r.TrustModels, &skillsBytes, &capsBytes, &r.ScoreAvg, &r.ValidationsCnt, &r.FeedbacksCnt, &r.LastSeenAt)
+		key := col.kind.scanTarget()
+		r, err := scanAgent(rows, key)
 		if err != nil {
 			return nil, "", err
 		}
-		_ = json.Unmarshal(cardBytes, &r.CardJSON)
-		if len(skillsBytes) > 0 {
-			_ = json.Unmarshal(skillsBytes, &r.Skills)
-		}
-		if len(capsBytes) > 0 {
-			_ = json.Unmarshal(capsBytes, &r.Capabilities)
-		}
+		r.cursor = encodeCursor(agentCursor{Sort: sort, Value: deref(key), ChainID: r.ChainID, AgentID: r.AgentID})
 		out = append(out, r)
 	}
-	return out, "", nil
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+	next := ""
+	if len(out) > limit {
+		out = out[:limit]
+		next = CursorFor(out[limit-1])
+	}
+	return out, next, nil
 }
 
 func (s *Postgres) GetAgent(ctx context.Context, chainID, agentID string) (AgentRow, error) {
-	row := s.db.QueryRow(ctx, `
-        SELECT chain_id, agent_id, registry_addr, domain, address_caip10, card_json, trust_models, skills, capabilities, score_avg, validations_cnt, feedbacks_cnt, last_seen_at
-        FROM agents WHERE chain_id=$1 AND agent_id=$2
-    `, chainID, agentID)
+	row := s.db.QueryRow(ctx, `SELECT `+agentColumns+` FROM `+agentFrom+` WHERE agents.chain_id=$1 AND agents.agent_id=$2`, chainID, agentID)
+	r, err := scanAgent(row)
+	if err != nil {
+		return AgentRow{}, err
+	}
+	r.Endpoints, err = s.ListEndpoints(ctx, chainID, agentID)
+	if err != nil {
+		return AgentRow{}, err
+	}
+	return r, nil
+}
+
+// ListAgentsByDomain returns the resolved agents (agent_id is not the 0
+// placeholder) registered under domain on any chain, ignoring case, through
+// idx_agents_domain_lower.
+func (s *Postgres) ListAgentsByDomain(ctx context.Context, domain string) ([]AgentRow, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+agentColumns+` FROM `+agentFrom+`
+        WHERE lower(agents.domain) = lower($1) AND agents.agent_id <> 0 ORDER BY agents.chain_id, agents.agent_id`, domain)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []AgentRow{}
+	for rows.Next() {
+		r, err := scanAgent(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// agentColumns and agentFrom are shared by every query returning AgentRow; scanAgent
+// must be kept in the same order.
+const agentColumns = `agents.chain_id, agents.agent_id, agents.registry_addr, agents.domain, agents.address_caip10, agents.card_json, agents.trust_models, agents.skills, agents.capabilities, agents.score_avg, agents.validations_cnt, agents.feedbacks_cnt, agents.last_seen_at, agents.registered_at,
+        agents.registered_block, agents.updated_at_block, COALESCE(h.status, ''), h.uptime_pct, h.latency_p50_ms, h.latency_p95_ms, h.last_probed_at`
+
+const agentFrom = `agents LEFT JOIN agent_health h ON h.chain_id = agents.chain_id AND h.agent_id = agents.agent_id`
+
+// scanAgent scans the agentColumns, then any extra columns selected after them into extra.
+func scanAgent(row pgx.Row, extra ...any) (AgentRow, error) {
 	var r AgentRow
 	var cardBytes []byte
 	var skillsBytes []byte
 	var capsBytes []byte
-	err := row.Scan(&r.ChainID, &r.AgentID, &r.RegistryAddr, &r.Domain, &r.AddressCAIP, &cardBytes, &r.TrustModels, &skillsBytes, &capsBytes, &r.ScoreAvg, &r.ValidationsCnt, &r.FeedbacksCnt, &r.LastSeenAt)
+	dest := []any{&r.ChainID, &r.AgentID, &r.RegistryAddr, &r.Domain, &r.AddressCAIP, &cardBytes, &r.TrustModels, &skillsBytes, &capsBytes, &r.ScoreAvg, &r.ValidationsCnt, &r.FeedbacksCnt, &r.LastSeenAt, &r.RegisteredAt,
+		&r.RegisteredBlock, &r.UpdatedAtBlock, &r.Status, &r.UptimePct, &r.LatencyP50Ms, &r.LatencyP95Ms, &r.LastProbedAt}
+	err := row.Scan(append(dest, extra...)...)
 	if err != nil {
 		return AgentRow{}, err
 	}
@@ -219,6 +423,16 @@ func (s *Postgres) ListZeroIDAgents(ctx context.Context, chainID string, limit i
 
 // DeleteAgent removes a specific (chain_id, agent_id) row.
 func (s *Postgres) DeleteAgent(ctx context.Context, chainID string, agentID int64) error {
-	_, err := s.db.Exec(ctx, `DELETE FROM agents WHERE chain_id=$1 AND agent_id=$2`, chainID, agentID)
-	return err
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	if err := recordEvent(ctx, tx, EventAgentDeleted, chainID, agentID, nil); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM agents WHERE chain_id=$1 AND agent_id=$2`, chainID, agentID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
@@ -0,0 +1,72 @@
+package store
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
+)
+
+func TestFilterSQL(t *testing.T) {
+	cases := []struct {
+		filter string
+		sql    string
+		args   []any
+	}{
+		{"trust:TEE", "$1 = ANY(trust_models)", []any{"tee"}},
+		{"network:sepolia", "agents.chain_id = $1", []any{"eip155:11155111"}},
+		{"status:Up", "h.status = $1", []any{"up"}},
+		{"-status:down", "NOT COALESCE(h.status = $1, false)", []any{"down"}},
+		{"skill:search", "EXISTS (SELECT 1 FROM jsonb_array_elements(skills) s WHERE s->>'id' ILIKE $1 OR s->>'name' ILIKE $1)", []any{"%search%"}},
+		{"endpoint:MCP", "EXISTS (SELECT 1 FROM agent_endpoints e WHERE e.chain_id = agents.chain_id AND e.agent_id = agents.agent_id AND lower(e.name) = $1)", []any{"mcp"}},
+		{"cap:streaming", "((card_json->'capabilities' ? $1) OR ((card_json->'capabilities'->>$1)::boolean IS TRUE))", []any{"streaming"}},
+		{"trust:tee OR status:up", "($1 = ANY(trust_models) OR h.status = $2)", []any{"tee", "up"}},
+		{"network:base -(trust:tee OR trust:zkml)", "(agents.chain_id = $1 AND NOT COALESCE(($2 = ANY(trust_models) OR $3 = ANY(trust_models)), false))", []any{"eip155:8453", "tee", "zkml"}},
+	}
+	for _, tc := range cases {
+		var args []any
+		add := func(v any) int {
+			args = append(args, v)
+			return len(args)
+		}
+		x, err := filter.Parse(tc.filter)
+		if err != nil {
+			t.Fatalf("%q: %v", tc.filter, err)
+		}
+		if sql := filterSQL(x, add); sql != tc.sql {
+			t.Errorf("%q: sql\n got %s\nwant %s", tc.filter, sql, tc.sql)
+		}
+		if !reflect.DeepEqual(args, tc.args) {
+			t.Errorf("%q: args %v, want %v", tc.filter, args, tc.args)
+		}
+	}
+}
+
+func TestSearchWhereExceptFacet(t *testing.T) {
+	ownOnly, _ := filter.Parse("trust:tee OR trust:zkml")
+	mixed, _ := filter.Parse("trust:tee OR status:up")
+	p := SearchParams{TrustModel: "reputation", Network: "sepolia", Filters: []filter.Expr{ownOnly, mixed}}
+
+	cases := []struct {
+		except string
+		args   []any
+	}{
+		// a search applies everything
+		{"", []any{"reputation", "eip155:11155111", "tee", "zkml", "tee", "up"}},
+		// the trust facet drops its own parameter and the filter only on trust, but
+		// keeps the filter that also constrains status
+		{filter.TrustModel, []any{"eip155:11155111", "tee", "up"}},
+		{filter.Network, []any{"reputation", "tee", "zkml", "tee", "up"}},
+	}
+	for _, tc := range cases {
+		var args []any
+		where := searchWhere(p, &args, tc.except)
+		if !reflect.DeepEqual(args, tc.args) {
+			t.Errorf("except %q: args %v, want %v", tc.except, args, tc.args)
+		}
+		if n := strings.Count(strings.Join(where, " AND "), "$"); n != len(args) {
+			t.Errorf("except %q: %d placeholders for %d args: %v", tc.except, n, len(args), where)
+		}
+	}
+}
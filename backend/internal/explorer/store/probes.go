@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Probe kinds
+const (
+	ProbeCard = "card"
+	ProbeA2A  = "a2a"
+	ProbeMCP  = "mcp"
+)
+
+// Agent health statuses
+const (
+	StatusUp       = "up"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+// ProbeTarget is everything the prober needs to know about one agent.
+type ProbeTarget struct {
+	ChainID string
+	AgentID int64
+	Domain  string
+	HasA2A  bool
+	A2AURL  string
+	MCPURL  string
+}
+
+// ProbeResult is one endpoint check, stored as a row of agent_probes.
+type ProbeResult struct {
+	Kind        string    `json:"kind"`
+	URL         string    `json:"url"`
+	ProbedAt    time.Time `json:"probedAt"`
+	HTTPStatus  int       `json:"httpStatus,omitempty"`
+	LatencyMs   float64   `json:"latencyMs"`
+	TLSValid    *bool     `json:"tlsValid,omitempty"`
+	HandshakeOK bool      `json:"handshakeOk"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// ListProbeTargets returns every indexed agent with the endpoints worth probing.
+func (s *Postgres) ListProbeTargets(ctx context.Context) ([]ProbeTarget, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT a.chain_id, a.agent_id, a.domain,
+               (a.card_json ? 'url') OR EXISTS (SELECT 1 FROM agent_endpoints e WHERE e.chain_id = a.chain_id AND e.agent_id = a.agent_id AND lower(e.name) = 'a2a'),
+               COALESCE(a.card_json->>'url', ''),
+               COALESCE((SELECT e.endpoint FROM agent_endpoints e WHERE e.chain_id = a.chain_id AND e.agent_id = a.agent_id AND lower(e.name) = 'mcp' ORDER BY e.source DESC LIMIT 1), '')
+        FROM agents a
+        ORDER BY a.chain_id, a.agent_id
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []ProbeTarget{}
+	for rows.Next() {
+		var t ProbeTarget
+		if err := rows.Scan(&t.ChainID, &t.AgentID, &t.Domain, &t.HasA2A, &t.A2AURL, &t.MCPURL); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+// RecordProbes stores one probe round for an agent and refreshes its health summary:
+// status from this round, uptime and latency percentiles over the trailing window.
+func (s *Postgres) RecordProbes(ctx context.Context, chainID string, agentID int64, results []ProbeResult, status string, window time.Duration) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, r := range results {
+		var httpStatus *int
+		if r.HTTPStatus != 0 {
+			httpStatus = &r.HTTPStatus
+		}
+		_, err := tx.Exec(ctx, `
+            INSERT INTO agent_probes (chain_id, agent_id, kind, url, probed_at, http_status, latency_ms, tls_valid, handshake_ok, error)
+            VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+        `, chainID, agentID, r.Kind, r.URL, r.ProbedAt, httpStatus, r.LatencyMs, r.TLSValid, r.HandshakeOK, r.Error)
+		if err != nil {
+			return err
+		}
+	}
+
+	var prevStatus string
+	err = tx.QueryRow(ctx, `SELECT status FROM agent_health WHERE chain_id=$1 AND agent_id=$2 FOR UPDATE`, chainID, agentID).Scan(&prevStatus)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO agent_health (chain_id, agent_id, status, uptime_pct, latency_p50_ms, latency_p95_ms, last_probed_at)
+        SELECT $1, $2, $3,
+               100.0 * count(*) FILTER (WHERE handshake_ok) / NULLIF(count(*), 0),
+               percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_ms) FILTER (WHERE handshake_ok),
+               percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms) FILTER (WHERE handshake_ok),
+               now()
+        FROM agent_probes
+        WHERE chain_id = $1 AND agent_id = $2 AND probed_at > now() - make_interval(secs => $4)
+        ON CONFLICT (chain_id, agent_id)
+        DO UPDATE SET status=EXCLUDED.status, uptime_pct=EXCLUDED.uptime_pct, latency_p50_ms=EXCLUDED.latency_p50_ms, latency_p95_ms=EXCLUDED.latency_p95_ms, last_probed_at=EXCLUDED.last_probed_at
+    `, chainID, agentID, status, window.Seconds())
+	if err != nil {
+		return err
+	}
+	if prevStatus != status {
+		if err := recordEvent(ctx, tx, EventStatusChanged, chainID, agentID, map[string]any{"from": prevStatus, "to": status}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit(ctx)
+}
+
+// ListProbes returns the most recent probe results of an agent, newest first.
+func (s *Postgres) ListProbes(ctx context.Context, chainID, agentID string, limit int) ([]ProbeResult, error) {
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	rows, err := s.db.Query(ctx, `
+        SELECT kind, url, probed_at, COALESCE(http_status, 0), COALESCE(latency_ms, 0), tls_valid, handshake_ok, error
+        FROM agent_probes WHERE chain_id=$1 AND agent_id=$2
+        ORDER BY probed_at DESC LIMIT $3
+    `, chainID, agentID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []ProbeResult{}
+	for rows.Next() {
+		var r ProbeResult
+		if err := rows.Scan(&r.Kind, &r.URL, &r.ProbedAt, &r.HTTPStatus, &r.LatencyMs, &r.TLSValid, &r.HandshakeOK, &r.Error); err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// PruneProbes deletes probe rows older than the retention period.
+func (s *Postgres) PruneProbes(ctx context.Context, olderThan time.Duration) error {
+	_, err := s.db.Exec(ctx, `DELETE FROM agent_probes WHERE probed_at < now() - make_interval(secs => $1)`, olderThan.Seconds())
+	return err
+}
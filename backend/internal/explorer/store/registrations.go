@@ -0,0 +1,242 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// UpsertRegistration persists a v1 registration file together with its endpoints,
+// supported trust models and wallets. The agent row is created if missing so that
+// MCP-only and DID-only agents are indexed as first-class rows; when replaceCard is
+// set (the registration declares no A2A card) the registration also becomes its
+// card, dropping the skills, capabilities and endpoints of any previous one.
+func (s *Postgres) UpsertRegistration(ctx context.Context, chainID string, registryAddr string, agentID int64, domain string, reg Registration, replaceCard bool) error {
+	trust := make([]string, 0, len(reg.SupportedTrust))
+	for _, t := range reg.SupportedTrust {
+		trust = append(trust, strings.ToLower(strings.TrimSpace(t)))
+	}
+	raw, _ := json.Marshal(reg.Raw)
+
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// what the agent looked like before, for the event log
+	var existed, hadRegistration bool
+	var prev struct {
+		tokenURI, owner string
+		sameContent     bool
+	}
+	if err := tx.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM agents WHERE chain_id=$1 AND agent_id=$2)`, chainID, agentID).Scan(&existed); err != nil {
+		return err
+	}
+	err = tx.QueryRow(ctx, `SELECT token_uri, owner_address, registration_json = $3::jsonb FROM agent_registrations WHERE chain_id=$1 AND agent_id=$2 FOR UPDATE`,
+		chainID, agentID, raw).Scan(&prev.tokenURI, &prev.owner, &prev.sameContent)
+	switch {
+	case err == nil:
+		hadRegistration = true
+	case !errors.Is(err, pgx.ErrNoRows):
+		return err
+	}
+
+	onConflict := `DO UPDATE SET registry_addr=EXCLUDED.registry_addr, last_seen_at=now()`
+	if replaceCard {
+		onConflict = `DO UPDATE SET registry_addr=EXCLUDED.registry_addr, domain=EXCLUDED.domain, address_caip10=` + cardAddress + `, card_json=EXCLUDED.card_json, trust_models=EXCLUDED.trust_models, skills='[]'::jsonb, capabilities='{}'::jsonb, last_seen_at=now()`
+	}
+	_, err = tx.Exec(ctx, `
+        INSERT INTO agents (chain_id, registry_addr, agent_id, domain, address_caip10, card_json, trust_models, skills, capabilities, last_seen_at, `+eventBlockColumns+`)
+        VALUES ($1,$2,$3,$4,$5,$6,$7,'[]'::jsonb,'{}'::jsonb, now(), `+eventBlockValues+`)
+        ON CONFLICT (chain_id, agent_id) `+onConflict,
+		chainID, registryAddr, agentID, domain, ownerAccount(reg.Owner, chainID), raw, trust)
+	if err != nil {
+		return err
+	}
+	// the registration is the card of new agents too
+	if replaceCard || !existed {
+		if err := upsertEmbedding(ctx, tx, chainID, agentID, reg.Raw); err != nil {
+			return err
+		}
+	}
+	// an A2A card the registration no longer declares leaves no endpoints behind
+	if replaceCard {
+		if err := replaceEndpoints(ctx, tx, chainID, agentID, EndpointSourceCard, nil); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+        INSERT INTO agent_registrations (chain_id, agent_id, token_uri, owner_address, registration_json, content_verified, fetched_at)
+        VALUES ($1,$2,$3,$4,$5,$6, now())
+        ON CONFLICT (chain_id, agent_id)
+        DO UPDATE SET token_uri=EXCLUDED.token_uri, owner_address=EXCLUDED.owner_address, registration_json=EXCLUDED.registration_json, content_verified=EXCLUDED.content_verified, fetched_at=now()
+    `, chainID, agentID, reg.TokenURI, reg.Owner, raw, reg.Verified)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceEndpoints(ctx, tx, chainID, agentID, EndpointSourceRegistration, reg.Endpoints); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM agent_supported_trust WHERE chain_id=$1 AND agent_id=$2`, chainID, agentID); err != nil {
+		return err
+	}
+	for _, t := range trust {
+		if t == "" {
+			continue
+		}
+		if _, err := tx.Exec(ctx, `INSERT INTO agent_supported_trust (chain_id, agent_id, trust_model) VALUES ($1,$2,$3) ON CONFLICT DO NOTHING`, chainID, agentID, t); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM agent_wallets WHERE chain_id=$1 AND agent_id=$2`, chainID, agentID); err != nil {
+		return err
+	}
+	for _, w := range reg.Wallets {
+		if _, err := tx.Exec(ctx, `INSERT INTO agent_wallets (chain_id, agent_id, address) VALUES ($1,$2,$3) ON CONFLICT DO NOTHING`, chainID, agentID, w); err != nil {
+			return err
+		}
+	}
+
+	var changed []string
+	if hadRegistration {
+		if prev.tokenURI != reg.TokenURI {
+			changed = append(changed, "tokenUri")
+		}
+		if !strings.EqualFold(prev.owner, reg.Owner) {
+			changed = append(changed, "owner")
+		}
+		if !prev.sameContent {
+			changed = append(changed, "registration")
+		}
+	}
+	switch {
+	case !existed:
+		err = recordEvent(ctx, tx, EventAgentRegistered, chainID, agentID, map[string]any{"source": EndpointSourceRegistration, "tokenUri": reg.TokenURI})
+	case !hadRegistration:
+		err = recordEvent(ctx, tx, EventAgentUpdated, chainID, agentID, map[string]any{"changed": []string{"registration"}, "tokenUri": reg.TokenURI})
+	case len(changed) > 0:
+		err = recordEvent(ctx, tx, EventAgentUpdated, chainID, agentID, map[string]any{"changed": changed, "tokenUri": reg.TokenURI})
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// replaceEndpoints swaps all endpoints of one source for the given set.
+func replaceEndpoints(ctx context.Context, tx pgx.Tx, chainID string, agentID int64, source string, eps []Endpoint) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM agent_endpoints WHERE chain_id=$1 AND agent_id=$2 AND source=$3`, chainID, agentID, source); err != nil {
+		return err
+	}
+	for _, ep := range eps {
+		if strings.TrimSpace(ep.Name) == "" || strings.TrimSpace(ep.Endpoint) == "" {
+			continue
+		}
+		extra := ep.Extra
+		if extra == nil {
+			extra = map[string]any{}
+		}
+		_, err := tx.Exec(ctx, `
+            INSERT INTO agent_endpoints (chain_id, agent_id, source, name, endpoint, version, extra)
+            VALUES ($1,$2,$3,$4,$5,$6,$7)
+            ON CONFLICT (chain_id, agent_id, source, name, endpoint)
+            DO UPDATE SET version=EXCLUDED.version, extra=EXCLUDED.extra
+        `, chainID, agentID, source, ep.Name, ep.Endpoint, ep.Version, extra)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListEndpoints returns every endpoint recorded for an agent, registration entries first.
+func (s *Postgres) ListEndpoints(ctx context.Context, chainID, agentID string) ([]Endpoint, error) {
+	rows, err := s.db.Query(ctx, `
+        SELECT source, name, endpoint, version, extra
+        FROM agent_endpoints WHERE chain_id=$1 AND agent_id=$2
+        ORDER BY source DESC, name, endpoint
+    `, chainID, agentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []Endpoint{}
+	for rows.Next() {
+		var ep Endpoint
+		var extraBytes []byte
+		if err := rows.Scan(&ep.Source, &ep.Name, &ep.Endpoint, &ep.Version, &extraBytes); err != nil {
+			return nil, err
+		}
+		if len(extraBytes) > 0 {
+			_ = json.Unmarshal(extraBytes, &ep.Extra)
+		}
+		if len(ep.Extra) == 0 {
+			ep.Extra = nil
+		}
+		out = append(out, ep)
+	}
+	return out, rows.Err()
+}
+
+// GetRegistration loads the stored v1 registration file of an agent.
+func (s *Postgres) GetRegistration(ctx context.Context, chainID, agentID string) (Registration, error) {
+	var reg Registration
+	var raw []byte
+	err := s.db.QueryRow(ctx, `
+        SELECT token_uri, owner_address, registration_json, content_verified
+        FROM agent_registrations WHERE chain_id=$1 AND agent_id=$2
+    `, chainID, agentID).Scan(&reg.TokenURI, &reg.Owner, &raw, &reg.Verified)
+	if err != nil {
+		return Registration{}, err
+	}
+	_ = json.Unmarshal(raw, &reg.Raw)
+	reg.Name, _ = reg.Raw["name"].(string)
+	reg.Description, _ = reg.Raw["description"].(string)
+
+	eps, err := s.ListEndpoints(ctx, chainID, agentID)
+	if err != nil {
+		return Registration{}, err
+	}
+	reg.Endpoints = []Endpoint{}
+	for _, ep := range eps {
+		if ep.Source == EndpointSourceRegistration {
+			reg.Endpoints = append(reg.Endpoints, ep)
+		}
+	}
+
+	reg.SupportedTrust, err = s.listStrings(ctx, `SELECT trust_model FROM agent_supported_trust WHERE chain_id=$1 AND agent_id=$2 ORDER BY trust_model`, chainID, agentID)
+	if err != nil {
+		return Registration{}, err
+	}
+	reg.Wallets, err = s.listStrings(ctx, `SELECT address FROM agent_wallets WHERE chain_id=$1 AND agent_id=$2 ORDER BY address`, chainID, agentID)
+	if err != nil {
+		return Registration{}, err
+	}
+	return reg, nil
+}
+
+func (s *Postgres) listStrings(ctx context.Context, sql string, args ...any) ([]string, error) {
+	rows, err := s.db.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []string{}
+	for rows.Next() {
+		var v string
+		if err := rows.Scan(&v); err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, rows.Err()
+}
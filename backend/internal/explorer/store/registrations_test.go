@@ -0,0 +1,59 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
+)
+
+func TestRegistrationDroppingA2AClearsTheCard(t *testing.T) {
+	st := testStore(t)
+	ctx := context.Background()
+	const chain = "eip155:31337"
+	clean := func() { _, _ = st.db.Exec(ctx, `DELETE FROM agents WHERE chain_id = $1`, chain) }
+	clean()
+	t.Cleanup(clean)
+
+	// first indexed from its A2A card
+	card := map[string]any{
+		"name":         "seven",
+		"url":          "https://seven.example/a2a",
+		"skills":       []any{map[string]any{"id": "translate", "name": "Translate", "tags": []any{"language"}}},
+		"capabilities": map[string]any{"streaming": true},
+	}
+	if err := st.UpsertAgentFromCard(ctx, chain, "0x01", 7, "seven.example", card); err != nil {
+		t.Fatal(err)
+	}
+	// then a registration that only declares an MCP server
+	reg := Registration{
+		TokenURI:  "ipfs://seven",
+		Owner:     "0x0000000000000000000000000000000000000007",
+		Endpoints: []Endpoint{{Name: "MCP", Endpoint: "https://seven.example/mcp"}},
+		Raw:       map[string]any{"name": "seven"},
+	}
+	if err := st.UpsertRegistration(ctx, chain, "0x01", 7, "seven.example", reg, true); err != nil {
+		t.Fatal(err)
+	}
+
+	a, err := st.GetAgent(ctx, chain, "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Skills) != 0 || len(a.Capabilities) != 0 {
+		t.Errorf("old card kept: skills %v, capabilities %v", a.Skills, a.Capabilities)
+	}
+	if len(a.Endpoints) != 1 || a.Endpoints[0].Name != "MCP" || a.Endpoints[0].Source != EndpointSourceRegistration {
+		t.Errorf("endpoints = %+v, want only the registration's MCP", a.Endpoints)
+	}
+	for _, f := range []string{"skill:translate", "tag:language", "cap:streaming", "endpoint:a2a"} {
+		x, _ := filter.Parse(f)
+		rows, _, err := st.SearchAgents(ctx, SearchParams{Network: chain, Filters: []filter.Expr{x}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 0 {
+			t.Errorf("%s still finds the agent", f)
+		}
+	}
+}
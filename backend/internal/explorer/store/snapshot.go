@@ -0,0 +1,134 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SnapshotTables are the tables a snapshot carries, parents before children. Probe
+// history and health, embeddings, the event log and admin and webhook state are left
+// out: they are recomputed or belong to one deployment.
+var SnapshotTables = []string{
+	"agents", "agent_events", "agent_registrations", "agent_endpoints", "agent_supported_trust", "agent_wallets",
+	"agent_mcp_servers", "agent_mcp_tools", "agent_mcp_resources", "agent_mcp_prompts",
+	"indexer_checkpoints",
+}
+
+// snapshotConflict is how a restored row meets one already present. Rows the local
+// indexer already has win, except that checkpoints advance to the furthest of both.
+var snapshotConflict = map[string]string{
+	"indexer_checkpoints": `ON CONFLICT (chain_id, registry_addr) DO UPDATE SET
+        last_block = GREATEST(indexer_checkpoints.last_block, EXCLUDED.last_block),
+        agents_backfilled = GREATEST(indexer_checkpoints.agents_backfilled, EXCLUDED.agents_backfilled),
+        updated_at = now()`,
+}
+
+func tableColumns(ctx context.Context, tx pgx.Tx, table string) ([]string, error) {
+	rows, err := tx.Query(ctx, `SELECT column_name FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = $1 ORDER BY ordinal_position`, table)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// DumpSnapshot reads every snapshot table from one consistent, read-only snapshot of
+// the database. table is called with each table's columns before row is called
+// with each of its rows as a JSON object keyed by column.
+func (s *Postgres) DumpSnapshot(ctx context.Context, table func(name string, columns []string) error, row func(data []byte) error) error {
+	tx, err := s.db.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, name := range SnapshotTables {
+		cols, err := tableColumns(ctx, tx, name)
+		if err != nil {
+			return err
+		}
+		if err := table(name, cols); err != nil {
+			return err
+		}
+		rows, err := tx.Query(ctx, `SELECT row_to_json(t)::text FROM `+name+` t`)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var b []byte
+			if err := rows.Scan(&b); err != nil {
+				rows.Close()
+				return err
+			}
+			if err := row(b); err != nil {
+				rows.Close()
+				return err
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RestoreSnapshot runs fn in one transaction, committed only if fn succeeds. fn loads
+// rows with insert, which takes a batch of JSON rows of a snapshot table with the
+// columns they were dumped with. Columns the table no longer has are dropped and
+// ones it gained take their defaults; rows whose key is already present are skipped,
+// so restoring twice changes nothing.
+func (s *Postgres) RestoreSnapshot(ctx context.Context, fn func(insert func(table string, columns []string, rows []json.RawMessage) error) error) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	have := map[string][]string{}
+	insert := func(table string, columns []string, rows []json.RawMessage) error {
+		if !slices.Contains(SnapshotTables, table) {
+			return fmt.Errorf("snapshot table %q is not restorable", table)
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		if _, ok := have[table]; !ok {
+			cols, err := tableColumns(ctx, tx, table)
+			if err != nil {
+				return err
+			}
+			have[table] = cols
+		}
+		var list []string
+		for _, c := range columns {
+			if slices.Contains(have[table], c) {
+				list = append(list, pgx.Identifier{c}.Sanitize())
+			}
+		}
+		if len(list) == 0 {
+			return fmt.Errorf("snapshot table %q shares no columns with the database", table)
+		}
+		conflict, ok := snapshotConflict[table]
+		if !ok {
+			conflict = "ON CONFLICT DO NOTHING"
+		}
+		cols := strings.Join(list, ", ")
+		b, err := json.Marshal(rows)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, fmt.Sprintf(`INSERT INTO %[1]s (%[2]s) SELECT %[2]s FROM json_populate_recordset(NULL::%[1]s, $1::json) %[3]s`,
+			table, cols, conflict), string(b))
+		return err
+	}
+	if err := fn(insert); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/embed"
+)
+
+// Sort keys accepted by SearchAgents.
+const (
+	SortRelevance   = "relevance"
+	SortRecent      = "recent"
+	SortRegistered  = "registered"
+	SortScore       = "score"
+	SortFeedbacks   = "feedbacks"
+	SortValidations = "validations"
+	SortName        = "name"
+	SortAgentID     = "agentId"
+)
+
+// SortKeys lists every sort key, in documentation order.
+var SortKeys = []string{SortRelevance, SortRecent, SortRegistered, SortScore, SortFeedbacks, SortValidations, SortName, SortAgentID}
+
+// Sort is the order of a search. Ties are broken on (chain_id, agent_id) in the
+// same direction, so every order is total and can be paged with a keyset cursor.
+type Sort struct {
+	Key string
+	Asc bool
+}
+
+// ParseSort validates a sort key and order ("asc" or "desc"). An empty key means
+// relevance; an empty order is descending, except for name and agentId which read
+// naturally A to Z.
+func ParseSort(key, order string) (Sort, error) {
+	if key == "" {
+		key = SortRelevance
+	}
+	if _, ok := sortColumns[key]; !ok && key != SortRelevance {
+		return Sort{}, fmt.Errorf("unknown sort %q", key)
+	}
+	s := Sort{Key: key, Asc: key == SortName || key == SortAgentID}
+	switch order {
+	case "":
+	case "asc":
+		s.Asc = true
+	case "desc":
+		s.Asc = false
+	default:
+		return Sort{}, fmt.Errorf("unknown order %q", order)
+	}
+	return s, nil
+}
+
+func (s Sort) String() string {
+	if s.Asc {
+		return s.Key + ":asc"
+	}
+	return s.Key + ":desc"
+}
+
+// sortKind is the Go type a sort column scans into and its cursor value encodes.
+type sortKind int
+
+const (
+	kindTime sortKind = iota
+	kindFloat
+	kindInt
+	kindString
+)
+
+type sortColumn struct {
+	expr string
+	kind sortKind
+}
+
+// sortColumns are the order expressions of each key except relevance, which depends
+// on the query. None may be NULL, or the keyset comparison would drop rows: agents
+// without a score sort below any score, and agents without a card name by domain.
+var sortColumns = map[string]sortColumn{
+	SortRecent:      {`agents.last_seen_at`, kindTime},
+	SortRegistered:  {`agents.registered_at`, kindTime},
+	SortScore:       {`COALESCE(agents.score_avg, -1)::float8`, kindFloat},
+	SortFeedbacks:   {`COALESCE(agents.feedbacks_cnt, 0)::bigint`, kindInt},
+	SortValidations: {`COALESCE(agents.validations_cnt, 0)::bigint`, kindInt},
+	SortName:        {`lower(COALESCE(NULLIF(agents.card_json->>'name', ''), agents.domain))`, kindString},
+	SortAgentID:     {`agents.agent_id`, kindInt},
+}
+
+// resolve returns the sort actually applied: relevance needs a query or semantic
+// text and falls back to recent without either.
+func (s Sort) resolve(p SearchParams) Sort {
+	if s.Key == "" {
+		s.Key = SortRelevance
+	}
+	if s.Key == SortRelevance && strings.TrimSpace(p.Q) == "" && strings.TrimSpace(p.Semantic) == "" {
+		s.Key = SortRecent
+	}
+	return s
+}
+
+// column is the order expression for s, adding any arguments it needs.
+func (s Sort) column(p SearchParams, add func(any) int) sortColumn {
+	if s.Key != SortRelevance {
+		return sortColumns[s.Key]
+	}
+	if sem := strings.TrimSpace(p.Semantic); sem != "" {
+		return sortColumn{similaritySQL(add(embed.Text(sem))), kindFloat}
+	}
+	// exact domain or name matches first, then prefixes, then substrings, then agents
+	// matched only by a skill name
+	q := strings.TrimSpace(p.Q)
+	exact := add(strings.ToLower(q))
+	prefix := add(q + "%")
+	substr := add("%" + q + "%")
+	return sortColumn{fmt.Sprintf(`(CASE
+            WHEN lower(agents.domain) = $%[1]d OR lower(agents.card_json->>'name') = $%[1]d THEN 3
+            WHEN agents.domain ILIKE $%[2]d OR agents.card_json->>'name' ILIKE $%[2]d THEN 2
+            WHEN agents.domain ILIKE $%[3]d OR agents.card_json->>'name' ILIKE $%[3]d THEN 1
+            ELSE 0 END)::float8`, exact, prefix, substr), kindFloat}
+}
+
+// scanTarget is a pointer to scan a column of this kind into; deref reads it back.
+func (k sortKind) scanTarget() any {
+	switch k {
+	case kindTime:
+		return new(time.Time)
+	case kindFloat:
+		return new(float64)
+	case kindInt:
+		return new(int64)
+	}
+	return new(string)
+}
+
+func deref(target any) any {
+	switch v := target.(type) {
+	case *time.Time:
+		return *v
+	case *float64:
+		return *v
+	case *int64:
+		return *v
+	case *string:
+		return *v
+	}
+	return nil
+}
+
+// kind is the type of s's sort values; relevance is a text rank or a similarity.
+func (s Sort) kind() sortKind {
+	if s.Key == SortRelevance {
+		return kindFloat
+	}
+	return sortColumns[s.Key].kind
+}
@@ -0,0 +1,33 @@
+package store
+
+import "testing"
+
+func TestParseSort(t *testing.T) {
+	cases := []struct {
+		key, order string
+		want       string
+		wantErr    bool
+	}{
+		{"", "", "relevance:desc", false},
+		{"recent", "", "recent:desc", false},
+		{"score", "asc", "score:asc", false},
+		{"name", "", "name:asc", false},
+		{"agentId", "", "agentId:asc", false},
+		{"name", "desc", "name:desc", false},
+		{"relevance", "asc", "relevance:asc", false},
+		{"popularity", "", "", true},
+		{"recent", "up", "", true},
+	}
+	for _, tc := range cases {
+		s, err := ParseSort(tc.key, tc.order)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("ParseSort(%q, %q) = %s, want error", tc.key, tc.order, s)
+			}
+			continue
+		}
+		if err != nil || s.String() != tc.want {
+			t.Errorf("ParseSort(%q, %q) = %s, %v, want %s", tc.key, tc.order, s, err, tc.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/praxis/praxis-explorer/internal/explorer/metrics"
+	"github.com/praxis/praxis-explorer/internal/explorer/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/praxis/praxis-explorer/internal/explorer/store")
+
+// queryTracer times every statement and batch for store_query_duration_seconds
+// and wraps it in a "store.<Method>" span, naming the exported Postgres method
+// that issued it, so new methods are measured without instrumenting each one.
+type queryTracer struct{}
+
+type traceKey struct{}
+
+type traceStart struct {
+	method string
+	at     time.Time
+	span   trace.Span
+}
+
+func (queryTracer) start(ctx context.Context, sql string) context.Context {
+	method := callerMethod()
+	ctx, span := tracer.Start(ctx, "store."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.query.text", sql),
+		))
+	return context.WithValue(ctx, traceKey{}, traceStart{method: method, at: time.Now(), span: span})
+}
+
+func (queryTracer) end(ctx context.Context, err error) {
+	if s, ok := ctx.Value(traceKey{}).(traceStart); ok {
+		metrics.StoreDuration.WithLabelValues(s.method).Observe(time.Since(s.at).Seconds())
+		tracing.End(s.span, err)
+	}
+}
+
+func (t queryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return t.start(ctx, data.SQL)
+}
+
+func (t queryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	t.end(ctx, data.Err)
+}
+
+func (t queryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return t.start(ctx, fmt.Sprintf("batch of %d statements", data.Batch.Len()))
+}
+
+func (queryTracer) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+func (t queryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	t.end(ctx, data.Err)
+}
+
+const postgresMethod = "/internal/explorer/store.(*Postgres)."
+
+// callerMethod names the innermost exported Postgres method on the stack, or
+// "other" for statements issued elsewhere.
+func callerMethod() string {
+	var pcs [32]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs[:])])
+	for {
+		f, more := frames.Next()
+		if i := strings.Index(f.Function, postgresMethod); i >= 0 {
+			name := f.Function[i+len(postgresMethod):]
+			// closures inside a method are named Method.func1
+			name, _, _ = strings.Cut(name, ".")
+			if name != "" && name[0] >= 'A' && name[0] <= 'Z' {
+				return name
+			}
+		}
+		if !more {
+			return "other"
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Webhook delivery states.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// WebhookFilter is the subset of SearchParams a single event can be matched on.
+type WebhookFilter struct {
+	Q          string `json:"q,omitempty"`
+	Network    string `json:"network,omitempty"`
+	Skill      string `json:"skill,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	TrustModel string `json:"trustModel,omitempty"`
+}
+
+// SearchParams converts the filter for Event.Matches.
+func (f WebhookFilter) SearchParams() SearchParams {
+	return SearchParams{Q: f.Q, Network: f.Network, Skill: f.Skill, Tag: f.Tag, TrustModel: f.TrustModel}
+}
+
+// WebhookSubscription receives the events matching Filter and Types.
+type WebhookSubscription struct {
+	ID           int64         `json:"id"`
+	URL          string        `json:"url"`
+	Secret       string        `json:"-"`
+	Description  string        `json:"description"`
+	Filter       WebhookFilter `json:"filter"`
+	Types        []string      `json:"types"`
+	Active       bool          `json:"active"`
+	StartEventID int64         `json:"startEventId"`
+	CreatedBy    string        `json:"createdBy,omitempty"`
+	CreatedAt    time.Time     `json:"createdAt"`
+	UpdatedAt    time.Time     `json:"updatedAt"`
+}
+
+// Wants reports whether the subscription should receive e.
+func (w WebhookSubscription) Wants(e Event) bool {
+	if !w.Active || e.ID <= w.StartEventID {
+		return false
+	}
+	if len(w.Types) > 0 {
+		found := false
+		for _, t := range w.Types {
+			found = found || t == e.Type
+		}
+		if !found {
+			return false
+		}
+	}
+	return e.Matches(w.Filter.SearchParams())
+}
+
+// WebhookDelivery is one event queued for one subscription in the outbox.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int64           `json:"subscriptionId"`
+	EventID        int64           `json:"eventId"`
+	EventType      string          `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt"`
+	LastStatus     int             `json:"lastStatus,omitempty"`
+	LastError      string          `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+
+	// set by ClaimWebhookDeliveries
+	URL    string `json:"-"`
+	Secret string `json:"-"`
+}
+
+const webhookColumns = `id, url, secret, description, filter, types, active, start_event_id, created_by, created_at, updated_at`
+
+func scanWebhook(row pgx.Row) (WebhookSubscription, error) {
+	var w WebhookSubscription
+	var filter []byte
+	err := row.Scan(&w.ID, &w.URL, &w.Secret, &w.Description, &filter, &w.Types, &w.Active, &w.StartEventID, &w.CreatedBy, &w.CreatedAt, &w.UpdatedAt)
+	if err == nil {
+		_ = json.Unmarshal(filter, &w.Filter)
+	}
+	return w, err
+}
+
+// CreateWebhook stores a subscription that starts after the newest event logged so far.
+func (s *Postgres) CreateWebhook(ctx context.Context, w WebhookSubscription) (WebhookSubscription, error) {
+	if w.Types == nil {
+		w.Types = []string{}
+	}
+	return scanWebhook(s.db.QueryRow(ctx, `
+        INSERT INTO webhook_subscriptions (url, secret, description, filter, types, active, start_event_id, created_by)
+        VALUES ($1,$2,$3,$4,$5,$6,(SELECT COALESCE(max(id), 0) FROM indexer_events),$7)
+        RETURNING `+webhookColumns, w.URL, w.Secret, w.Description, w.Filter, w.Types, w.Active, w.CreatedBy))
+}
+
+// GetWebhook returns a subscription; pgx.ErrNoRows if there is none.
+func (s *Postgres) GetWebhook(ctx context.Context, id int64) (WebhookSubscription, error) {
+	return scanWebhook(s.db.QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhook_subscriptions WHERE id = $1`, id))
+}
+
+// ListWebhooks returns every subscription, oldest first.
+func (s *Postgres) ListWebhooks(ctx context.Context) ([]WebhookSubscription, error) {
+	rows, err := s.db.Query(ctx, `SELECT `+webhookColumns+` FROM webhook_subscriptions ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []WebhookSubscription{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// UpdateWebhook saves the mutable fields of w (url, description, filter, types,
+// active); pgx.ErrNoRows if it doesn't exist.
+func (s *Postgres) UpdateWebhook(ctx context.Context, w WebhookSubscription) (WebhookSubscription, error) {
+	if w.Types == nil {
+		w.Types = []string{}
+	}
+	return scanWebhook(s.db.QueryRow(ctx, `
+        UPDATE webhook_subscriptions
+        SET url = $2, description = $3, filter = $4, types = $5, active = $6, updated_at = now()
+        WHERE id = $1
+        RETURNING `+webhookColumns, w.ID, w.URL, w.Description, w.Filter, w.Types, w.Active))
+}
+
+// DeleteWebhook removes a subscription and its outbox; it reports false if none existed.
+func (s *Postgres) DeleteWebhook(ctx context.Context, id int64) (bool, error) {
+	tag, err := s.db.Exec(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	return tag.RowsAffected() > 0, err
+}
+
+// WebhookCursor is the id of the last event fanned out into the outbox. On first use
+// it starts at the newest event so history isn't replayed to every subscriber.
+func (s *Postgres) WebhookCursor(ctx context.Context) (int64, error) {
+	var id int64
+	err := s.db.QueryRow(ctx, `
+        INSERT INTO webhook_cursor (last_event_id) SELECT COALESCE(max(id), 0) FROM indexer_events
+        ON CONFLICT (id) DO UPDATE SET last_event_id = webhook_cursor.last_event_id
+        RETURNING last_event_id
+    `).Scan(&id)
+	return id, err
+}
+
+// EnqueueWebhookDeliveries adds deliveries to the outbox and advances the cursor to
+// lastEventID in one transaction, so a crash neither loses nor duplicates events.
+func (s *Postgres) EnqueueWebhookDeliveries(ctx context.Context, lastEventID int64, ds []WebhookDelivery) error {
+	tx, err := s.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+	for _, d := range ds {
+		if _, err := tx.Exec(ctx, `
+            INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+            VALUES ($1,$2,$3,$4) ON CONFLICT (subscription_id, event_id) DO NOTHING
+        `, d.SubscriptionID, d.EventID, d.EventType, d.Payload); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(ctx, `
+        INSERT INTO webhook_cursor (last_event_id) VALUES ($1)
+        ON CONFLICT (id) DO UPDATE SET last_event_id = GREATEST(webhook_cursor.last_event_id, EXCLUDED.last_event_id)
+    `, lastEventID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// ClaimWebhookDeliveries leases up to limit due deliveries of active subscriptions
+// by pushing their next attempt out by lease. Concurrent dispatchers skip each other's
+// claims and a crash mid-attempt ends in a retry, so delivery is at-least-once.
+func (s *Postgres) ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]WebhookDelivery, error) {
+	rows, err := s.db.Query(ctx, `
+        UPDATE webhook_deliveries d
+        SET next_attempt_at = now() + make_interval(secs => $2)
+        FROM webhook_subscriptions w
+        WHERE w.id = d.subscription_id AND d.id IN (
+            SELECT d2.id FROM webhook_deliveries d2
+            JOIN webhook_subscriptions w2 ON w2.id = d2.subscription_id AND w2.active
+            WHERE d2.status = 'pending' AND d2.next_attempt_at <= now()
+            ORDER BY d2.next_attempt_at, d2.id
+            LIMIT $1
+            FOR UPDATE OF d2 SKIP LOCKED
+        )
+        RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.status, d.attempts,
+                  d.next_attempt_at, d.last_status, d.last_error, d.created_at, d.delivered_at, w.url, w.secret
+    `, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastStatus, &d.LastError, &d.CreatedAt, &d.DeliveredAt, &d.URL, &d.Secret); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// CompleteWebhookDelivery marks a delivery as delivered.
+func (s *Postgres) CompleteWebhookDelivery(ctx context.Context, id int64, attempts, status int) error {
+	_, err := s.db.Exec(ctx, `
+        UPDATE webhook_deliveries
+        SET status = 'delivered', attempts = $2, last_status = $3, last_error = '', delivered_at = now()
+        WHERE id = $1
+    `, id, attempts, status)
+	return err
+}
+
+// FailWebhookDelivery records a failed attempt and schedules the next one at next,
+// or moves the delivery to the dead-letter state when next is nil.
+func (s *Postgres) FailWebhookDelivery(ctx context.Context, id int64, attempts, status int, errMsg string, next *time.Time) error {
+	state, at := DeliveryPending, time.Now()
+	if next == nil {
+		state = DeliveryDead
+	} else {
+		at = *next
+	}
+	_, err := s.db.Exec(ctx, `
+        UPDATE webhook_deliveries
+        SET status = $2, attempts = $3, last_status = $4, last_error = $5, next_attempt_at = $6
+        WHERE id = $1
+    `, id, state, attempts, status, errMsg, at)
+	return err
+}
+
+// ListWebhookDeliveries returns a subscription's deliveries, newest first, optionally
+// restricted to one status.
+func (s *Postgres) ListWebhookDeliveries(ctx context.Context, subscriptionID int64, status string, limit int) ([]WebhookDelivery, error) {
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	rows, err := s.db.Query(ctx, `
+        SELECT id, subscription_id, event_id, event_type, payload, status, attempts,
+               next_attempt_at, last_status, last_error, created_at, delivered_at
+        FROM webhook_deliveries
+        WHERE subscription_id = $1 AND ($2 = '' OR status = $2)
+        ORDER BY id DESC LIMIT $3
+    `, subscriptionID, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := []WebhookDelivery{}
+	for rows.Next() {
+		var d WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastStatus, &d.LastError, &d.CreatedAt, &d.DeliveredAt); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// RetryWebhookDelivery puts a dead or delivered delivery back in the queue with a
+// fresh attempt budget; it reports false if the subscription has no such delivery.
+func (s *Postgres) RetryWebhookDelivery(ctx context.Context, subscriptionID, id int64) (bool, error) {
+	tag, err := s.db.Exec(ctx, `
+        UPDATE webhook_deliveries
+        SET status = 'pending', attempts = 0, next_attempt_at = now(), delivered_at = NULL
+        WHERE id = $1 AND subscription_id = $2
+    `, id, subscriptionID)
+	return tag.RowsAffected() > 0, err
+}
+
+// PruneWebhookDeliveries deletes delivered and dead deliveries older than olderThan.
+func (s *Postgres) PruneWebhookDeliveries(ctx context.Context, olderThan time.Duration) error {
+	_, err := s.db.Exec(ctx, `
+        DELETE FROM webhook_deliveries
+        WHERE status <> 'pending' AND created_at < now() - make_interval(secs => $1)
+    `, olderThan.Seconds())
+	return err
+}
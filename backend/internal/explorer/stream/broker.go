@@ -0,0 +1,195 @@
+// Package stream pushes indexer events to SSE and WebSocket clients. Events come
+// from the indexer_events log, which the store appends to in the same transaction
+// as each change, so a client resuming with Last-Event-ID misses nothing that is
+// still within retention.
+package stream
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// Source is the part of *store.Postgres the broker reads from.
+type Source interface {
+	ListEventsAfter(ctx context.Context, afterID int64, limit int) ([]store.Event, error)
+	LatestEventID(ctx context.Context) (int64, error)
+}
+
+// Filter selects events for one subscriber. Types, when set, restricts event types.
+type Filter struct {
+	Search store.SearchParams
+	Types  map[string]bool
+}
+
+func (f Filter) match(e store.Event) bool {
+	if len(f.Types) > 0 && !f.Types[e.Type] {
+		return false
+	}
+	return e.Matches(f.Search)
+}
+
+// subscriberBuffer is how many events a slow client may lag before it is dropped.
+const subscriberBuffer = 256
+
+// Subscription delivers matching events in id order. C is closed when the
+// subscriber falls too far behind; the client then reconnects with Last-Event-ID.
+type Subscription struct {
+	C      <-chan store.Event
+	ch     chan store.Event
+	filter Filter
+	b      *Broker
+	closed bool
+}
+
+// gapWait is how long the broker holds back events behind a missing id. Ids are
+// assigned when a transaction inserts its event but become visible when it commits,
+// so a gap usually closes within moments; one left by a rolled-back transaction
+// never does and is skipped after this long.
+const gapWait = 5 * time.Second
+
+// Broker polls the event log and fans new events out to subscribers.
+type Broker struct {
+	src  Source
+	poll time.Duration
+	now  func() time.Time
+
+	once     sync.Once
+	mu       sync.Mutex
+	last     int64
+	gapSince time.Time
+	subs     map[*Subscription]struct{}
+}
+
+// NewBroker creates a broker; it starts polling on the first Subscribe.
+func NewBroker(src Source, poll time.Duration) *Broker {
+	if poll <= 0 {
+		poll = time.Second
+	}
+	return &Broker{src: src, poll: poll, now: time.Now, subs: map[*Subscription]struct{}{}}
+}
+
+// Subscribe registers a subscriber for live events. Callers replay history with
+// Replay before reading C so nothing between afterID and now is lost.
+func (b *Broker) Subscribe(f Filter) *Subscription {
+	b.once.Do(func() { b.start(context.Background()) })
+	ch := make(chan store.Event, subscriberBuffer)
+	s := &Subscription{C: ch, ch: ch, filter: f, b: b}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Close unregisters the subscription.
+func (s *Subscription) Close() {
+	s.b.mu.Lock()
+	defer s.b.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		delete(s.b.subs, s)
+		close(s.ch)
+	}
+}
+
+// Replay calls fn for every stored event after afterID that matches f, oldest
+// first, up to the newest event at the time of the call. It returns the id of the
+// last event examined so live delivery can skip anything already replayed.
+func (b *Broker) Replay(ctx context.Context, f Filter, afterID int64, fn func(store.Event) error) (int64, error) {
+	for {
+		events, err := b.src.ListEventsAfter(ctx, afterID, 500)
+		if err != nil {
+			return afterID, err
+		}
+		for _, e := range events {
+			afterID = e.ID
+			if f.match(e) {
+				if err := fn(e); err != nil {
+					return afterID, err
+				}
+			}
+		}
+		if len(events) < 500 {
+			return afterID, nil
+		}
+	}
+}
+
+// start reads the log position before returning, so the first subscriber's replay
+// and live delivery meet without a hole, then polls in the background.
+func (b *Broker) start(ctx context.Context) {
+	last, err := b.src.LatestEventID(ctx)
+	if err != nil {
+		log.WithError(err).Warn("[stream] failed reading latest event id; streaming from the start of the log")
+	}
+	b.mu.Lock()
+	b.last = last
+	b.mu.Unlock()
+	go b.run(ctx)
+}
+
+func (b *Broker) run(ctx context.Context) {
+	t := time.NewTicker(b.poll)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			b.pollOnce(ctx)
+		}
+	}
+}
+
+func (b *Broker) pollOnce(ctx context.Context) {
+	for {
+		b.mu.Lock()
+		after := b.last
+		b.mu.Unlock()
+		events, err := b.src.ListEventsAfter(ctx, after, 500)
+		if err != nil {
+			log.WithError(err).Warn("[stream] failed polling events")
+			return
+		}
+		if b.publish(events) < 500 {
+			return
+		}
+	}
+}
+
+// publish hands events to every matching subscriber, dropping subscribers whose
+// buffer is full rather than blocking the rest. It stops at an id gap younger than
+// gapWait and returns how many events were consumed.
+func (b *Broker) publish(events []store.Event) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i, e := range events {
+		if e.ID > b.last+1 && b.last > 0 {
+			if b.gapSince.IsZero() {
+				b.gapSince = b.now()
+			}
+			if b.now().Sub(b.gapSince) < gapWait {
+				return i
+			}
+		}
+		b.gapSince = time.Time{}
+		b.last = e.ID
+		for s := range b.subs {
+			if !s.filter.match(e) {
+				continue
+			}
+			select {
+			case s.ch <- e:
+			default:
+				log.WithField("lastEventId", e.ID).Warn("[stream] dropping slow subscriber")
+				s.closed = true
+				delete(b.subs, s)
+				close(s.ch)
+			}
+		}
+	}
+	return len(events)
+}
@@ -0,0 +1,207 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/praxis/praxis-explorer/internal/explorer/filter"
+	"github.com/praxis/praxis-explorer/internal/explorer/problem"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// Handler serves the event stream over SSE and WebSocket.
+type Handler struct {
+	b         *Broker
+	heartbeat time.Duration
+	upgrader  websocket.Upgrader
+}
+
+// NewHandler serves events from b, sending a keepalive every heartbeat.
+func NewHandler(b *Broker, heartbeat time.Duration) *Handler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &Handler{
+		b:         b,
+		heartbeat: heartbeat,
+		// the API is public and read-only here, as with CORS
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+	}
+}
+
+// request parses filters (chain, skill, tag, trustModel, types, filter) and the resume
+// position: the Last-Event-ID header, or lastEventId for clients that can't set it.
+func request(c *gin.Context) (Filter, int64, bool, error) {
+	f := Filter{Search: store.SearchParams{
+		Network:    c.Query("chain"),
+		Skill:      c.Query("skill"),
+		Tag:        c.Query("tag"),
+		TrustModel: c.Query("trustModel"),
+	}}
+	if v := c.Query("types"); v != "" {
+		f.Types = map[string]bool{}
+		for _, t := range strings.Split(v, ",") {
+			t = strings.TrimSpace(t)
+			known := false
+			for _, k := range store.EventTypes {
+				known = known || k == t
+			}
+			if !known {
+				return Filter{}, 0, false, fmt.Errorf("unknown event type %q", t)
+			}
+			f.Types[t] = true
+		}
+	}
+	x, err := filter.Parse(c.Query("filter"))
+	if err != nil {
+		return Filter{}, 0, false, err
+	}
+	if x != nil {
+		for _, field := range filter.FieldsOf(x) {
+			if !slices.Contains(store.EventFields, field) {
+				return Filter{}, 0, false, fmt.Errorf("filter: events can't be filtered on %q", field)
+			}
+		}
+		f.Search.Filters = []filter.Expr{x}
+	}
+	last := c.GetHeader("Last-Event-ID")
+	if last == "" {
+		last = c.Query("lastEventId")
+	}
+	if last == "" {
+		return f, 0, false, nil
+	}
+	id, err := strconv.ParseInt(last, 10, 64)
+	if err != nil || id < 0 {
+		return Filter{}, 0, false, fmt.Errorf("invalid Last-Event-ID %q", last)
+	}
+	return f, id, true, nil
+}
+
+// SSE streams events as text/event-stream, each with its log id as the SSE id.
+func (h *Handler) SSE(c *gin.Context) {
+	f, after, resume, err := request(c)
+	if err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	sub := h.b.Subscribe(f)
+	defer sub.Close()
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, "retry: 3000\n\n")
+	w.Flush()
+
+	h.serve(c.Request.Context(), sub, f, after, resume,
+		func(e store.Event) error {
+			if err := writeSSE(w, e); err != nil {
+				return err
+			}
+			w.Flush()
+			return nil
+		},
+		func() error {
+			_, err := io.WriteString(w, ": keepalive\n\n")
+			w.Flush()
+			return err
+		})
+}
+
+func writeSSE(w io.Writer, e store.Event) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, b)
+	return err
+}
+
+// WebSocket streams events as JSON text messages. The stream is one-way; client
+// messages are read only to notice the connection closing.
+func (h *Handler) WebSocket(c *gin.Context) {
+	f, after, resume, err := request(c)
+	if err != nil {
+		problem.Write(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		return // the upgrader already replied
+	}
+	defer conn.Close()
+	sub := h.b.Subscribe(f)
+	defer sub.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	h.serve(ctx, sub, f, after, resume,
+		func(e store.Event) error {
+			_ = conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+			return conn.WriteJSON(e)
+		},
+		func() error {
+			return conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second))
+		})
+}
+
+// serve replays history when resuming, then forwards live events until the client
+// goes away or falls behind.
+func (h *Handler) serve(ctx context.Context, sub *Subscription, f Filter, after int64, resume bool, send func(store.Event) error, keepalive func() error) {
+	var sent int64
+	if resume {
+		var err error
+		sent, err = h.b.Replay(ctx, f, after, send)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.WithError(err).Warn("[stream] replay failed")
+			}
+			return
+		}
+	}
+	t := time.NewTicker(h.heartbeat)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+			if keepalive() != nil {
+				return
+			}
+		case e, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if e.ID <= sent {
+				continue
+			}
+			if send(e) != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,221 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// fakeSource is an in-memory event log.
+type fakeSource struct {
+	mu     sync.Mutex
+	events []store.Event
+}
+
+func (f *fakeSource) add(e store.Event) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.events = append(f.events, e)
+}
+
+func (f *fakeSource) ListEventsAfter(_ context.Context, afterID int64, limit int) ([]store.Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []store.Event
+	for _, e := range f.events {
+		if e.ID > afterID && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeSource) LatestEventID(context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.events) == 0 {
+		return 0, nil
+	}
+	return f.events[len(f.events)-1].ID, nil
+}
+
+func event(id int64, typ, chain string) store.Event {
+	return store.Event{
+		ID: id, Type: typ, ChainID: chain, AgentID: id,
+		Agent: store.EventAgent{
+			TrustModels: []string{"feedback"},
+			Skills:      []map[string]any{{"id": "search", "name": "Web Search", "tags": []any{"web"}}},
+		},
+	}
+}
+
+func TestFilter(t *testing.T) {
+	e := event(1, store.EventCardChanged, "eip155:11155111")
+	cases := []struct {
+		f    Filter
+		want bool
+	}{
+		{Filter{}, true},
+		{Filter{Search: store.SearchParams{Network: "Sepolia"}}, true},
+		{Filter{Search: store.SearchParams{Network: "base-sepolia"}}, false},
+		{Filter{Search: store.SearchParams{Skill: "web"}}, true},
+		{Filter{Search: store.SearchParams{Skill: "translate"}}, false},
+		{Filter{Search: store.SearchParams{Tag: "WEB"}}, true},
+		{Filter{Search: store.SearchParams{Tag: "we"}}, false},
+		{Filter{Search: store.SearchParams{TrustModel: "feedback"}}, true},
+		{Filter{Search: store.SearchParams{TrustModel: "tee-attestation"}}, false},
+		{Filter{Types: map[string]bool{store.EventCardChanged: true}}, true},
+		{Filter{Types: map[string]bool{store.EventAgentDeleted: true}}, false},
+	}
+	for i, c := range cases {
+		if got := c.f.match(e); got != c.want {
+			t.Errorf("case %d: match = %v, want %v", i, got, c.want)
+		}
+	}
+}
+
+func TestReplayFiltersAndPages(t *testing.T) {
+	src := &fakeSource{}
+	for i := int64(1); i <= 1200; i++ {
+		chain := "eip155:11155111"
+		if i%2 == 0 {
+			chain = "eip155:84532"
+		}
+		src.add(event(i, store.EventAgentUpdated, chain))
+	}
+	b := NewBroker(src, time.Hour)
+	var got []int64
+	last, err := b.Replay(context.Background(), Filter{Search: store.SearchParams{Network: "sepolia"}}, 100, func(e store.Event) error {
+		got = append(got, e.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last != 1200 {
+		t.Errorf("last = %d, want 1200", last)
+	}
+	if len(got) != 550 || got[0] != 101 || got[len(got)-1] != 1199 {
+		t.Errorf("replayed %d events (%v..%v), want 550 odd ids from 101 to 1199", len(got), got[0], got[len(got)-1])
+	}
+}
+
+func TestPublishHoldsBackGaps(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewBroker(&fakeSource{}, time.Hour)
+	b.now = func() time.Time { return now }
+	b.last = 1
+	sub := &Subscription{ch: make(chan store.Event, 8), b: b}
+	b.subs[sub] = struct{}{}
+
+	// 3 is visible but 2 isn't committed yet
+	if n := b.publish([]store.Event{event(3, store.EventAgentUpdated, "eip155:11155111")}); n != 0 {
+		t.Fatalf("published %d events across a fresh gap", n)
+	}
+	// 2 commits: both go out in order
+	if n := b.publish([]store.Event{event(2, store.EventAgentUpdated, "eip155:11155111"), event(3, store.EventAgentUpdated, "eip155:11155111")}); n != 2 {
+		t.Fatalf("published %d, want 2", n)
+	}
+	if a, c := <-sub.ch, <-sub.ch; a.ID != 2 || c.ID != 3 {
+		t.Fatalf("got ids %d, %d", a.ID, c.ID)
+	}
+	// 4 rolled back: 5 waits out gapWait and is then delivered
+	if n := b.publish([]store.Event{event(5, store.EventAgentUpdated, "eip155:11155111")}); n != 0 {
+		t.Fatalf("published %d events across a fresh gap", n)
+	}
+	now = now.Add(gapWait)
+	if n := b.publish([]store.Event{event(5, store.EventAgentUpdated, "eip155:11155111")}); n != 1 {
+		t.Fatalf("published %d after gapWait, want 1", n)
+	}
+	if e := <-sub.ch; e.ID != 5 {
+		t.Fatalf("got id %d, want 5", e.ID)
+	}
+}
+
+func TestPublishDropsSlowSubscribers(t *testing.T) {
+	b := NewBroker(&fakeSource{}, time.Hour)
+	slow := &Subscription{ch: make(chan store.Event, 1), b: b}
+	fast := &Subscription{ch: make(chan store.Event, 8), b: b}
+	b.subs[slow] = struct{}{}
+	b.subs[fast] = struct{}{}
+
+	b.publish([]store.Event{event(1, store.EventAgentUpdated, "eip155:11155111"), event(2, store.EventAgentUpdated, "eip155:11155111")})
+	if _, ok := b.subs[slow]; ok {
+		t.Fatal("slow subscriber still registered")
+	}
+	<-slow.ch
+	if _, ok := <-slow.ch; ok {
+		t.Fatal("slow subscriber channel not closed")
+	}
+	if len(fast.ch) != 2 {
+		t.Fatalf("fast subscriber got %d events, want 2", len(fast.ch))
+	}
+	slow.Close() // closing again is a no-op
+}
+
+func TestSSEResumeAndLive(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	src := &fakeSource{}
+	src.add(event(1, store.EventAgentRegistered, "eip155:11155111"))
+	src.add(event(2, store.EventAgentRegistered, "eip155:84532"))
+	src.add(event(3, store.EventCardChanged, "eip155:11155111"))
+
+	r := gin.New()
+	h := NewHandler(NewBroker(src, 10*time.Millisecond), time.Hour)
+	r.GET("/stream/agents", h.SSE)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/stream/agents?chain=sepolia", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type %q", ct)
+	}
+
+	var ids []string
+	sc := bufio.NewScanner(res.Body)
+	for sc.Scan() && len(ids) < 2 {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "id: ") {
+			continue
+		}
+		ids = append(ids, strings.TrimPrefix(line, "id: "))
+		if len(ids) == 1 {
+			// replay is done; these arrive live and only the sepolia one matches
+			src.add(event(4, store.EventAgentDeleted, "eip155:84532"))
+			src.add(event(5, store.EventStatusChanged, "eip155:11155111"))
+		}
+	}
+	if strings.Join(ids, ",") != "3,5" {
+		t.Fatalf("ids = %v, want [3 5]", ids)
+	}
+}
+
+func TestSSERejectsBadParams(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	h := NewHandler(NewBroker(&fakeSource{}, time.Hour), time.Hour)
+	r.GET("/stream/agents", h.SSE)
+	for _, q := range []string{"types=agent.exploded", "lastEventId=abc"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stream/agents?"+q, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400", q, w.Code)
+		}
+	}
+}
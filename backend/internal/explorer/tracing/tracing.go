@@ -0,0 +1,140 @@
+// Package tracing configures OpenTelemetry for the explorer. Spans are created
+// through the global tracer provider, so instrumented code only needs
+// otel.Tracer; Setup decides where they go.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Exporters accepted in EXPLORER_TRACE_EXPORTER.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+// Config selects the exporter and sampling.
+type Config struct {
+	// Exporter is none, stdout, file or otlp. otlp is configured by the standard
+	// OTEL_EXPORTER_OTLP_* variables (endpoint, headers, TLS).
+	Exporter string
+	// File receives JSON spans, one per line, with the file exporter.
+	File string
+	// SampleRatio is the fraction of new traces recorded; spans whose parent
+	// was sampled are always recorded.
+	SampleRatio float64
+}
+
+// ConfigFromEnv reads EXPLORER_TRACE_EXPORTER (default none), EXPLORER_TRACE_FILE
+// (default praxis-traces.json) and EXPLORER_TRACE_SAMPLE_RATIO (default 1).
+func ConfigFromEnv() Config {
+	c := Config{Exporter: ExporterNone, File: "praxis-traces.json", SampleRatio: 1}
+	if v := os.Getenv("EXPLORER_TRACE_EXPORTER"); v != "" {
+		c.Exporter = v
+	}
+	if v := os.Getenv("EXPLORER_TRACE_FILE"); v != "" {
+		c.File = v
+	}
+	if v := os.Getenv("EXPLORER_TRACE_SAMPLE_RATIO"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 1 {
+			c.SampleRatio = f
+		} else {
+			log.WithField("value", v).Warn("invalid EXPLORER_TRACE_SAMPLE_RATIO; using default")
+		}
+	}
+	return c
+}
+
+// Setup installs the global tracer provider and W3C trace-context propagator.
+// The returned function flushes pending spans and must be called on shutdown.
+// With the none exporter spans are no-ops, but a trace context received by the
+// API is still passed on to outbound fetches.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exp sdktrace.SpanExporter
+	var closer io.Closer
+	switch cfg.Exporter {
+	case ExporterNone, "":
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		e, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout))
+		if err != nil {
+			return nil, err
+		}
+		exp = e
+	case ExporterFile:
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("trace file: %w", err)
+		}
+		e, err := stdouttrace.New(stdouttrace.WithWriter(f))
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		exp, closer = e, f
+	case ExporterOTLP:
+		e, err := otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+		exp = e
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q (want none, stdout, file or otlp)", cfg.Exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL,
+		semconv.ServiceName("praxis-explorer")))
+	if err != nil {
+		return nil, err
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults
+	if env, err := resource.New(ctx, resource.WithFromEnv()); err == nil {
+		if merged, err := resource.Merge(res, env); err == nil {
+			res = merged
+		}
+	}
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exp),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closer != nil {
+			if cerr := closer.Close(); err == nil {
+				err = cerr
+			}
+		}
+		return err
+	}, nil
+}
+
+// End records err on span, if any, and ends it. It is meant for
+// `defer func() { tracing.End(span, err) }()` with a named error result.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,53 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+)
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), Config{Exporter: ExporterFile, File: path, SampleRatio: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "indexer.handleIdentityLog")
+	_, child := otel.Tracer("test").Start(ctx, "indexer.fetchAndStoreCard")
+	End(child, errors.New("card decode: unexpected EOF"))
+	End(parent, nil)
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+	for _, want := range []string{"indexer.handleIdentityLog", "indexer.fetchAndStoreCard", "card decode: unexpected EOF", "praxis-explorer"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("trace file lacks %q", want)
+		}
+	}
+}
+
+func TestUnknownExporter(t *testing.T) {
+	if _, err := Setup(context.Background(), Config{Exporter: "zipkin"}); err == nil {
+		t.Error("unknown exporter accepted")
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("EXPLORER_TRACE_EXPORTER", "otlp")
+	t.Setenv("EXPLORER_TRACE_SAMPLE_RATIO", "2")
+	c := ConfigFromEnv()
+	if c.Exporter != ExporterOTLP || c.SampleRatio != 1 || c.File == "" {
+		t.Errorf("config = %+v", c)
+	}
+}
@@ -0,0 +1,369 @@
+// Package webhook delivers indexer events to operator-registered HTTP endpoints.
+//
+// The dispatcher fans new rows of indexer_events out into the webhook_deliveries
+// outbox, one row per matching subscription, advancing a persisted cursor in the same
+// transaction. Due deliveries are then POSTed with an HMAC signature and retried with
+// exponential backoff until they succeed or run out of attempts and go dead.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+	log "github.com/sirupsen/logrus"
+)
+
+// Request headers sent with every delivery.
+const (
+	HeaderSignature = "X-Praxis-Signature"
+	HeaderEvent     = "X-Praxis-Event"
+	HeaderDelivery  = "X-Praxis-Delivery"
+)
+
+// Store is the part of *store.Postgres the dispatcher uses.
+type Store interface {
+	ListEventsAfter(ctx context.Context, afterID int64, limit int) ([]store.Event, error)
+	ListWebhooks(ctx context.Context) ([]store.WebhookSubscription, error)
+	WebhookCursor(ctx context.Context) (int64, error)
+	EnqueueWebhookDeliveries(ctx context.Context, lastEventID int64, ds []store.WebhookDelivery) error
+	ClaimWebhookDeliveries(ctx context.Context, limit int, lease time.Duration) ([]store.WebhookDelivery, error)
+	CompleteWebhookDelivery(ctx context.Context, id int64, attempts, status int) error
+	FailWebhookDelivery(ctx context.Context, id int64, attempts, status int, errMsg string, next *time.Time) error
+	PruneWebhookDeliveries(ctx context.Context, olderThan time.Duration) error
+}
+
+// Config controls delivery. Attempt n (1-based) that fails is retried after
+// BaseBackoff * 2^(n-1), capped at MaxBackoff; after MaxAttempts it goes dead.
+type Config struct {
+	Poll        time.Duration
+	Timeout     time.Duration
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Concurrency int
+	Retention   time.Duration // delivered and dead rows older than this are pruned
+}
+
+// DefaultConfig spreads 12 attempts over about 14 hours before giving up.
+func DefaultConfig() Config {
+	return Config{
+		Poll:        2 * time.Second,
+		Timeout:     10 * time.Second,
+		MaxAttempts: 12,
+		BaseBackoff: 30 * time.Second,
+		MaxBackoff:  6 * time.Hour,
+		Concurrency: 8,
+		Retention:   30 * 24 * time.Hour,
+	}
+}
+
+// ConfigFromEnv reads EXPLORER_WEBHOOK_MAX_ATTEMPTS and EXPLORER_WEBHOOK_TIMEOUT over
+// the defaults.
+func ConfigFromEnv() Config {
+	cfg := DefaultConfig()
+	if v := os.Getenv("EXPLORER_WEBHOOK_MAX_ATTEMPTS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			cfg.MaxAttempts = n
+		} else {
+			log.WithField("value", v).Warn("invalid EXPLORER_WEBHOOK_MAX_ATTEMPTS; using default")
+		}
+	}
+	if v := os.Getenv("EXPLORER_WEBHOOK_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.Timeout = d
+		} else {
+			log.WithField("value", v).Warn("invalid EXPLORER_WEBHOOK_TIMEOUT; using default")
+		}
+	}
+	return cfg
+}
+
+// backoff is the delay after the given number of failed attempts.
+func (c Config) backoff(attempts int) time.Duration {
+	d := c.BaseBackoff
+	for i := 1; i < attempts && d < c.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > c.MaxBackoff {
+		d = c.MaxBackoff
+	}
+	return d
+}
+
+// GenerateSecret returns a new random signing secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// Sign returns the X-Praxis-Signature value for body sent at t:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<unix seconds>.<body>">".
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + mac(secret, ts, body)
+}
+
+func mac(secret, ts string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write([]byte(ts))
+	m.Write([]byte("."))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Verify checks a signature header against body, rejecting timestamps further than
+// tolerance from now. Receivers in Go can use it as-is.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var ts string
+	var sigs []string
+	for _, part := range strings.Split(header, ",") {
+		k, v, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch k {
+		case "t":
+			ts = v
+		case "v1":
+			sigs = append(sigs, v)
+		}
+	}
+	sec, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil || len(sigs) == 0 {
+		return errors.New("malformed signature header")
+	}
+	if d := now.Sub(time.Unix(sec, 0)); d > tolerance || d < -tolerance {
+		return errors.New("signature timestamp outside tolerance")
+	}
+	want := mac(secret, ts, body)
+	for _, s := range sigs {
+		if hmac.Equal([]byte(s), []byte(want)) {
+			return nil
+		}
+	}
+	return errors.New("signature mismatch")
+}
+
+// gapWait is how long fan-out waits at a missing event id; see stream.gapWait.
+const gapWait = 5 * time.Second
+
+// Dispatcher moves events into the outbox and delivers them.
+type Dispatcher struct {
+	st     Store
+	cfg    Config
+	client *http.Client
+	now    func() time.Time
+
+	gapSince  time.Time
+	lastPrune time.Time
+}
+
+// New creates a dispatcher; zero fields of cfg take their defaults.
+func New(st Store, cfg Config) *Dispatcher {
+	def := DefaultConfig()
+	if cfg.Poll <= 0 {
+		cfg.Poll = def.Poll
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = def.Timeout
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = def.MaxAttempts
+	}
+	if cfg.BaseBackoff <= 0 {
+		cfg.BaseBackoff = def.BaseBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = def.MaxBackoff
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = def.Concurrency
+	}
+	return &Dispatcher{
+		st:  st,
+		cfg: cfg,
+		// receivers must answer directly; following redirects would re-send signed
+		// payloads to hosts the operator never registered
+		client: &http.Client{
+			Timeout:       cfg.Timeout,
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		now: time.Now,
+	}
+}
+
+// Run fans out and delivers until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	log.Info("[webhooks] dispatcher started")
+	t := time.NewTicker(d.cfg.Poll)
+	defer t.Stop()
+	for {
+		d.tick(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+func (d *Dispatcher) tick(ctx context.Context) {
+	if err := d.fanOut(ctx); err != nil {
+		log.WithError(err).Warn("[webhooks] fan-out failed")
+	}
+	for {
+		n, err := d.deliverDue(ctx)
+		if err != nil {
+			log.WithError(err).Warn("[webhooks] claiming deliveries failed")
+		}
+		if n == 0 || err != nil || ctx.Err() != nil {
+			break
+		}
+	}
+	if d.cfg.Retention > 0 && d.now().Sub(d.lastPrune) > time.Hour {
+		d.lastPrune = d.now()
+		if err := d.st.PruneWebhookDeliveries(ctx, d.cfg.Retention); err != nil {
+			log.WithError(err).Warn("[webhooks] pruning deliveries failed")
+		}
+	}
+}
+
+// fanOut queues every new event for each subscription that wants it. It stops at an
+// id gap younger than gapWait, since the missing event may still be committing.
+func (d *Dispatcher) fanOut(ctx context.Context) error {
+	cursor, err := d.st.WebhookCursor(ctx)
+	if err != nil {
+		return err
+	}
+	for {
+		events, err := d.st.ListEventsAfter(ctx, cursor, 500)
+		if err != nil || len(events) == 0 {
+			return err
+		}
+		subs, err := d.st.ListWebhooks(ctx)
+		if err != nil {
+			return err
+		}
+		var ds []store.WebhookDelivery
+		last, held := cursor, false
+		for _, e := range events {
+			if e.ID > last+1 {
+				if d.gapSince.IsZero() {
+					d.gapSince = d.now()
+				}
+				if d.now().Sub(d.gapSince) < gapWait {
+					held = true
+					break
+				}
+			}
+			d.gapSince = time.Time{}
+			last = e.ID
+			var payload []byte
+			for _, s := range subs {
+				if !s.Wants(e) {
+					continue
+				}
+				if payload == nil {
+					if payload, err = json.Marshal(e); err != nil {
+						return err
+					}
+				}
+				ds = append(ds, store.WebhookDelivery{SubscriptionID: s.ID, EventID: e.ID, EventType: e.Type, Payload: payload})
+			}
+		}
+		if last == cursor {
+			return nil
+		}
+		if err := d.st.EnqueueWebhookDeliveries(ctx, last, ds); err != nil {
+			return err
+		}
+		cursor = last
+		if held || len(events) < 500 {
+			return nil
+		}
+	}
+}
+
+// deliverDue claims a batch of due deliveries and attempts them concurrently. It
+// returns how many were claimed.
+func (d *Dispatcher) deliverDue(ctx context.Context) (int, error) {
+	batch, err := d.st.ClaimWebhookDeliveries(ctx, d.cfg.Concurrency*4, d.cfg.Timeout*2)
+	if err != nil {
+		return 0, err
+	}
+	sem := make(chan struct{}, d.cfg.Concurrency)
+	var wg sync.WaitGroup
+	for _, del := range batch {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(del store.WebhookDelivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			d.attempt(ctx, del)
+		}(del)
+	}
+	wg.Wait()
+	return len(batch), nil
+}
+
+// attempt POSTs one delivery and records the outcome. 2xx is success; 410 Gone
+// dead-letters immediately since the receiver asked not to be called again.
+func (d *Dispatcher) attempt(ctx context.Context, del store.WebhookDelivery) {
+	attempts := del.Attempts + 1
+	status, err := d.post(ctx, del)
+	l := log.WithFields(log.Fields{"delivery": del.ID, "subscription": del.SubscriptionID, "attempt": attempts})
+	if err == nil {
+		if err := d.st.CompleteWebhookDelivery(ctx, del.ID, attempts, status); err != nil {
+			l.WithError(err).Warn("[webhooks] failed recording delivery")
+		}
+		return
+	}
+	var next *time.Time
+	if attempts < d.cfg.MaxAttempts && status != http.StatusGone {
+		at := d.now().Add(d.cfg.backoff(attempts))
+		next = &at
+		l.WithError(err).WithField("retryAt", at).Info("[webhooks] delivery failed")
+	} else {
+		l.WithError(err).Warn("[webhooks] delivery dead-lettered")
+	}
+	if err := d.st.FailWebhookDelivery(ctx, del.ID, attempts, status, err.Error(), next); err != nil {
+		l.WithError(err).Warn("[webhooks] failed recording delivery failure")
+	}
+}
+
+func (d *Dispatcher) post(ctx context.Context, del store.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, del.URL, bytes.NewReader(del.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "praxis-explorer-webhooks/1")
+	req.Header.Set(HeaderEvent, del.EventType)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(del.ID, 10))
+	req.Header.Set(HeaderSignature, Sign(del.Secret, d.now(), del.Payload))
+	res, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("receiver answered %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
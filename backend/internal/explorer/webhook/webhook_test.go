@@ -0,0 +1,294 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/praxis/praxis-explorer/internal/explorer/store"
+)
+
+// fakeStore keeps the event log, subscriptions and outbox in memory.
+type fakeStore struct {
+	mu         sync.Mutex
+	events     []store.Event
+	subs       []store.WebhookSubscription
+	cursor     int64
+	deliveries []*store.WebhookDelivery
+	now        func() time.Time
+}
+
+func (f *fakeStore) ListEventsAfter(_ context.Context, afterID int64, limit int) ([]store.Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []store.Event
+	for _, e := range f.events {
+		if e.ID > afterID && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStore) ListWebhooks(context.Context) ([]store.WebhookSubscription, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]store.WebhookSubscription(nil), f.subs...), nil
+}
+
+func (f *fakeStore) WebhookCursor(context.Context) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cursor, nil
+}
+
+func (f *fakeStore) EnqueueWebhookDeliveries(_ context.Context, last int64, ds []store.WebhookDelivery) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, d := range ds {
+		d := d
+		d.ID = int64(len(f.deliveries) + 1)
+		d.Status = store.DeliveryPending
+		d.NextAttemptAt = f.now()
+		f.deliveries = append(f.deliveries, &d)
+	}
+	f.cursor = last
+	return nil
+}
+
+func (f *fakeStore) ClaimWebhookDeliveries(_ context.Context, limit int, lease time.Duration) ([]store.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var out []store.WebhookDelivery
+	for _, d := range f.deliveries {
+		if d.Status != store.DeliveryPending || d.NextAttemptAt.After(f.now()) || len(out) == limit {
+			continue
+		}
+		for _, s := range f.subs {
+			if s.ID == d.SubscriptionID && s.Active {
+				d.NextAttemptAt = f.now().Add(lease)
+				c := *d
+				c.URL, c.Secret = s.URL, s.Secret
+				out = append(out, c)
+			}
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStore) CompleteWebhookDelivery(_ context.Context, id int64, attempts, status int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d := f.deliveries[id-1]
+	d.Status, d.Attempts, d.LastStatus = store.DeliveryDelivered, attempts, status
+	return nil
+}
+
+func (f *fakeStore) FailWebhookDelivery(_ context.Context, id int64, attempts, status int, errMsg string, next *time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	d := f.deliveries[id-1]
+	d.Attempts, d.LastStatus, d.LastError = attempts, status, errMsg
+	if next == nil {
+		d.Status = store.DeliveryDead
+	} else {
+		d.NextAttemptAt = *next
+	}
+	return nil
+}
+
+func (f *fakeStore) PruneWebhookDeliveries(context.Context, time.Duration) error { return nil }
+
+func event(id int64, typ, chain string, tags ...any) store.Event {
+	return store.Event{
+		ID: id, Type: typ, ChainID: chain, AgentID: id,
+		Agent: store.EventAgent{Domain: "a.example", Skills: []map[string]any{{"id": "s", "name": "Search", "tags": tags}}},
+	}
+}
+
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestDispatcher(st *fakeStore, clk *clock) *Dispatcher {
+	st.now = clk.now
+	d := New(st, Config{MaxAttempts: 3, BaseBackoff: time.Minute, MaxBackoff: time.Hour, Timeout: 2 * time.Second})
+	d.now = clk.now
+	return d
+}
+
+func TestSignVerify(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+	body := []byte(`{"id":1}`)
+	h := Sign("whsec_x", now, body)
+	if err := Verify("whsec_x", h, body, now.Add(time.Minute), 5*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if Verify("whsec_y", h, body, now, 5*time.Minute) == nil {
+		t.Error("wrong secret verified")
+	}
+	if Verify("whsec_x", h, []byte(`{"id":2}`), now, 5*time.Minute) == nil {
+		t.Error("tampered body verified")
+	}
+	if Verify("whsec_x", h, body, now.Add(time.Hour), 5*time.Minute) == nil {
+		t.Error("stale timestamp verified")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	c := Config{BaseBackoff: 30 * time.Second, MaxBackoff: 10 * time.Minute}
+	want := []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute}
+	for i, w := range want {
+		if got := c.backoff(i + 1); got != w {
+			t.Errorf("backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+}
+
+func TestFanOutFilters(t *testing.T) {
+	clk := &clock{time.Unix(1_700_000_000, 0)}
+	st := &fakeStore{
+		events: []store.Event{
+			event(1, store.EventAgentRegistered, "eip155:11155111", "web"),
+			event(2, store.EventAgentRegistered, "eip155:84532", "web"),
+			event(3, store.EventCardChanged, "eip155:11155111", "finance"),
+			event(4, store.EventAgentDeleted, "eip155:11155111", "web"),
+		},
+		subs: []store.WebhookSubscription{
+			{ID: 1, Active: true, Filter: store.WebhookFilter{Network: "sepolia", Tag: "web"}},
+			{ID: 2, Active: true, Types: []string{store.EventCardChanged}},
+			{ID: 3, Active: true, StartEventID: 3},
+			{ID: 4, Active: false},
+		},
+	}
+	d := newTestDispatcher(st, clk)
+	if err := d.fanOut(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := map[int64][]int64{}
+	for _, del := range st.deliveries {
+		got[del.SubscriptionID] = append(got[del.SubscriptionID], del.EventID)
+	}
+	want := map[int64][]int64{1: {1, 4}, 2: {3}, 3: {4}}
+	if len(got) != len(want) {
+		t.Fatalf("deliveries = %v, want %v", got, want)
+	}
+	for sub, ids := range want {
+		if len(got[sub]) != len(ids) || got[sub][0] != ids[0] || got[sub][len(ids)-1] != ids[len(ids)-1] {
+			t.Errorf("subscription %d got events %v, want %v", sub, got[sub], ids)
+		}
+	}
+	if st.cursor != 4 {
+		t.Errorf("cursor = %d, want 4", st.cursor)
+	}
+}
+
+func TestFanOutWaitsAtGap(t *testing.T) {
+	clk := &clock{time.Unix(1_700_000_000, 0)}
+	st := &fakeStore{
+		events: []store.Event{event(1, store.EventAgentUpdated, "eip155:11155111"), event(3, store.EventAgentUpdated, "eip155:11155111")},
+		subs:   []store.WebhookSubscription{{ID: 1, Active: true}},
+	}
+	d := newTestDispatcher(st, clk)
+	ctx := context.Background()
+	if err := d.fanOut(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if st.cursor != 1 || len(st.deliveries) != 1 {
+		t.Fatalf("cursor %d with %d deliveries; want to stop before the gap", st.cursor, len(st.deliveries))
+	}
+	clk.t = clk.t.Add(gapWait)
+	if err := d.fanOut(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if st.cursor != 3 || len(st.deliveries) != 2 {
+		t.Fatalf("cursor %d with %d deliveries; want the gap skipped after gapWait", st.cursor, len(st.deliveries))
+	}
+}
+
+func TestDeliverySignedAndRetried(t *testing.T) {
+	clk := &clock{time.Now()}
+	var mu sync.Mutex
+	var calls int
+	var verifyErr error
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if err := Verify("whsec_test", r.Header.Get(HeaderSignature), body, time.Now(), 5*time.Minute); err != nil {
+			verifyErr = err
+		}
+		var e store.Event
+		if err := json.Unmarshal(body, &e); err != nil || e.ID != 1 || r.Header.Get(HeaderEvent) != store.EventAgentRegistered {
+			t.Errorf("unexpected delivery %s (%v)", body, err)
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	st := &fakeStore{
+		events: []store.Event{event(1, store.EventAgentRegistered, "eip155:11155111")},
+		subs:   []store.WebhookSubscription{{ID: 1, Active: true, URL: receiver.URL, Secret: "whsec_test"}},
+	}
+	d := newTestDispatcher(st, clk)
+	ctx := context.Background()
+
+	d.tick(ctx)
+	del := st.deliveries[0]
+	if del.Status != store.DeliveryPending || del.Attempts != 1 || del.LastStatus != http.StatusServiceUnavailable {
+		t.Fatalf("after first attempt: %+v", *del)
+	}
+	if !del.NextAttemptAt.Equal(clk.t.Add(time.Minute)) {
+		t.Fatalf("next attempt at %v, want one minute later", del.NextAttemptAt)
+	}
+	d.tick(ctx) // not due yet
+	if calls != 1 {
+		t.Fatalf("receiver called %d times before the retry was due", calls)
+	}
+	clk.t = clk.t.Add(time.Minute)
+	d.tick(ctx)
+	if del.Status != store.DeliveryDelivered || del.Attempts != 2 || calls != 2 {
+		t.Fatalf("after retry: %+v, %d calls", *del, calls)
+	}
+	if verifyErr != nil {
+		t.Fatal(verifyErr)
+	}
+}
+
+func TestDeliveryDeadLetters(t *testing.T) {
+	clk := &clock{time.Now()}
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(HeaderDelivery) == "2" {
+			w.WriteHeader(http.StatusGone)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	st := &fakeStore{
+		events: []store.Event{event(1, store.EventAgentUpdated, "eip155:11155111"), event(2, store.EventAgentUpdated, "eip155:11155111")},
+		subs:   []store.WebhookSubscription{{ID: 1, Active: true, URL: receiver.URL, Secret: "s"}},
+	}
+	d := newTestDispatcher(st, clk)
+	for i := 0; i < 5; i++ {
+		d.tick(context.Background())
+		clk.t = clk.t.Add(time.Hour)
+	}
+	if a := st.deliveries[0]; a.Status != store.DeliveryDead || a.Attempts != 3 {
+		t.Errorf("500s: %+v, want dead after 3 attempts", *a)
+	}
+	if b := st.deliveries[1]; b.Status != store.DeliveryDead || b.Attempts != 1 {
+		t.Errorf("410: %+v, want dead after 1 attempt", *b)
+	}
+}
@@ -0,0 +1,45 @@
+-- 003_registrations.sql — persist ERC-8004 v1 registration files and their declared endpoints
+CREATE TABLE IF NOT EXISTS agent_registrations (
+  chain_id          TEXT NOT NULL,
+  agent_id          BIGINT NOT NULL,
+  token_uri         TEXT NOT NULL,
+  owner_address     TEXT NOT NULL DEFAULT '',
+  registration_json JSONB NOT NULL,
+  fetched_at        TIMESTAMPTZ DEFAULT now(),
+  PRIMARY KEY (chain_id, agent_id),
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
+
+-- Endpoints come either from a v1 registration file or from the A2A card url.
+-- source keeps the two apart so refreshing one never clobbers the other.
+CREATE TABLE IF NOT EXISTS agent_endpoints (
+  chain_id  TEXT NOT NULL,
+  agent_id  BIGINT NOT NULL,
+  source    TEXT NOT NULL,
+  name      TEXT NOT NULL,
+  endpoint  TEXT NOT NULL,
+  version   TEXT NOT NULL DEFAULT '',
+  extra     JSONB NOT NULL DEFAULT '{}',
+  PRIMARY KEY (chain_id, agent_id, source, name, endpoint),
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_endpoints_name ON agent_endpoints (lower(name));
+
+CREATE TABLE IF NOT EXISTS agent_supported_trust (
+  chain_id    TEXT NOT NULL,
+  agent_id    BIGINT NOT NULL,
+  trust_model TEXT NOT NULL,
+  PRIMARY KEY (chain_id, agent_id, trust_model),
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS agent_wallets (
+  chain_id TEXT NOT NULL,
+  agent_id BIGINT NOT NULL,
+  address  TEXT NOT NULL,
+  PRIMARY KEY (chain_id, agent_id, address),
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_wallets_address ON agent_wallets (lower(address));
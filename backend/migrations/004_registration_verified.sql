@@ -0,0 +1,3 @@
+-- 004_registration_verified.sql — record whether a registration's bytes were checked against its URI
+ALTER TABLE agent_registrations
+  ADD COLUMN IF NOT EXISTS content_verified BOOLEAN NOT NULL DEFAULT false;
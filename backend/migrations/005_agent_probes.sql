@@ -0,0 +1,33 @@
+-- 005_agent_probes.sql — endpoint liveness probes and a per-agent health summary
+CREATE TABLE IF NOT EXISTS agent_probes (
+  id           BIGSERIAL PRIMARY KEY,
+  chain_id     TEXT NOT NULL,
+  agent_id     BIGINT NOT NULL,
+  kind         TEXT NOT NULL,          -- card | a2a | mcp
+  url          TEXT NOT NULL,
+  probed_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  http_status  INTEGER,
+  latency_ms   DOUBLE PRECISION,
+  tls_valid    BOOLEAN,                -- NULL for plain http
+  handshake_ok BOOLEAN NOT NULL DEFAULT false,
+  error        TEXT NOT NULL DEFAULT '',
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_probes_agent_time ON agent_probes (chain_id, agent_id, probed_at DESC);
+CREATE INDEX IF NOT EXISTS idx_agent_probes_time ON agent_probes (probed_at);
+
+-- Rolled up after every probe round so search can filter and sort without scanning probes.
+CREATE TABLE IF NOT EXISTS agent_health (
+  chain_id       TEXT NOT NULL,
+  agent_id       BIGINT NOT NULL,
+  status         TEXT NOT NULL,        -- up | degraded | down
+  uptime_pct     DOUBLE PRECISION,
+  latency_p50_ms DOUBLE PRECISION,
+  latency_p95_ms DOUBLE PRECISION,
+  last_probed_at TIMESTAMPTZ NOT NULL,
+  PRIMARY KEY (chain_id, agent_id),
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_health_status ON agent_health (status);
@@ -0,0 +1,50 @@
+-- 006_mcp_catalog.sql — what agents' MCP servers advertise (initialize, tools/resources/prompts lists)
+CREATE TABLE IF NOT EXISTS agent_mcp_servers (
+  chain_id         TEXT NOT NULL,
+  agent_id         BIGINT NOT NULL,
+  endpoint         TEXT NOT NULL,
+  protocol_version TEXT NOT NULL DEFAULT '',
+  server_name      TEXT NOT NULL DEFAULT '',
+  server_version   TEXT NOT NULL DEFAULT '',
+  capabilities     JSONB NOT NULL DEFAULT '{}'::jsonb,
+  introspected_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+  error            TEXT NOT NULL DEFAULT '',   -- last failure; the previous catalog is kept
+  PRIMARY KEY (chain_id, agent_id),
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS agent_mcp_tools (
+  chain_id     TEXT NOT NULL,
+  agent_id     BIGINT NOT NULL,
+  name         TEXT NOT NULL,
+  title        TEXT NOT NULL DEFAULT '',
+  description  TEXT NOT NULL DEFAULT '',
+  input_schema JSONB NOT NULL DEFAULT '{}'::jsonb,
+  PRIMARY KEY (chain_id, agent_id, name),
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_mcp_tools_name ON agent_mcp_tools (lower(name));
+CREATE INDEX IF NOT EXISTS idx_agent_mcp_tools_fts ON agent_mcp_tools
+  USING GIN (to_tsvector('simple', name || ' ' || title || ' ' || description));
+
+CREATE TABLE IF NOT EXISTS agent_mcp_resources (
+  chain_id    TEXT NOT NULL,
+  agent_id    BIGINT NOT NULL,
+  uri         TEXT NOT NULL,
+  name        TEXT NOT NULL DEFAULT '',
+  description TEXT NOT NULL DEFAULT '',
+  mime_type   TEXT NOT NULL DEFAULT '',
+  PRIMARY KEY (chain_id, agent_id, uri),
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
+
+CREATE TABLE IF NOT EXISTS agent_mcp_prompts (
+  chain_id    TEXT NOT NULL,
+  agent_id    BIGINT NOT NULL,
+  name        TEXT NOT NULL,
+  description TEXT NOT NULL DEFAULT '',
+  arguments   JSONB NOT NULL DEFAULT '[]'::jsonb,
+  PRIMARY KEY (chain_id, agent_id, name),
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
@@ -0,0 +1,46 @@
+-- 007_admin_auth.sql — admin API keys, SIWE sessions and the admin audit log
+CREATE TABLE IF NOT EXISTS admin_api_keys (
+  id            BIGSERIAL PRIMARY KEY,
+  name          TEXT NOT NULL,
+  key_hash      TEXT NOT NULL UNIQUE,        -- sha256 of the plaintext key, hex
+  prefix        TEXT NOT NULL,               -- first characters of the key, for display only
+  role          TEXT NOT NULL,               -- operator | owner
+  owner_address TEXT NOT NULL DEFAULT '',    -- lowercased 0x address for owner keys
+  created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_used_at  TIMESTAMPTZ,
+  revoked_at    TIMESTAMPTZ
+);
+
+CREATE TABLE IF NOT EXISTS siwe_nonces (
+  nonce      TEXT PRIMARY KEY,
+  expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS admin_sessions (
+  token_hash TEXT PRIMARY KEY,               -- sha256 of the bearer token, hex
+  address    TEXT NOT NULL,
+  role       TEXT NOT NULL,
+  chain_id   BIGINT NOT NULL DEFAULT 0,      -- chain the SIWE message was signed for
+  created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_sessions_expires ON admin_sessions (expires_at);
+
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+  id         BIGSERIAL PRIMARY KEY,
+  at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  actor_kind TEXT NOT NULL,                  -- api_key | session
+  actor_id   TEXT NOT NULL,
+  role       TEXT NOT NULL,
+  action     TEXT NOT NULL,
+  method     TEXT NOT NULL,
+  path       TEXT NOT NULL,
+  chain_id   TEXT NOT NULL DEFAULT '',
+  agent_id   TEXT NOT NULL DEFAULT '',
+  request    JSONB,
+  status     INTEGER NOT NULL,
+  remote_ip  TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS idx_admin_audit_at ON admin_audit_log (at DESC);
@@ -0,0 +1,10 @@
+-- 008_refresh_nonces.sql — nonces of owner-signed refresh requests, kept to reject replays
+CREATE TABLE IF NOT EXISTS agent_refresh_nonces (
+  chain_id   TEXT NOT NULL,
+  agent_id   BIGINT NOT NULL,
+  nonce      TEXT NOT NULL,
+  used_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (chain_id, agent_id, nonce)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_refresh_nonces_used ON agent_refresh_nonces (used_at);
@@ -0,0 +1,14 @@
+-- 009_indexer_events.sql — append-only log of agent lifecycle events, written in the
+-- same transaction as the change; streams resume from it via Last-Event-ID
+CREATE TABLE IF NOT EXISTS indexer_events (
+  id         BIGSERIAL PRIMARY KEY,
+  type       TEXT NOT NULL,          -- agent.registered | agent.updated | card.changed | agent.deleted | probe.status_changed
+  chain_id   TEXT NOT NULL,
+  agent_id   BIGINT NOT NULL,
+  at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+  agent      JSONB NOT NULL DEFAULT '{}'::jsonb,  -- snapshot used for filtering: domain, name, trustModels, skills
+  data       JSONB NOT NULL DEFAULT '{}'::jsonb
+);
+
+CREATE INDEX IF NOT EXISTS idx_indexer_events_at ON indexer_events (at);
+CREATE INDEX IF NOT EXISTS idx_indexer_events_agent ON indexer_events (chain_id, agent_id, id);
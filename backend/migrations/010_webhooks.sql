@@ -0,0 +1,39 @@
+-- 010_webhooks.sql — outbound webhook subscriptions and their durable delivery outbox
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+  id             BIGSERIAL PRIMARY KEY,
+  url            TEXT NOT NULL,
+  secret         TEXT NOT NULL,                      -- HMAC-SHA256 signing key, shown once at creation
+  description    TEXT NOT NULL DEFAULT '',
+  filter         JSONB NOT NULL DEFAULT '{}'::jsonb, -- q, network, skill, tag, trustModel
+  types          TEXT[] NOT NULL DEFAULT '{}',       -- empty: every event type
+  active         BOOLEAN NOT NULL DEFAULT true,
+  start_event_id BIGINT NOT NULL DEFAULT 0,          -- only events after this id are delivered
+  created_by     TEXT NOT NULL DEFAULT '',
+  created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+  updated_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id              BIGSERIAL PRIMARY KEY,
+  subscription_id BIGINT NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+  event_id        BIGINT NOT NULL,
+  event_type      TEXT NOT NULL,
+  payload         JSONB NOT NULL,
+  status          TEXT NOT NULL DEFAULT 'pending',   -- pending | delivered | dead
+  attempts        INT NOT NULL DEFAULT 0,
+  next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  last_status     INT NOT NULL DEFAULT 0,            -- HTTP status of the last attempt, 0 on transport errors
+  last_error      TEXT NOT NULL DEFAULT '',
+  created_at      TIMESTAMPTZ NOT NULL DEFAULT now(),
+  delivered_at    TIMESTAMPTZ,
+  UNIQUE (subscription_id, event_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_sub ON webhook_deliveries (subscription_id, id DESC);
+
+-- how far the dispatcher has fanned indexer_events out into webhook_deliveries
+CREATE TABLE IF NOT EXISTS webhook_cursor (
+  id            BOOLEAN PRIMARY KEY DEFAULT true CHECK (id),
+  last_event_id BIGINT NOT NULL
+);
@@ -0,0 +1,17 @@
+-- 011_agent_sort.sql — registration time for sort=registered, and keyset indexes for
+-- the common agent sort orders
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS registered_at TIMESTAMPTZ;
+
+-- best known registration time: the first agent.registered event, else when we last saw it
+UPDATE agents SET registered_at = COALESCE(
+  (SELECT min(e.at) FROM indexer_events e
+    WHERE e.chain_id = agents.chain_id AND e.agent_id = agents.agent_id AND e.type = 'agent.registered'),
+  agents.last_seen_at, now())
+WHERE registered_at IS NULL;
+
+ALTER TABLE agents ALTER COLUMN registered_at SET DEFAULT now();
+ALTER TABLE agents ALTER COLUMN registered_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_agents_recent ON agents (last_seen_at, chain_id, agent_id);
+CREATE INDEX IF NOT EXISTS idx_agents_registered ON agents (registered_at, chain_id, agent_id);
+CREATE INDEX IF NOT EXISTS idx_agents_score ON agents ((COALESCE(score_avg, -1)::float8), chain_id, agent_id);
@@ -0,0 +1,38 @@
+-- 012_agent_embeddings.sql — hashed n-gram vectors of agent cards for similar-agent
+-- lookups and semantic search; see package embed
+CREATE TABLE IF NOT EXISTS agent_embeddings (
+  chain_id   TEXT NOT NULL,
+  agent_id   BIGINT NOT NULL,
+  model      TEXT NOT NULL,          -- embed.Model; rows from an older model are recomputed
+  vec        REAL[] NOT NULL,        -- L2-normalized, so a dot product is the cosine
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (chain_id, agent_id),
+  FOREIGN KEY (chain_id, agent_id) REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_embeddings_model ON agent_embeddings (model);
+
+-- With pgvector available, mirror vec into an indexed vector column for nearest-neighbour
+-- queries. Without it (or without the privilege to install it) the REAL[] column is
+-- used directly.
+DO $$
+BEGIN
+  IF EXISTS (SELECT 1 FROM pg_available_extensions WHERE name = 'vector') THEN
+    CREATE EXTENSION IF NOT EXISTS vector;
+    ALTER TABLE agent_embeddings ADD COLUMN IF NOT EXISTS embedding vector(512);
+    CREATE OR REPLACE FUNCTION agent_embeddings_sync() RETURNS trigger AS $f$
+    BEGIN
+      NEW.embedding := NEW.vec::vector;
+      RETURN NEW;
+    END
+    $f$ LANGUAGE plpgsql;
+    DROP TRIGGER IF EXISTS agent_embeddings_sync ON agent_embeddings;
+    CREATE TRIGGER agent_embeddings_sync BEFORE INSERT OR UPDATE OF vec ON agent_embeddings
+      FOR EACH ROW EXECUTE FUNCTION agent_embeddings_sync();
+    UPDATE agent_embeddings SET embedding = vec::vector WHERE embedding IS NULL;
+    CREATE INDEX IF NOT EXISTS idx_agent_embeddings_hnsw ON agent_embeddings USING hnsw (embedding vector_cosine_ops);
+  END IF;
+EXCEPTION WHEN insufficient_privilege THEN
+  RAISE NOTICE 'pgvector not installed (%); similarity search uses the REAL[] column', SQLERRM;
+END
+$$;
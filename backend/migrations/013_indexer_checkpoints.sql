@@ -0,0 +1,10 @@
+-- 013_indexer_checkpoints.sql — how far the indexer got per identity registry, so a
+-- restart (or a restored snapshot) resumes instead of replaying from scratch
+CREATE TABLE IF NOT EXISTS indexer_checkpoints (
+  chain_id          TEXT NOT NULL,
+  registry_addr     TEXT NOT NULL,
+  last_block        BIGINT NOT NULL DEFAULT 0,   -- last block whose identity logs were handled
+  agents_backfilled BIGINT NOT NULL DEFAULT 0,   -- highest agent id the backfill has visited
+  updated_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+  PRIMARY KEY (chain_id, registry_addr)
+);
@@ -0,0 +1,12 @@
+-- 014_schema_migrations.sql — which migrations have been applied, so /readyz can
+-- tell a database that is behind the binary. Migrations run in order, so applying
+-- this one implies 001–014; every later migration records its own version here
+-- and bumps store.SchemaVersion.
+CREATE TABLE IF NOT EXISTS schema_migrations (
+  version    INT PRIMARY KEY,
+  applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO schema_migrations (version)
+SELECT generate_series(1, 14)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,22 @@
+-- 015_agent_events.sql — every identity registry log about an agent with its chain
+-- context (block, timestamp, transaction, sender), for the per-agent timeline, and
+-- the registration and last update blocks on agents
+CREATE TABLE IF NOT EXISTS agent_events (
+  chain_id     TEXT NOT NULL,
+  agent_id     BIGINT NOT NULL,
+  block_number BIGINT NOT NULL,
+  block_time   TIMESTAMPTZ,                         -- NULL when the block header could not be read
+  tx_hash      TEXT NOT NULL,
+  log_index    INT NOT NULL,
+  event_type   TEXT NOT NULL,                       -- Solidity event name: Registered | AgentRegistered | AgentUpdated
+  args         JSONB NOT NULL DEFAULT '{}'::jsonb,  -- decoded event arguments
+  from_address TEXT,                                -- transaction sender
+  PRIMARY KEY (chain_id, tx_hash, log_index)
+);
+
+CREATE INDEX IF NOT EXISTS idx_agent_events_agent ON agent_events (chain_id, agent_id, block_number, log_index);
+
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS registered_block BIGINT;
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS updated_at_block BIGINT;
+
+INSERT INTO schema_migrations (version) VALUES (15) ON CONFLICT DO NOTHING;
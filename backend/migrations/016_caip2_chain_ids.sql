@@ -0,0 +1,70 @@
+-- 016_caip2_chain_ids.sql — chains are keyed by CAIP-2 id (eip155:11155111) instead of
+-- the network's name in the config file. rename_chain moves one chain's rows; it runs
+-- here for well-known names, and the indexer runs it at startup for the names in its
+-- config.
+
+-- per-agent tables follow the agent when its chain_id changes
+DO $$
+DECLARE t TEXT;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['agent_registrations', 'agent_endpoints', 'agent_supported_trust', 'agent_wallets',
+    'agent_probes', 'agent_health', 'agent_mcp_servers', 'agent_mcp_tools', 'agent_mcp_resources',
+    'agent_mcp_prompts', 'agent_embeddings'] LOOP
+    EXECUTE format('ALTER TABLE %I DROP CONSTRAINT IF EXISTS %I', t, t || '_chain_id_agent_id_fkey');
+    EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I FOREIGN KEY (chain_id, agent_id)
+      REFERENCES agents (chain_id, agent_id) ON DELETE CASCADE ON UPDATE CASCADE', t, t || '_chain_id_agent_id_fkey');
+  END LOOP;
+END $$;
+
+-- rename_chain moves every row of chain old_id to new_id. Agents already indexed
+-- under new_id win over their old copies; returns how many agents moved.
+CREATE OR REPLACE FUNCTION rename_chain(old_id TEXT, new_id TEXT) RETURNS BIGINT AS $$
+DECLARE moved BIGINT;
+BEGIN
+  IF old_id = new_id THEN
+    RETURN 0;
+  END IF;
+  DELETE FROM agents a WHERE a.chain_id = old_id
+    AND EXISTS (SELECT 1 FROM agents b WHERE b.chain_id = new_id AND b.agent_id = a.agent_id);
+  UPDATE agents SET chain_id = new_id WHERE chain_id = old_id;
+  GET DIAGNOSTICS moved = ROW_COUNT;
+
+  DELETE FROM agent_refresh_nonces n WHERE n.chain_id = old_id
+    AND EXISTS (SELECT 1 FROM agent_refresh_nonces m WHERE m.chain_id = new_id AND m.agent_id = n.agent_id AND m.nonce = n.nonce);
+  UPDATE agent_refresh_nonces SET chain_id = new_id WHERE chain_id = old_id;
+
+  DELETE FROM agent_events e WHERE e.chain_id = old_id
+    AND EXISTS (SELECT 1 FROM agent_events f WHERE f.chain_id = new_id AND f.tx_hash = e.tx_hash AND f.log_index = e.log_index);
+  UPDATE agent_events SET chain_id = new_id WHERE chain_id = old_id;
+
+  -- checkpoints merge to the furthest of both
+  INSERT INTO indexer_checkpoints (chain_id, registry_addr, last_block, agents_backfilled)
+  SELECT new_id, registry_addr, last_block, agents_backfilled FROM indexer_checkpoints WHERE chain_id = old_id
+  ON CONFLICT (chain_id, registry_addr) DO UPDATE SET
+    last_block = GREATEST(indexer_checkpoints.last_block, EXCLUDED.last_block),
+    agents_backfilled = GREATEST(indexer_checkpoints.agents_backfilled, EXCLUDED.agents_backfilled),
+    updated_at = now();
+  DELETE FROM indexer_checkpoints WHERE chain_id = old_id;
+
+  UPDATE indexer_events SET chain_id = new_id WHERE chain_id = old_id;
+  UPDATE webhook_subscriptions SET filter = jsonb_set(filter, '{network}', to_jsonb(new_id)), updated_at = now()
+  WHERE filter->>'network' = old_id;
+  RETURN moved;
+END $$ LANGUAGE plpgsql;
+
+SELECT rename_chain(alias, caip2) FROM (VALUES
+  ('mainnet', 'eip155:1'),
+  ('ethereum', 'eip155:1'),
+  ('sepolia', 'eip155:11155111'),
+  ('holesky', 'eip155:17000'),
+  ('base', 'eip155:8453'),
+  ('base-sepolia', 'eip155:84532'),
+  ('optimism', 'eip155:10'),
+  ('op-sepolia', 'eip155:11155420'),
+  ('arbitrum', 'eip155:42161'),
+  ('arbitrum-sepolia', 'eip155:421614'),
+  ('polygon', 'eip155:137'),
+  ('polygon-amoy', 'eip155:80002')
+) AS known (alias, caip2);
+
+INSERT INTO schema_migrations (version) VALUES (16) ON CONFLICT DO NOTHING;
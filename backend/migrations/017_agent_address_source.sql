@@ -0,0 +1,6 @@
+-- 017_agent_address_source.sql — address_caip10 holds a checksummed CAIP-10 account id;
+-- once the indexer has read the agent's AgentAddress on-chain, cards no longer
+-- override it
+ALTER TABLE agents ADD COLUMN IF NOT EXISTS address_onchain BOOLEAN NOT NULL DEFAULT false;
+
+INSERT INTO schema_migrations (version) VALUES (17) ON CONFLICT DO NOTHING;
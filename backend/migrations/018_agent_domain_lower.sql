@@ -0,0 +1,4 @@
+-- 018_agent_domain_lower.sql — domains are matched ignoring case by GET /resolve
+CREATE INDEX IF NOT EXISTS idx_agents_domain_lower ON agents (lower(domain));
+
+INSERT INTO schema_migrations (version) VALUES (18) ON CONFLICT DO NOTHING;
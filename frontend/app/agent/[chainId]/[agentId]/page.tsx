@@ -4,8 +4,9 @@ import { useState, useEffect } from 'react'
 import { useParams } from 'next/navigation'
 import Link from 'next/link'
 import Header from '@/components/Header'
-import { getAgent } from '@/lib/api'
-import { AgentRow } from '@/types/agent'
+import AgentCard from '@/components/AgentCard'
+import { getAgent, getSimilarAgents } from '@/lib/api'
+import { AgentRow, SimilarAgent } from '@/types/agent'
 import { formatDate, isOnline, getChainName, truncateAddress, extractSkillName, extractSkillTags } from '@/lib/utils'
 
 export default function AgentDetailPage() {
@@ -14,6 +15,7 @@ export default function AgentDetailPage() {
   const [agent, setAgent] = useState<AgentRow | null>(null)
   const [loading, setLoading] = useState(true)
   const [error, setError] = useState<string | null>(null)
+  const [similar, setSimilar] = useState<SimilarAgent[]>([])
   const [activeTab, setActiveTab] = useState<'overview' | 'skills' | 'capabilities' | 'raw'>('overview')
 
   useEffect(() => {
@@ -38,6 +40,14 @@ export default function AgentDetailPage() {
     }
   }, [params?.chainId, params?.agentId])
 
+  // recommendations are optional; the page works without them
+  useEffect(() => {
+    if (!params?.chainId || !params?.agentId) return
+    getSimilarAgents(String(params.chainId), String(params.agentId), 6)
+      .then(setSimilar)
+      .catch(() => setSimilar([]))
+  }, [params?.chainId, params?.agentId])
+
   if (loading) {
     return (
       <div className="min-h-screen">
@@ -291,6 +301,19 @@ export default function AgentDetailPage() {
               )}
             </div>
           </div>
+
+          {similar.length > 0 && (
+            <div className="mt-12">
+              <h2 className="text-2xl font-bold text-white mb-6">Similar agents</h2>
+              <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-6">
+                {similar.map((s) => (
+                  <div key={`${s.chainId}-${s.agentId}`} className="animate-fade-in">
+                    <AgentCard agent={s} />
+                  </div>
+                ))}
+              </div>
+            </div>
+          )}
         </div>
       </main>
     </div>
@@ -1,4 +1,4 @@
-import { AgentRow, AgentsResponse, SearchParams } from '@/types/agent'
+import { AgentEvent, AgentRow, AgentsResponse, ResolvedAgent, SearchParams, SimilarAgent } from '@/types/agent'
 
 const API_BASE_URL = (process.env.NEXT_PUBLIC_API_URL || process.env.NEXT_PUBLIC_EXPLORER_URL || 'http://localhost:8080').replace(/\/$/, '')
 
@@ -11,8 +11,18 @@ export async function searchAgents(params: SearchParams): Promise<AgentsResponse
   if (params.skill) searchParams.set('skill', params.skill)
   if (params.tag) searchParams.set('tag', params.tag)
   if (params.trustModel) searchParams.set('trustModel', params.trustModel)
+  if (params.endpoint) searchParams.set('endpoint', params.endpoint)
+  if (params.status) searchParams.set('status', params.status)
+  if (params.tool) searchParams.set('tool', params.tool)
   if (params.cursor) searchParams.set('cursor', params.cursor)
   if (params.limit) searchParams.set('limit', params.limit.toString())
+  if (params.facets?.length) searchParams.set('facets', params.facets.join(','))
+  if (params.facetLimit) searchParams.set('facetLimit', params.facetLimit.toString())
+  if (params.filter) searchParams.set('filter', params.filter)
+  if (params.match) searchParams.set('match', params.match)
+  if (params.sort) searchParams.set('sort', params.sort)
+  if (params.order) searchParams.set('order', params.order)
+  if (params.semantic) searchParams.set('semantic', params.semantic)
 
   const response = await fetch(`${API_BASE_URL}/agents?${searchParams}`, {
     headers: {
@@ -48,11 +58,85 @@ export async function getAgent(chainId: string, agentId: string): Promise<AgentR
   return data
 }
 
-export async function refreshAgent(chainId: string, domain: string, agentId: number, registryAddr?: string) {
+export async function getSimilarAgents(chainId: string, agentId: string, limit?: number): Promise<SimilarAgent[]> {
+  const query = limit ? `?limit=${limit}` : ''
+  const response = await fetch(`${API_BASE_URL}/agents/${encodeURIComponent(chainId)}/${agentId}/similar${query}`, {
+    headers: {
+      'Content-Type': 'application/json',
+    },
+  })
+
+  if (!response.ok) {
+    const txt = await response.text().catch(() => `${response.status}`)
+    throw new Error(`API error: ${response.status} ${txt}`)
+  }
+
+  const data = await response.json().catch(() => ({} as any))
+  return Array.isArray(data.items) ? data.items : []
+}
+
+// agents whose address is a CAIP-10 account; source tells whether the index
+// knew them or the identity registry was asked
+export async function getAgentsByAddress(caip10: string, limit?: number): Promise<{ items: AgentRow[]; source: 'index' | 'chain' }> {
+  const query = limit ? `?limit=${limit}` : ''
+  const response = await fetch(`${API_BASE_URL}/addresses/${encodeURIComponent(caip10)}/agents${query}`, {
+    headers: {
+      'Content-Type': 'application/json',
+    },
+  })
+
+  if (!response.ok) {
+    const txt = await response.text().catch(() => `${response.status}`)
+    throw new Error(`API error: ${response.status} ${txt}`)
+  }
+
+  const data = await response.json().catch(() => ({} as any))
+  return { items: Array.isArray(data.items) ? data.items : [], source: data.source === 'chain' ? 'chain' : 'index' }
+}
+
+// agents registered under a domain on any chain, each saying whether the card
+// served there declares its registration
+export async function resolveDomain(domain: string): Promise<{ items: ResolvedAgent[]; source: 'index' | 'chain' }> {
+  const response = await fetch(`${API_BASE_URL}/resolve?domain=${encodeURIComponent(domain)}`, {
+    headers: {
+      'Content-Type': 'application/json',
+    },
+  })
+
+  if (!response.ok) {
+    const txt = await response.text().catch(() => `${response.status}`)
+    throw new Error(`API error: ${response.status} ${txt}`)
+  }
+
+  const data = await response.json().catch(() => ({} as any))
+  return { items: Array.isArray(data.items) ? data.items : [], source: data.source === 'chain' ? 'chain' : 'index' }
+}
+
+// on-chain timeline of an agent, oldest first
+export async function getAgentEvents(chainId: string, agentId: string, limit?: number): Promise<AgentEvent[]> {
+  const query = limit ? `?limit=${limit}` : ''
+  const response = await fetch(`${API_BASE_URL}/agents/${encodeURIComponent(chainId)}/${agentId}/events${query}`, {
+    headers: {
+      'Content-Type': 'application/json',
+    },
+  })
+
+  if (!response.ok) {
+    const txt = await response.text().catch(() => `${response.status}`)
+    throw new Error(`API error: ${response.status} ${txt}`)
+  }
+
+  const data = await response.json().catch(() => ({} as any))
+  return Array.isArray(data.items) ? data.items : []
+}
+
+// /admin routes require an operator API key or SIWE session token
+export async function refreshAgent(chainId: string, domain: string, agentId: number, registryAddr?: string, adminToken?: string) {
   const response = await fetch(`${API_BASE_URL}/admin/refresh`, {
     method: 'POST',
     headers: {
       'Content-Type': 'application/json',
+      ...(adminToken ? { Authorization: `Bearer ${adminToken}` } : {}),
     },
     body: JSON.stringify({
       chainId,
@@ -71,3 +155,22 @@ export async function refreshAgent(chainId: string, domain: string, agentId: num
   if (!data) throw new Error('Empty response')
   return data
 }
+
+// Owner self-service refresh: `signature` is the agent address's personal_sign over
+// "Praxis Agent Refresh\nchain=<chainId>\nagentId=<agentId>\nnonce=<nonce>\ntimestamp=<unix seconds>"
+export async function ownerRefreshAgent(chainId: string, agentId: number, nonce: string, timestamp: number, signature: string) {
+  const response = await fetch(`${API_BASE_URL}/agents/${encodeURIComponent(chainId)}/${agentId}/refresh`, {
+    method: 'POST',
+    headers: {
+      'Content-Type': 'application/json',
+    },
+    body: JSON.stringify({ nonce, timestamp, signature }),
+  })
+
+  if (!response.ok) {
+    const txt = await response.text().catch(() => `${response.status}`)
+    throw new Error(`API error: ${response.status} ${txt}`)
+  }
+
+  return response.json()
+}
@@ -1,3 +1,11 @@
+export interface AgentEndpoint {
+  source: 'registration' | 'card'
+  name: string
+  endpoint: string
+  version?: string
+  extra?: Record<string, any>
+}
+
 export interface AgentRow {
   chainId: string
   agentId: number
@@ -12,13 +20,57 @@ export interface AgentRow {
   validationsCnt: number
   feedbacksCnt: number
   lastSeenAt: string
+  registeredAt?: string
+  status?: 'up' | 'degraded' | 'down'
+  uptimePct?: number
+  latencyP50Ms?: number
+  latencyP95Ms?: number
+  lastProbedAt?: string
+  endpoints?: AgentEndpoint[]
+  // blocks of the registering and latest updating identity events
+  registeredBlock?: number
+  updatedAtBlock?: number
+}
+
+// an identity registry log about an agent, from /agents/:chainId/:agentId/events
+export interface AgentEvent {
+  chainId: string
+  agentId: number
+  blockNumber: number
+  blockTime?: string
+  txHash: string
+  logIndex: number
+  type: 'Registered' | 'AgentRegistered' | 'AgentUpdated'
+  // decoded event arguments; integers as decimal strings
+  args: Record<string, string> | null
+  from?: string
+}
+
+// an agent recommended by /similar, with its cosine similarity in [-1, 1]
+export interface SimilarAgent extends AgentRow {
+  similarity: number
+}
+
+// an agent found by /resolve; cardMatches is unset when the domain served no card
+export interface ResolvedAgent extends AgentRow {
+  cardMatches?: boolean
+}
+
+export type Facet = 'network' | 'trustModel' | 'tag' | 'capability'
+
+export interface FacetBucket {
+  value: string
+  count: number
 }
 
 export interface AgentsResponse {
   items: AgentRow[]
   nextCursor?: string
+  facets?: Partial<Record<Facet, FacetBucket[]>>
 }
 
+export type SortKey = 'relevance' | 'recent' | 'registered' | 'score' | 'feedbacks' | 'validations' | 'name' | 'agentId'
+
 export interface SearchParams {
   q?: string
   network?: string
@@ -26,6 +78,29 @@ export interface SearchParams {
   skill?: string
   tag?: string
   trustModel?: string
+  endpoint?: string
+  status?: 'up' | 'degraded' | 'down'
+  tool?: string
   cursor?: string
   limit?: number
+  facets?: Facet[]
+  facetLimit?: number
+  // boolean expression, e.g. `tag:finance AND (trust:tee OR trust:reputation)`
+  filter?: string
+  match?: 'any' | 'all'
+  // a cursor only resumes the sort and order it was issued for
+  sort?: SortKey
+  order?: 'asc' | 'desc'
+  // free text matched against card embeddings; relevance then ranks by similarity
+  semantic?: string
+}
+
+// RFC 7807 error body returned by every failing API call
+export interface Problem {
+  type: string
+  title: string
+  status: number
+  detail?: string
+  instance?: string
+  errors?: string[]
 }